@@ -95,12 +95,14 @@ func ChannelCreate(fcTool *core.Client) *cobra.Command {
 
 func ChannelList(fcTool *core.Client) *cobra.Command {
 	listChannelOptions := core.ListChannelOptions{}
+	output := string(OutputFormatTable)
 
 	command := &cobra.Command{
 		Use:   "list",
 		Short: "List channels",
 		Example: `  riff channel list
-  riff channel list --namespace joseph-ns`,
+  riff channel list --namespace joseph-ns
+  riff channel list --selector team=payments`,
 		Args: cobra.ExactArgs(channelListNumberOfArgs),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			channels, err := (*fcTool).ListChannels(listChannelOptions)
@@ -108,6 +110,10 @@ func ChannelList(fcTool *core.Client) *cobra.Command {
 				return err
 			}
 
+			if OutputFormat(output) != OutputFormatTable {
+				return Render(cmd.OutOrStdout(), channels, OutputFormat(output))
+			}
+
 			if len(channels.Items) == 0 {
 				fmt.Fprintln(cmd.OutOrStdout(), "No resources found.")
 			} else {
@@ -122,6 +128,8 @@ func ChannelList(fcTool *core.Client) *cobra.Command {
 	}
 
 	command.Flags().StringVarP(&listChannelOptions.Namespace, "namespace", "n", "", "the `namespace` of the channels to be listed")
+	command.Flags().StringVarP(&listChannelOptions.LabelSelector, "selector", "l", "", "only list channels matching the given label `selector`")
+	command.Flags().VarP(OneOfStringValue(&output, outputFormats...), "output", "o", outputUsage)
 
 	return command
 }
@@ -135,9 +143,21 @@ func ChannelDelete(fcTool *core.Client) *cobra.Command {
 		Args: ArgValidationConjunction(
 			cobra.ExactArgs(channelDeleteNumberOfArgs),
 			AtPosition(channelDeleteNameIndex, ValidName())),
-		Example: `  riff channel delete tweets`,
+		Example: `  riff channel delete tweets
+  riff channel delete tweets --cascade`,
+		PreRunE: FlagsValidatorAsCobraRunE(RequiredUnlessTTY("yes")),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			options.Name = args[channelDeleteNameIndex]
+			prompt := fmt.Sprintf("Delete channel %s%s?", options.Name, inNamespace(options.Namespace))
+			if options.Cascade {
+				prompt = fmt.Sprintf("Delete channel %s%s, along with its subscriptions?", options.Name, inNamespace(options.Namespace))
+			}
+			if ok, err := Confirmed(cmd, prompt); err != nil {
+				return err
+			} else if !ok {
+				printAbortedCompletion(cmd)
+				return nil
+			}
 
 			err := (*fcTool).DeleteChannel(options)
 			if err != nil {
@@ -152,5 +172,7 @@ func ChannelDelete(fcTool *core.Client) *cobra.Command {
 	LabelArgs(command, "CHANNEL_NAME")
 
 	command.Flags().StringVarP(&options.Namespace, "namespace", "n", "", "the `namespace` of the channel")
+	command.Flags().BoolVar(&options.Cascade, "cascade", false, "also delete the subscriptions to the channel")
+	AddYesFlag(command)
 	return command
 }
@@ -199,6 +199,16 @@ var _ = Describe("The riff channel list command", func() {
 			err := cl.Execute()
 			Expect(err).To(MatchError(e))
 		})
+		It("should filter channels by label", func() {
+			cl.SetArgs([]string{"--selector", "team=payments"})
+
+			o := core.ListChannelOptions{LabelSelector: "team=payments"}
+
+			asMock.On("ListChannels", o).Return(&eventing.ChannelList{}, nil)
+			cl.SetOutput(&strings.Builder{})
+			err := cl.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
 	})
 })
 
@@ -240,7 +250,7 @@ var _ = Describe("The riff channel delete command", func() {
 			asMock.AssertExpectations(GinkgoT())
 		})
 		It("should involve the core.Client", func() {
-			cd.SetArgs([]string{"my-channel", "--namespace", "ns"})
+			cd.SetArgs([]string{"my-channel", "--namespace", "ns", "--yes"})
 
 			o := core.DeleteChannelOptions{
 				Name: "my-channel",
@@ -253,12 +263,24 @@ var _ = Describe("The riff channel delete command", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 		It("should propagate core.Client errors", func() {
-			cd.SetArgs([]string{"my-channel"})
+			cd.SetArgs([]string{"my-channel", "--yes"})
 
 			e := fmt.Errorf("some error")
 			asMock.On("DeleteChannel", mock.Anything).Return(e)
 			err := cd.Execute()
 			Expect(err).To(MatchError(e))
 		})
+		It("should cascade to subscriptions when asked to", func() {
+			cd.SetArgs([]string{"my-channel", "--cascade", "--yes"})
+
+			o := core.DeleteChannelOptions{
+				Name:    "my-channel",
+				Cascade: true,
+			}
+
+			asMock.On("DeleteChannel", o).Return(nil)
+			err := cd.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
 	})
 })
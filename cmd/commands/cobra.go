@@ -17,8 +17,10 @@
 package commands
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"unicode"
 
@@ -29,6 +31,7 @@ import (
 
 	"github.com/spf13/cobra"
 	"github.com/spf13/pflag"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
 	"k8s.io/apimachinery/pkg/util/validation"
 )
 
@@ -47,6 +50,33 @@ func ArgValidationConjunction(validators ...cobra.PositionalArgs) cobra.Position
 	}
 }
 
+// Args returns a PositionalArgs validator that checks there are between min and max arguments (no upper bound if max
+// is negative), then applies the per-argument validators to each of them. All the invalid arguments are reported at
+// once.
+func Args(min int, max int, per ...PositionalArg) cobra.PositionalArgs {
+	count := cobra.RangeArgs(min, max)
+	if max < 0 {
+		count = cobra.MinimumNArgs(min)
+	} else if min == max {
+		count = cobra.ExactArgs(min)
+	}
+	return func(cmd *cobra.Command, args []string) error {
+		if err := count(cmd, args); err != nil {
+			return err
+		}
+		var errs []error
+		for _, arg := range args {
+			for _, validator := range per {
+				if err := validator(cmd, arg); err != nil {
+					errs = append(errs, fmt.Errorf("invalid argument %q: %v", arg, err))
+					break
+				}
+			}
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+}
+
 // UpToDashDash returns a validator that will invoke the `delegate` validator, but only with args before the
 // splitting `--`, if any
 func UpToDashDash(delegate cobra.PositionalArgs) cobra.PositionalArgs {
@@ -70,6 +100,32 @@ func AtPosition(i int, validator PositionalArg) cobra.PositionalArgs {
 	}
 }
 
+// AtPositions returns a PositionalArgs that applies the single valued validator to each of the given arguments.
+// The actual number of arguments is not checked by this function (use cobra's MinimumNArgs, ExactArgs, etc)
+func AtPositions(validator PositionalArg, indices ...int) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		for _, i := range indices {
+			if err := validator(cmd, args[i]); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
+// AllPositions returns a PositionalArgs that applies the single valued validator to every argument, however many
+// there are.
+func AllPositions(validator PositionalArg) cobra.PositionalArgs {
+	return func(cmd *cobra.Command, args []string) error {
+		for _, arg := range args {
+			if err := validator(cmd, arg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+}
+
 // KubernetesValidation turns a kubernetes-style validation function into a PositionalArg
 func KubernetesValidation(k8s func(string) []string) PositionalArg {
 	return func(cmd *cobra.Command, arg string) error {
@@ -86,6 +142,46 @@ func ValidName() PositionalArg {
 	return KubernetesValidation(validation.IsDNS1123Subdomain)
 }
 
+// revisionNameSuffix is an example of the suffix knative appends to a service name to name its revisions
+const revisionNameSuffix = "-00001"
+
+// ValidServiceName returns a PositionalArg that checks the argument can be used as the name of a knative service:
+// it must be a DNS-1123 label, short enough for the names of the generated revisions to be DNS-1123 labels as well.
+func ValidServiceName() PositionalArg {
+	return KubernetesValidation(func(name string) []string {
+		if msgs := validation.IsDNS1123Label(name); len(msgs) > 0 {
+			return msgs
+		}
+		if max := validation.DNS1123LabelMaxLength - len(revisionNameSuffix); len(name) > max {
+			return []string{fmt.Sprintf("must be no more than %d characters", max)}
+		}
+		return nil
+	})
+}
+
+// ValidDuration returns a PositionalArg that checks the argument is a positive duration, as understood by
+// time.ParseDuration (e.g. "30s" or "1h15m").
+func ValidDuration() PositionalArg {
+	return func(cmd *cobra.Command, arg string) error {
+		if _, err := parsePositiveDuration(arg); err != nil {
+			return fmt.Errorf("invalid duration %q: %v", arg, err)
+		}
+		return nil
+	}
+}
+
+// parsePositiveDuration parses a duration, rejecting zero and negative ones.
+func parsePositiveDuration(value string) (time.Duration, error) {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, fmt.Errorf("expected a duration such as 30s or 5m")
+	}
+	if d <= 0 {
+		return 0, fmt.Errorf("must be positive")
+	}
+	return d, nil
+}
+
 func LabelArgs(cmd *cobra.Command, labels ...string) {
 	if cmd.Annotations == nil {
 		cmd.Annotations = make(map[string]string)
@@ -166,6 +262,21 @@ func NotSet(name string) FlagsMatcher {
 	}
 }
 
+// Equals returns a FlagsMatcher that matches when the value of the flag, whatever its type and whether it has been set
+// explicitly or not, renders as the given string.
+func Equals(name string, value string) FlagsMatcher {
+	return flagsMatcher{
+		eval: func(cmd *cobra.Command) bool {
+			f := cmd.Flag(name)
+			if f == nil {
+				panic(fmt.Sprintf("Expected to find flag named %q in command %q", name, cmd.Use))
+			}
+			return f.Value.String() == value
+		},
+		desc: fmt.Sprintf("--%s is '%s'", name, value),
+	}
+}
+
 // FlagsDependency returns a validator that will evaluate the given delegate if the provided flag matcher returns true.
 // Use to enforce scenarios such as "if --foo is set, then --bar must be set as well".
 func FlagsDependency(matcher FlagsMatcher, delegate FlagsValidator) FlagsValidator {
@@ -184,6 +295,60 @@ func FlagsDependency(matcher FlagsMatcher, delegate FlagsValidator) FlagsValidat
 	}
 }
 
+// RequiresAllWhenSet returns a FlagsValidator that asserts that, when the trigger flag is set, all of the required
+// flags are set as well. All the missing flags are reported at once.
+func RequiresAllWhenSet(trigger string, required ...string) FlagsValidator {
+	return FlagsDependency(Set(trigger), allOf(required...))
+}
+
+// RequiredWhenFlagEquals returns a FlagsValidator that asserts that, when the value of flag is the given one, all of
+// the required flags are set. The value of flag is compared in its string form, so flag may be of any type.
+func RequiredWhenFlagEquals(flag string, value string, required ...string) FlagsValidator {
+	return FlagsDependency(Equals(flag, value), allOf(required...))
+}
+
+// allOf returns a FlagsValidator that asserts that all of the given flags are set, reporting all the missing ones at
+// once.
+func allOf(required ...string) FlagsValidator {
+	return func(cmd *cobra.Command) error {
+		var missing []string
+		for _, f := range required {
+			flag := cmd.Flag(f)
+			if flag == nil {
+				panic(fmt.Sprintf("Expected to find flag named %q in command %q", f, cmd.Use))
+			}
+			if !flag.Changed {
+				missing = append(missing, f)
+			}
+		}
+		if len(missing) > 0 {
+			return fmt.Errorf("--%s must be set", strings.Join(missing, ", --"))
+		}
+		return nil
+	}
+}
+
+// Conflicts returns a FlagsValidator that asserts that, when flag is set, none of the conflicting flags is set.
+// Unlike AtMostOneOf, the conflicting flags may be freely combined with each other.
+func Conflicts(flag string, conflicting ...string) FlagsValidator {
+	return func(cmd *cobra.Command) error {
+		for _, f := range append([]string{flag}, conflicting...) {
+			if cmd.Flag(f) == nil {
+				panic(fmt.Sprintf("Expected to find flag named %q in command %q", f, cmd.Use))
+			}
+		}
+		if !cmd.Flag(flag).Changed {
+			return nil
+		}
+		for _, f := range conflicting {
+			if cmd.Flag(f).Changed {
+				return fmt.Errorf("--%s and --%s cannot be set together", flag, f)
+			}
+		}
+		return nil
+	}
+}
+
 // AtLeastOneOf returns a FlagsValidator that asserts that at least one of the passed in flags is set.
 func AtLeastOneOf(flagNames ...string) FlagsValidator {
 	return func(cmd *cobra.Command) error {
@@ -221,6 +386,54 @@ func AtMostOneOf(flagNames ...string) FlagsValidator {
 	}
 }
 
+// ExactlyOneOf returns a FlagsValidator that asserts that one and only one of the passed in flags is set.
+func ExactlyOneOf(flagNames ...string) FlagsValidator {
+	return func(cmd *cobra.Command) error {
+		switch set := countSetFlags(cmd, flagNames); {
+		case set == 0:
+			return fmt.Errorf("one of --%s is required", strings.Join(flagNames, ", --"))
+		case set > 1:
+			return fmt.Errorf("only one of --%s may be set", strings.Join(flagNames, ", --"))
+		default:
+			return nil
+		}
+	}
+}
+
+// AtLeastNOf returns a FlagsValidator that asserts that at least n of the passed in flags are set.
+func AtLeastNOf(n int, flagNames ...string) FlagsValidator {
+	return func(cmd *cobra.Command) error {
+		if countSetFlags(cmd, flagNames) < n {
+			return fmt.Errorf("at least %d of --%s must be set", n, strings.Join(flagNames, ", --"))
+		}
+		return nil
+	}
+}
+
+// AtMostNOf returns a FlagsValidator that asserts that at most n of the passed in flags are set.
+func AtMostNOf(n int, flagNames ...string) FlagsValidator {
+	return func(cmd *cobra.Command) error {
+		if countSetFlags(cmd, flagNames) > n {
+			return fmt.Errorf("at most %d of --%s may be set", n, strings.Join(flagNames, ", --"))
+		}
+		return nil
+	}
+}
+
+func countSetFlags(cmd *cobra.Command, flagNames []string) int {
+	set := 0
+	for _, f := range flagNames {
+		flag := cmd.Flag(f)
+		if flag == nil {
+			panic(fmt.Sprintf("Expected to find flag named %q in command %q", f, cmd.Use))
+		}
+		if flag.Changed {
+			set++
+		}
+	}
+	return set
+}
+
 // NoneOf returns a FlagsValidator that asserts that none of the passed in flags are set.
 func NoneOf(flagNames ...string) FlagsValidator {
 	return func(cmd *cobra.Command) error {
@@ -237,6 +450,96 @@ func NoneOf(flagNames ...string) FlagsValidator {
 	}
 }
 
+// FlagsPositiveDuration returns a FlagsValidator that checks the value of the given flag, when set, is a positive
+// duration. It applies to flags of type duration as well as string flags holding a duration.
+func FlagsPositiveDuration(flagName string) FlagsValidator {
+	return func(cmd *cobra.Command) error {
+		flag := cmd.Flag(flagName)
+		if flag == nil {
+			panic(fmt.Sprintf("Expected to find flag named %q in command %q", flagName, cmd.Use))
+		}
+		if !flag.Changed {
+			return nil
+		}
+		if _, err := parsePositiveDuration(flag.Value.String()); err != nil {
+			return fmt.Errorf("invalid value %q for --%s: %v", flag.Value.String(), flagName, err)
+		}
+		return nil
+	}
+}
+
+// RequiredUnlessTTY returns a FlagsValidator that asserts that all of the given flags are set when the command doesn't
+// run interactively, i.e. when its standard input or output is not a terminal. This is meant for flags that stand for
+// an answer the user is otherwise prompted for, e.g. --yes, which scripts must then give explicitly.
+func RequiredUnlessTTY(flagNames ...string) FlagsValidator {
+	required := allOf(flagNames...)
+	return func(cmd *cobra.Command) error {
+		if isInteractive() {
+			return nil
+		}
+		if err := required(cmd); err != nil {
+			return fmt.Errorf("%v, as the standard input or output is not a terminal", err)
+		}
+		return nil
+	}
+}
+
+// FlagGroup builds a single FlagsValidator out of several constraints on the flags of a command. Unlike
+// FlagsValidationConjunction, all the constraints are checked and all the violations are reported at once.
+type FlagGroup struct {
+	validators []FlagsValidator
+}
+
+// NewFlagGroup returns an empty FlagGroup, to be completed with constraints before calling Build.
+func NewFlagGroup() *FlagGroup {
+	return &FlagGroup{}
+}
+
+// AtLeastOne adds the constraint that at least one of the flags is set.
+func (g *FlagGroup) AtLeastOne(flagNames ...string) *FlagGroup {
+	return g.Check(AtLeastOneOf(flagNames...))
+}
+
+// AtMostOne adds the constraint that at most one of the flags is set.
+func (g *FlagGroup) AtMostOne(flagNames ...string) *FlagGroup {
+	return g.Check(AtMostOneOf(flagNames...))
+}
+
+// ExactlyOne adds the constraint that one and only one of the flags is set.
+func (g *FlagGroup) ExactlyOne(flagNames ...string) *FlagGroup {
+	return g.Check(ExactlyOneOf(flagNames...))
+}
+
+// Requires adds the constraint that, when trigger is set, all of the required flags are set as well.
+func (g *FlagGroup) Requires(trigger string, required ...string) *FlagGroup {
+	return g.Check(RequiresAllWhenSet(trigger, required...))
+}
+
+// Conflicts adds the constraint that, when flag is set, none of the conflicting flags is set.
+func (g *FlagGroup) Conflicts(flag string, conflicting ...string) *FlagGroup {
+	return g.Check(Conflicts(flag, conflicting...))
+}
+
+// Check adds an arbitrary validator to the group, e.g. a FlagsDependency.
+func (g *FlagGroup) Check(validator FlagsValidator) *FlagGroup {
+	g.validators = append(g.validators, validator)
+	return g
+}
+
+// Build returns a FlagsValidator checking all the constraints of the group, in the order they were added.
+func (g *FlagGroup) Build() FlagsValidator {
+	validators := append([]FlagsValidator(nil), g.validators...)
+	return func(cmd *cobra.Command) error {
+		var errs []error
+		for _, v := range validators {
+			if err := v(cmd); err != nil {
+				errs = append(errs, err)
+			}
+		}
+		return utilerrors.NewAggregate(errs)
+	}
+}
+
 type broadcastStringValue []*string
 
 func (bsv broadcastStringValue) Set(v string) error {
@@ -246,6 +549,8 @@ func (bsv broadcastStringValue) Set(v string) error {
 	return nil
 }
 
+// String returns the value of the first pointer only. All pointers hold the same value once the flag has been set,
+// but before that the first pointer is the one that reflects the default value.
 func (bsv broadcastStringValue) String() string {
 	return *bsv[0]
 }
@@ -264,6 +569,69 @@ func BroadcastStringValue(value string, ptrs ...*string) pflag.Value {
 	return broadcastStringValue(ptrs)
 }
 
+// BroadcastStringValueExcept is like BroadcastStringValue, except that the default value is not written to the
+// pointers in except: those keep their own current value until the flag is explicitly set, at which point all
+// pointers (including the ones in except) receive the value. At least one pointer must not be in except.
+func BroadcastStringValueExcept(value string, except []*string, ptrs ...*string) pflag.Value {
+	var defaulted, kept []*string
+	for _, p := range ptrs {
+		if containsStringPointer(except, p) {
+			kept = append(kept, p)
+		} else {
+			defaulted = append(defaulted, p)
+		}
+	}
+	if len(defaulted) < 1 {
+		panic("At least one string pointer not in except must be provided")
+	}
+	for _, p := range defaulted {
+		*p = value
+	}
+	// defaulted pointers come first, so that String() reports the default value
+	return broadcastStringValue(append(defaulted, kept...))
+}
+
+func containsStringPointer(ptrs []*string, ptr *string) bool {
+	for _, p := range ptrs {
+		if p == ptr {
+			return true
+		}
+	}
+	return false
+}
+
+type oneOfStringValue struct {
+	target  *string
+	allowed []string
+}
+
+func (v *oneOfStringValue) Set(value string) error {
+	for _, a := range v.allowed {
+		if value == a {
+			*v.target = value
+			return nil
+		}
+	}
+	return fmt.Errorf("must be one of %s", strings.Join(v.allowed, ", "))
+}
+
+func (v *oneOfStringValue) String() string {
+	return *v.target
+}
+
+func (v *oneOfStringValue) Type() string {
+	return strings.Join(v.allowed, "|")
+}
+
+// OneOfStringValue returns a pflag.Value for flags that only accept a fixed set of values. The current value of
+// target is used as the default.
+func OneOfStringValue(target *string, allowed ...string) pflag.Value {
+	if len(allowed) < 1 {
+		panic("At least one allowed value must be provided")
+	}
+	return &oneOfStringValue{target: target, allowed: allowed}
+}
+
 type broadcastBoolValue []*bool
 
 func (bbv broadcastBoolValue) Set(v string) error {
@@ -297,6 +665,26 @@ func BroadcastBoolValue(value bool, ptrs ...*bool) pflag.Value {
 	return broadcastBoolValue(ptrs)
 }
 
+// AddTimeoutFlag registers the --timeout flag read by ContextWithTimeout, bounding how long the command may block.
+func AddTimeoutFlag(cmd *cobra.Command, defaultTimeout time.Duration) {
+	cmd.Flags().Duration("timeout", defaultTimeout, "the maximum `duration` to wait for the operation to complete")
+}
+
+// ContextWithTimeout returns a context that is done once the duration set by the --timeout flag of the command (see
+// AddTimeoutFlag) has elapsed. The duration must be positive. The returned cancel function must be called to release
+// resources once the command is done.
+func ContextWithTimeout(cmd *cobra.Command) (context.Context, context.CancelFunc, error) {
+	timeout, err := cmd.Flags().GetDuration("timeout")
+	if err != nil {
+		return nil, nil, err
+	}
+	if timeout <= 0 {
+		return nil, nil, fmt.Errorf("--timeout must be positive, got %v", timeout)
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	return ctx, cancel, nil
+}
+
 // =========================================== Usage related functions =================================================
 
 func installAdvancedUsage(rootCmd *cobra.Command) {
@@ -404,3 +792,7 @@ func printSuccessfulCompletion(cmd *cobra.Command) {
 func printInterruptedCompletion(cmd *cobra.Command) {
 	fmt.Fprintf(cmd.OutOrStdout(), "%s was interrupted\n", cmd.CommandPath())
 }
+
+func printAbortedCompletion(cmd *cobra.Command) {
+	fmt.Fprintf(cmd.OutOrStdout(), "%s was aborted\n", cmd.CommandPath())
+}
@@ -17,9 +17,17 @@
 package commands_test
 
 import (
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
 	. "github.com/onsi/ginkgo"
 	. "github.com/onsi/gomega"
 	"github.com/projectriff/riff/cmd/commands"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+	"golang.org/x/crypto/ssh/terminal"
 )
 
 var _ = Describe("The cobra extensions", func() {
@@ -53,5 +61,374 @@ var _ = Describe("The cobra extensions", func() {
 
 		})
 
+		It("should not set the default value to excepted pointers", func() {
+			value1, value2 := "", "own-default"
+
+			v := commands.BroadcastStringValueExcept("the-default", []*string{&value2}, &value2, &value1)
+
+			Expect(value1).To(Equal("the-default"))
+			Expect(value2).To(Equal("own-default"))
+			Expect(v.String()).To(Equal("the-default"))
+		})
+
+		It("should set the value to all pointers, including excepted ones", func() {
+			value1, value2 := "", "own-default"
+
+			v := commands.BroadcastStringValueExcept("the-default", []*string{&value2}, &value1, &value2)
+
+			v.Set("bar")
+
+			Expect(value1).To(Equal("bar"))
+			Expect(value2).To(Equal("bar"))
+		})
+
+		It("should panic when all pointers are excepted", func() {
+			var value1 string
+
+			Expect(func() { commands.BroadcastStringValueExcept("default", []*string{&value1}, &value1) }).To(Panic())
+		})
+
+	})
+
+	Context("the flags count validators", func() {
+		var command *cobra.Command
+
+		BeforeEach(func() {
+			command = &cobra.Command{}
+			command.Flags().String("a", "", "")
+			command.Flags().String("b", "", "")
+			command.Flags().String("c", "", "")
+		})
+
+		It("should accept at most n flags being set", func() {
+			command.Flags().Set("a", "x")
+			command.Flags().Set("b", "x")
+			Expect(commands.AtMostNOf(2, "a", "b", "c")(command)).To(Succeed())
+		})
+
+		It("should reject more than n flags being set", func() {
+			command.Flags().Set("a", "x")
+			command.Flags().Set("b", "x")
+			command.Flags().Set("c", "x")
+			Expect(commands.AtMostNOf(2, "a", "b", "c")(command)).To(MatchError("at most 2 of --a, --b, --c may be set"))
+		})
+
+		It("should accept at least n flags being set", func() {
+			command.Flags().Set("a", "x")
+			command.Flags().Set("c", "x")
+			Expect(commands.AtLeastNOf(2, "a", "b", "c")(command)).To(Succeed())
+		})
+
+		It("should reject fewer than n flags being set", func() {
+			command.Flags().Set("b", "x")
+			Expect(commands.AtLeastNOf(2, "a", "b", "c")(command)).To(MatchError("at least 2 of --a, --b, --c must be set"))
+		})
+
+		It("should panic for unknown flags", func() {
+			Expect(func() { commands.AtMostNOf(1, "a", "d")(command) }).To(Panic())
+		})
+
+		It("should require one flag when none is set", func() {
+			Expect(commands.ExactlyOneOf("a", "b", "c")(command)).To(MatchError("one of --a, --b, --c is required"))
+		})
+
+		It("should accept exactly one flag being set", func() {
+			command.Flags().Set("b", "x")
+			Expect(commands.ExactlyOneOf("a", "b", "c")(command)).To(Succeed())
+		})
+
+		It("should reject more than one flag being set", func() {
+			command.Flags().Set("a", "x")
+			command.Flags().Set("c", "x")
+			Expect(commands.ExactlyOneOf("a", "b", "c")(command)).To(MatchError("only one of --a, --b, --c may be set"))
+		})
+	})
+
+	Context("the flags dependency validators", func() {
+		var command *cobra.Command
+
+		BeforeEach(func() {
+			command = &cobra.Command{}
+			command.Flags().String("git-repo", "", "")
+			command.Flags().String("image", "", "")
+			command.Flags().String("build-template", "", "")
+		})
+
+		It("should not check required flags when the trigger is not set", func() {
+			Expect(commands.RequiresAllWhenSet("git-repo", "image", "build-template")(command)).To(Succeed())
+		})
+
+		It("should accept all required flags being set", func() {
+			command.Flags().Set("git-repo", "x")
+			command.Flags().Set("image", "x")
+			command.Flags().Set("build-template", "x")
+			Expect(commands.RequiresAllWhenSet("git-repo", "image", "build-template")(command)).To(Succeed())
+		})
+
+		It("should report all missing flags", func() {
+			command.Flags().Set("git-repo", "x")
+			Expect(commands.RequiresAllWhenSet("git-repo", "image", "build-template")(command)).
+				To(MatchError("when --git-repo is set, --image, --build-template must be set"))
+		})
+
+		It("should panic for unknown flags", func() {
+			command.Flags().Set("git-repo", "x")
+			Expect(func() { commands.RequiresAllWhenSet("git-repo", "invoker")(command) }).To(Panic())
+		})
+
+		It("should not check required flags when the trigger flag has another value", func() {
+			command.Flags().Set("build-template", "riff")
+			Expect(commands.RequiredWhenFlagEquals("build-template", "kaniko", "image")(command)).To(Succeed())
+		})
+
+		It("should report missing flags when the trigger flag has the value", func() {
+			command.Flags().Set("build-template", "kaniko")
+			Expect(commands.RequiredWhenFlagEquals("build-template", "kaniko", "image", "git-repo")(command)).
+				To(MatchError("when --build-template is 'kaniko', --image, --git-repo must be set"))
+		})
+
+		It("should compare the value of non string flags", func() {
+			command.Flags().Bool("local", true, "")
+			Expect(commands.RequiredWhenFlagEquals("local", "true", "image")(command)).
+				To(MatchError("when --local is 'true', --image must be set"))
+			command.Flags().Set("local", "false")
+			Expect(commands.RequiredWhenFlagEquals("local", "true", "image")(command)).To(Succeed())
+		})
+
+		It("should accept conflicting flags when the flag is not set", func() {
+			command.Flags().Set("image", "x")
+			command.Flags().Set("build-template", "x")
+			Expect(commands.Conflicts("git-repo", "image", "build-template")(command)).To(Succeed())
+		})
+
+		It("should report the flags in conflict", func() {
+			command.Flags().Set("git-repo", "x")
+			command.Flags().Set("build-template", "x")
+			Expect(commands.Conflicts("git-repo", "image", "build-template")(command)).
+				To(MatchError("--git-repo and --build-template cannot be set together"))
+		})
+
+		It("should panic for unknown flags even when not set", func() {
+			Expect(func() { commands.Conflicts("git-repo", "invoker")(command) }).To(Panic())
+		})
+	})
+
+	Context("the flag group builder", func() {
+		var command *cobra.Command
+
+		BeforeEach(func() {
+			command = &cobra.Command{}
+			command.Flags().String("a", "", "")
+			command.Flags().String("b", "", "")
+			command.Flags().String("c", "", "")
+			command.Flags().String("d", "", "")
+		})
+
+		It("should accept flags satisfying all constraints", func() {
+			command.Flags().Set("a", "x")
+			command.Flags().Set("c", "x")
+			command.Flags().Set("d", "x")
+			validator := commands.NewFlagGroup().AtLeastOne("a", "b").AtMostOne("a", "b").Requires("c", "d").Build()
+			Expect(validator(command)).To(Succeed())
+		})
+
+		It("should report all violations at once", func() {
+			command.Flags().Set("a", "x")
+			command.Flags().Set("b", "x")
+			command.Flags().Set("c", "x")
+			validator := commands.NewFlagGroup().AtMostOne("a", "b").Requires("c", "d").Conflicts("a", "d").Build()
+			Expect(validator(command)).To(MatchError("[at most one of --a, --b must be set, when --c is set, --d must be set]"))
+		})
+
+		It("should report a single violation as is", func() {
+			validator := commands.NewFlagGroup().AtLeastOne("a", "b").ExactlyOne("c", "d").Build()
+			command.Flags().Set("c", "x")
+			Expect(validator(command)).To(MatchError("at least one of --a, --b must be set"))
+		})
+
+		It("should accept arbitrary validators", func() {
+			validator := commands.NewFlagGroup().Check(commands.FlagsDependency(commands.NotSet("a"), commands.NoneOf("b"))).Build()
+			command.Flags().Set("b", "x")
+			Expect(validator(command)).To(MatchError("when --a is not set, --b should not be set"))
+		})
+	})
+
+	Context("the non-interactive validator", func() {
+		var command *cobra.Command
+
+		BeforeEach(func() {
+			if terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd())) {
+				Skip("running in a terminal")
+			}
+			command = &cobra.Command{}
+			command.Flags().Bool("yes", false, "")
+			command.Flags().Bool("force", false, "")
+		})
+
+		It("should require the flags when not running in a terminal", func() {
+			validator := commands.RequiredUnlessTTY("yes", "force")
+			command.Flags().Set("force", "true")
+			Expect(validator(command)).To(MatchError("--yes must be set, as the standard input or output is not a terminal"))
+		})
+
+		It("should accept the flags once set", func() {
+			command.Flags().Set("yes", "true")
+			Expect(commands.RequiredUnlessTTY("yes")(command)).To(Succeed())
+		})
+	})
+
+	Context("the duration validators", func() {
+		var command *cobra.Command
+
+		BeforeEach(func() {
+			command = &cobra.Command{}
+			command.Flags().Duration("timeout", time.Minute, "")
+			command.Flags().String("poll-interval", "", "")
+		})
+
+		It("should accept positive durations", func() {
+			Expect(commands.ValidDuration()(command, "1h15m")).To(Succeed())
+			command.Flags().Set("timeout", "30s")
+			Expect(commands.FlagsPositiveDuration("timeout")(command)).To(Succeed())
+		})
+
+		It("should ignore flags that are not set", func() {
+			Expect(commands.FlagsPositiveDuration("poll-interval")(command)).To(Succeed())
+		})
+
+		It("should reject zero durations", func() {
+			Expect(commands.ValidDuration()(command, "0s")).To(MatchError(`invalid duration "0s": must be positive`))
+			command.Flags().Set("timeout", "0s")
+			Expect(commands.FlagsPositiveDuration("timeout")(command)).To(MatchError(`invalid value "0s" for --timeout: must be positive`))
+		})
+
+		It("should reject negative durations", func() {
+			Expect(commands.ValidDuration()(command, "-5m")).To(MatchError(`invalid duration "-5m": must be positive`))
+			command.Flags().Set("timeout", "-5m")
+			Expect(commands.FlagsPositiveDuration("timeout")(command)).To(MatchError(`invalid value "-5m0s" for --timeout: must be positive`))
+		})
+
+		It("should reject malformed durations", func() {
+			Expect(commands.ValidDuration()(command, "5 minutes")).To(MatchError(`invalid duration "5 minutes": expected a duration such as 30s or 5m`))
+			command.Flags().Set("poll-interval", "5")
+			Expect(commands.FlagsPositiveDuration("poll-interval")(command)).To(MatchError(`invalid value "5" for --poll-interval: expected a duration such as 30s or 5m`))
+		})
+	})
+
+	Context("the service name validator", func() {
+		validate := func(name string) error {
+			return commands.ValidServiceName()(&cobra.Command{}, name)
+		}
+
+		It("should accept names short enough for revision names", func() {
+			Expect(validate(strings.Repeat("a", 57))).To(Succeed())
+		})
+
+		It("should reject names too long for revision names", func() {
+			Expect(validate(strings.Repeat("a", 58))).To(MatchError("must be no more than 57 characters"))
+		})
+
+		It("should reject names that are not DNS labels", func() {
+			Expect(validate("my.service")).To(MatchError(ContainSubstring("a DNS-1123 label must consist of")))
+		})
+	})
+
+	Context("the argument count and per-argument validator", func() {
+		validator := commands.Args(1, 3, commands.ValidName())
+
+		It("should accept a valid number of valid arguments", func() {
+			Expect(validator(&cobra.Command{}, []string{"square", "cube"})).To(Succeed())
+		})
+
+		It("should check the number of arguments first", func() {
+			Expect(validator(&cobra.Command{}, []string{})).To(MatchError("accepts between 1 and 3 arg(s), received 0"))
+			Expect(validator(&cobra.Command{}, []string{"a", "b", "c", "D"})).To(MatchError("accepts between 1 and 3 arg(s), received 4"))
+		})
+
+		It("should report all the invalid arguments", func() {
+			err := validator(&cobra.Command{}, []string{"Square", "cube", "Cube"})
+			Expect(err).To(MatchError(And(
+				ContainSubstring(`invalid argument "Square": a DNS-1123 subdomain must consist of`),
+				ContainSubstring(`invalid argument "Cube": a DNS-1123 subdomain must consist of`),
+			)))
+			Expect(err.Error()).NotTo(ContainSubstring(`"cube"`))
+		})
+
+		It("should support exact and unbounded counts", func() {
+			Expect(commands.Args(2, 2)(&cobra.Command{}, []string{"a"})).To(MatchError("accepts 2 arg(s), received 1"))
+			Expect(commands.Args(1, -1)(&cobra.Command{}, []string{"a", "b", "c", "d"})).To(Succeed())
+		})
+	})
+
+	Context("the positional validators", func() {
+		var calls []string
+		validator := func(cmd *cobra.Command, arg string) error {
+			calls = append(calls, arg)
+			if arg == "bad" {
+				return fmt.Errorf("bad arg")
+			}
+			return nil
+		}
+
+		BeforeEach(func() {
+			calls = nil
+		})
+
+		It("should validate the given positions only", func() {
+			err := commands.AtPositions(validator, 0, 2)(&cobra.Command{}, []string{"a", "bad", "c"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal([]string{"a", "c"}))
+		})
+
+		It("should fail when one of the given positions is invalid", func() {
+			err := commands.AtPositions(validator, 0, 1)(&cobra.Command{}, []string{"a", "bad", "c"})
+			Expect(err).To(MatchError("bad arg"))
+		})
+
+		It("should validate all positions", func() {
+			err := commands.AllPositions(validator)(&cobra.Command{}, []string{"a", "b", "c"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(calls).To(Equal([]string{"a", "b", "c"}))
+		})
+
+		It("should fail when any position is invalid", func() {
+			err := commands.AllPositions(validator)(&cobra.Command{}, []string{"a", "b", "bad"})
+			Expect(err).To(MatchError("bad arg"))
+		})
+	})
+
+	Context("the one of string value", func() {
+		var (
+			value string
+			v     pflag.Value
+		)
+
+		BeforeEach(func() {
+			value = "table"
+			v = commands.OneOfStringValue(&value, "table", "json", "yaml")
+		})
+
+		It("should keep the current value as the default", func() {
+			Expect(v.String()).To(Equal("table"))
+		})
+
+		It("should accept allowed values", func() {
+			Expect(v.Set("json")).To(Succeed())
+			Expect(value).To(Equal("json"))
+			Expect(v.String()).To(Equal("json"))
+		})
+
+		It("should reject other values, listing valid choices", func() {
+			Expect(v.Set("xml")).To(MatchError("must be one of table, json, yaml"))
+			Expect(value).To(Equal("table"))
+		})
+
+		It("should describe the allowed values as its type", func() {
+			Expect(v.Type()).To(Equal("table|json|yaml"))
+		})
+
+		It("should panic when constructed with no allowed value", func() {
+			Expect(func() { commands.OneOfStringValue(&value) }).To(Panic())
+		})
 	})
 })
@@ -0,0 +1,195 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// functionNamesCompletionAnnotation marks commands whose positional args are function names, see
+	// CompleteFunctionNames
+	functionNamesCompletionAnnotation = "riff_completion_function_names"
+
+	// completionTimeout bounds how long looking up completions may take, so that completion never blocks the shell
+	completionTimeout = 2 * time.Second
+)
+
+// CompletionFunc returns the candidates to complete toComplete with, given the already provided args, in the style
+// of cobra's ValidArgsFunction. It never fails, returning no candidates instead.
+type CompletionFunc func(cmd *cobra.Command, args []string, toComplete string) []string
+
+// FunctionNameCompletion returns a CompletionFunc listing the names of the functions in the namespace set by the
+// --namespace flag of the command, if any. No candidates are returned if the functions can't be listed in a timely
+// manner, e.g. because the cluster is unreachable.
+func FunctionNameCompletion(fcTool *core.Client) CompletionFunc {
+	return func(cmd *cobra.Command, args []string, toComplete string) []string {
+		options := core.ListFunctionOptions{}
+		if flag := cmd.Flags().Lookup("namespace"); flag != nil {
+			options.Namespace = flag.Value.String()
+		}
+
+		result := make(chan []string, 1)
+		go func() {
+			functions, err := (*fcTool).ListFunctions(options)
+			if err != nil {
+				result <- nil
+				return
+			}
+			var names []string
+			for _, function := range functions.Items {
+				if strings.HasPrefix(function.Name, toComplete) {
+					names = append(names, function.Name)
+				}
+			}
+			result <- names
+		}()
+
+		select {
+		case names := <-result:
+			return names
+		case <-time.After(completionTimeout):
+			return nil
+		}
+	}
+}
+
+// CompleteFunctionNames marks the positional args of a command as function names, to be completed as such by the
+// shell completion script.
+func CompleteFunctionNames(cmd *cobra.Command) {
+	if cmd.Annotations == nil {
+		cmd.Annotations = make(map[string]string)
+	}
+	cmd.Annotations[functionNamesCompletionAnnotation] = "true"
+}
+
+// CompleteFunctionNamesCommand returns the hidden command the shell completion script uses to complete function
+// names, printing one candidate per line.
+func CompleteFunctionNamesCommand(fcTool *core.Client) *cobra.Command {
+	complete := FunctionNameCompletion(fcTool)
+
+	command := &cobra.Command{
+		Use:    "__complete-function-names",
+		Short:  "list function names for shell completion",
+		Hidden: true,
+		Args:   cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			toComplete := ""
+			if len(args) > 0 {
+				toComplete = args[0]
+			}
+			for _, name := range complete(cmd, nil, toComplete) {
+				fmt.Fprintln(cmd.OutOrStdout(), name)
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringP("namespace", "n", "", "the `namespace` of the functions")
+
+	return command
+}
+
+var bashCompletionFunctionTemplate = template.Must(template.New("bash").Parse(`
+__riff_complete_function_names()
+{
+    local namespace=() i
+    for ((i=0; i < ${#words[@]} - 1; i++)); do
+        case "${words[i]}" in
+            -n|--namespace) namespace=(--namespace "${words[i+1]}") ;;
+        esac
+    done
+    local out
+    if out=$(riff __complete-function-names "${namespace[@]}" "${cur}" 2>/dev/null); then
+        COMPREPLY=( $(compgen -W "${out}" -- "${cur}") )
+    fi
+}
+
+__custom_func()
+{
+    case ${last_command} in
+{{- range .}}
+        {{.}})
+            __riff_complete_function_names
+            return
+            ;;
+{{- end}}
+        *)
+            ;;
+    esac
+}
+`))
+
+func Completion(rootCmd *cobra.Command) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "completion",
+		Short: "Generate a shell completion script",
+		Long: `Generate a shell completion script for riff, printed on stdout.
+
+Function names are completed dynamically, by listing the functions of the cluster, with bash only.`,
+		Example: `  source <(riff completion bash)
+  riff completion zsh > "${fpath[1]}/_riff"`,
+		ValidArgs: []string{"bash", "zsh"},
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(1),
+			cobra.OnlyValidArgs,
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			switch args[0] {
+			case "bash":
+				customFunc, err := bashCompletionFunction(rootCmd)
+				if err != nil {
+					return err
+				}
+				rootCmd.BashCompletionFunction = customFunc
+				return rootCmd.GenBashCompletion(cmd.OutOrStdout())
+			default:
+				return rootCmd.GenZshCompletion(cmd.OutOrStdout())
+			}
+		},
+	}
+
+	LabelArgs(command, "SHELL")
+
+	return command
+}
+
+// bashCompletionFunction returns the bash functions completing the args of marked commands with function names.
+func bashCompletionFunction(rootCmd *cobra.Command) (string, error) {
+	var commands []string
+	Visit(rootCmd, func(c *cobra.Command) error {
+		if c.Annotations[functionNamesCompletionAnnotation] == "true" {
+			// this is how cobra names commands in the generated script
+			name := strings.Replace(c.CommandPath(), " ", "_", -1)
+			commands = append(commands, strings.Replace(name, ":", "__", -1))
+		}
+		return nil
+	})
+	sort.Strings(commands)
+
+	buf := &bytes.Buffer{}
+	err := bashCompletionFunctionTemplate.Execute(buf, commands)
+	return buf.String(), err
+}
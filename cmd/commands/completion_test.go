@@ -0,0 +1,102 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/projectriff/riff/pkg/core/mocks"
+	"github.com/spf13/cobra"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("The function names completion", func() {
+
+	var (
+		client core.Client
+		asMock *mocks.Client
+		cmd    *cobra.Command
+	)
+
+	BeforeEach(func() {
+		client = new(mocks.Client)
+		asMock = client.(*mocks.Client)
+		cmd = commands.CompleteFunctionNamesCommand(&client)
+	})
+	AfterEach(func() {
+		asMock.AssertExpectations(GinkgoT())
+	})
+
+	It("should list the functions matching the prefix", func() {
+		o := core.ListFunctionOptions{}
+		o.Namespace = "ns"
+		list := &v1alpha1.ServiceList{
+			Items: []v1alpha1.Service{
+				{ObjectMeta: meta_v1.ObjectMeta{Name: "square"}},
+				{ObjectMeta: meta_v1.ObjectMeta{Name: "cube"}},
+				{ObjectMeta: meta_v1.ObjectMeta{Name: "squirrel"}},
+			},
+		}
+		asMock.On("ListFunctions", o).Return(list, nil)
+
+		cmd.SetArgs([]string{"sq", "--namespace", "ns"})
+		stdout := &strings.Builder{}
+		cmd.SetOutput(stdout)
+		err := cmd.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("square\nsquirrel\n"))
+	})
+
+	It("should complete nothing when functions can't be listed", func() {
+		asMock.On("ListFunctions", core.ListFunctionOptions{}).Return(nil, fmt.Errorf("connection refused"))
+
+		names := commands.FunctionNameCompletion(&client)(cmd, nil, "")
+		Expect(names).To(BeEmpty())
+	})
+})
+
+var _ = Describe("The riff completion command", func() {
+
+	var rootCommand *cobra.Command
+
+	BeforeEach(func() {
+		rootCommand = commands.CreateAndWireRootCommand()
+	})
+
+	It("should fail with an unsupported shell", func() {
+		rootCommand.SetArgs([]string{"completion", "fish"})
+		rootCommand.SetOutput(&strings.Builder{})
+		err := rootCommand.Execute()
+		Expect(err).To(MatchError(`invalid argument "fish" for "riff completion"`))
+	})
+
+	It("should complete function names of commands acting on functions", func() {
+		rootCommand.SetArgs([]string{"completion", "bash", "--kubeconfig", "/dev/null", "--master", "https://127.0.0.1:6443"})
+		stdout := &strings.Builder{}
+		rootCommand.SetOutput(stdout)
+		err := rootCommand.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(ContainSubstring("riff_function_delete)\n            __riff_complete_function_names"))
+		Expect(stdout.String()).NotTo(ContainSubstring("riff_function_create)\n            __riff_complete_function_names"))
+	})
+})
@@ -0,0 +1,73 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/projectriff/riff/pkg/core"
+)
+
+// FormatConditions renders conditions as an aligned table, with their type, status, reason, age (time since their
+// last transition) and message. Missing fields are rendered as placeholders, and an empty list as "<none>".
+func FormatConditions(conditions []core.ConditionDescription) string {
+	if len(conditions) == 0 {
+		return "<none>\n"
+	}
+	now := time.Now()
+	out := &strings.Builder{}
+	w := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintf(w, "TYPE\tSTATUS\tREASON\tAGE\tMESSAGE\n")
+	for _, c := range conditions {
+		age := "<unknown>"
+		if !c.LastTransitionTime.IsZero() {
+			age = humanDuration(now.Sub(c.LastTransitionTime))
+		}
+		fmt.Fprintf(w, "%s\t%s\t%s\t%s\t%s\n", valueOr(c.Type, "<unknown>"), valueOr(c.Status, "Unknown"), c.Reason, age, c.Message)
+	}
+	w.Flush()
+	return out.String()
+}
+
+// humanDuration renders d with a single, coarse unit as kubectl does for ages, e.g. "42s", "5m", "3h" or "12d".
+func humanDuration(d time.Duration) string {
+	switch {
+	case d < 0:
+		// clocks of the client and the cluster disagree, the transition is recent anyway
+		return "0s"
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 48*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	case d < 2*365*24*time.Hour:
+		return fmt.Sprintf("%dd", int(d.Hours()/24))
+	default:
+		return fmt.Sprintf("%dy", int(d.Hours()/24/365))
+	}
+}
+
+func valueOr(v string, placeholder string) string {
+	if v == "" {
+		return placeholder
+	}
+	return v
+}
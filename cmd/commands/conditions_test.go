@@ -0,0 +1,56 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/projectriff/riff/pkg/core"
+)
+
+var _ = Describe("The conditions formatter", func() {
+
+	It("should render the age of conditions", func() {
+		now := time.Now()
+		conditions := []core.ConditionDescription{
+			{Type: "Ready", Status: "True", LastTransitionTime: now.Add(-30 * time.Second)},
+			{Type: "ConfigurationsReady", Status: "True", LastTransitionTime: now.Add(-3 * time.Hour)},
+			{Type: "RoutesReady", Status: "False", Reason: "Failed", Message: "oops", LastTransitionTime: now.Add(-72 * time.Hour)},
+			{Type: "Old", Status: "True", LastTransitionTime: now.Add(-3 * 365 * 24 * time.Hour)},
+		}
+		Expect(commands.FormatConditions(conditions)).To(Equal(`TYPE                 STATUS  REASON  AGE  MESSAGE
+Ready                True            30s  
+ConfigurationsReady  True            3h   
+RoutesReady          False   Failed  3d   oops
+Old                  True            3y   
+`))
+	})
+
+	It("should render missing fields as placeholders", func() {
+		conditions := []core.ConditionDescription{{Reason: "Pending"}}
+		Expect(commands.FormatConditions(conditions)).To(Equal(`TYPE       STATUS   REASON   AGE        MESSAGE
+<unknown>  Unknown  Pending  <unknown>  
+`))
+	})
+
+	It("should render an empty list of conditions", func() {
+		Expect(commands.FormatConditions(nil)).To(Equal("<none>\n"))
+	})
+})
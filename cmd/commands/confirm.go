@@ -0,0 +1,82 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/cobra"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+// Confirm writes the prompt to w, followed by " [y/N] ", and reads the answer from r. Only "y" or "yes", in any case,
+// confirm: any other answer, including none at all, doesn't.
+func Confirm(w io.Writer, r io.Reader, prompt string) (bool, error) {
+	if _, err := fmt.Fprintf(w, "%s [y/N] ", prompt); err != nil {
+		return false, err
+	}
+	answer, err := bufio.NewReader(r).ReadString('\n')
+	if err != nil && err != io.EOF {
+		return false, err
+	}
+	switch strings.ToLower(strings.TrimSpace(answer)) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
+
+// AddYesFlag registers the --yes (-y) flag read by Confirmed, skipping the confirmation prompt of a destructive
+// command.
+func AddYesFlag(cmd *cobra.Command) {
+	cmd.Flags().BoolP("yes", "y", false, "don't prompt for confirmation; required when the standard input or output is not a terminal")
+}
+
+// Confirmed tells whether the user confirmed the destructive operation described by prompt, either with the --yes
+// flag of the command (see AddYesFlag) or by answering the prompt. As the prompt can only be answered interactively,
+// --yes is required otherwise: commands should check it up front with RequiredUnlessTTY("yes").
+func Confirmed(cmd *cobra.Command, prompt string) (bool, error) {
+	if err := RequiredUnlessTTY("yes")(cmd); err != nil {
+		return false, err
+	}
+	yes, err := cmd.Flags().GetBool("yes")
+	if err != nil {
+		return false, err
+	}
+	if yes {
+		return true, nil
+	}
+	return Confirm(cmd.OutOrStdout(), os.Stdin, prompt)
+}
+
+// isInteractive tells whether both the standard input and output are terminals, so that the user can be prompted.
+func isInteractive() bool {
+	return terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd()))
+}
+
+// inNamespace describes the namespace resources are in, for prompts. Nothing is said of the namespace when it is
+// empty, as when the command runs without the kube flags resolving the default one.
+func inNamespace(namespace string) string {
+	if namespace == "" {
+		return ""
+	}
+	return fmt.Sprintf(" in namespace %q", namespace)
+}
@@ -0,0 +1,64 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/projectriff/riff/pkg/core/mocks"
+	"golang.org/x/crypto/ssh/terminal"
+)
+
+var _ = Describe("The confirmation prompt", func() {
+
+	It("should confirm when answered yes", func() {
+		for _, answer := range []string{"y\n", "yes\n", "YES\n", " Y \n", "y"} {
+			out := &strings.Builder{}
+			ok, err := commands.Confirm(out, strings.NewReader(answer), "Delete function square?")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeTrue(), "answer %q", answer)
+			Expect(out.String()).To(Equal("Delete function square? [y/N] "))
+		}
+	})
+
+	It("should not confirm otherwise", func() {
+		for _, answer := range []string{"n\n", "no\n", "\n", "", "yep\n"} {
+			ok, err := commands.Confirm(&strings.Builder{}, strings.NewReader(answer), "Delete function square?")
+			Expect(err).NotTo(HaveOccurred())
+			Expect(ok).To(BeFalse(), "answer %q", answer)
+		}
+	})
+
+	It("should require --yes when not running interactively", func() {
+		if terminal.IsTerminal(int(os.Stdin.Fd())) && terminal.IsTerminal(int(os.Stdout.Fd())) {
+			Skip("running in a terminal")
+		}
+		client := core.Client(new(mocks.Client))
+		asMock := client.(*mocks.Client)
+		fd := commands.FunctionDelete(&client)
+		fd.SetArgs([]string{"square"})
+
+		err := fd.Execute()
+		Expect(err).To(MatchError("--yes must be set, as the standard input or output is not a terminal"))
+		asMock.AssertExpectations(GinkgoT())
+	})
+})
@@ -0,0 +1,60 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+const (
+	// doctorTimeout is how long riff doctor waits for the cluster by default
+	doctorTimeout = 30 * time.Second
+)
+
+func Doctor(fcTool *core.Client) *cobra.Command {
+	command := &cobra.Command{
+		Use:   "doctor",
+		Short: "Check that the cluster is ready for riff",
+		Long: `Check that the cluster is reachable and that knative serving, which riff functions run on, is installed.
+
+Run this to diagnose setup problems before creating functions.`,
+		Example: `  riff doctor
+  riff doctor --timeout 5s`,
+		Args: cobra.ExactArgs(0),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			ctx, cancel, err := ContextWithTimeout(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+			if err := (*fcTool).Ping(ctx); err != nil {
+				return err
+			}
+
+			fmt.Fprintln(cmd.OutOrStdout(), "The cluster is reachable and knative serving is installed")
+			return nil
+		},
+	}
+
+	AddTimeoutFlag(command, doctorTimeout)
+
+	return command
+}
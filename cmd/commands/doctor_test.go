@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"errors"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/projectriff/riff/pkg/core/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("The riff doctor command", func() {
+	var (
+		client core.Client
+		asMock *mocks.Client
+		doctor *cobra.Command
+	)
+	BeforeEach(func() {
+		client = new(mocks.Client)
+		asMock = client.(*mocks.Client)
+
+		doctor = commands.Doctor(&client)
+	})
+	AfterEach(func() {
+		asMock.AssertExpectations(GinkgoT())
+	})
+	It("should report a healthy cluster", func() {
+		doctor.SetArgs([]string{})
+		asMock.On("Ping", mock.Anything).Return(nil)
+
+		stdout := &strings.Builder{}
+		doctor.SetOutput(stdout)
+		err := doctor.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("The cluster is reachable and knative serving is installed\n"))
+	})
+	It("should report a missing knative serving", func() {
+		doctor.SetArgs([]string{})
+		asMock.On("Ping", mock.Anything).Return(&core.ServingNotInstalledError{GroupVersion: "serving.knative.dev/v1alpha1"})
+
+		err := doctor.Execute()
+		Expect(err).To(MatchError("knative serving not installed, the cluster doesn't serve serving.knative.dev/v1alpha1 services"))
+	})
+	It("should report an unreachable cluster", func() {
+		doctor.SetArgs([]string{})
+		asMock.On("Ping", mock.Anything).Return(&core.ClusterUnreachableError{Cause: errors.New("connection refused")})
+
+		err := doctor.Execute()
+		Expect(err).To(MatchError("cluster unreachable: connection refused"))
+	})
+})
@@ -0,0 +1,115 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/projectriff/riff/pkg/core"
+	api_errors "k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Exit codes of the riff process, so that scripts can tell common failures apart. 2 is left out as shells use it for
+// misuse of builtins.
+const (
+	ExitCodeError         = 1
+	ExitCodeNotFound      = 3
+	ExitCodeAlreadyExists = 4
+	ExitCodeForbidden     = 5
+	ExitCodeTimeout       = 6
+)
+
+// ExitError is a command failure that comes with the exit code of the process. Its message is meant for users, the
+// original error being kept as its Cause.
+type ExitError struct {
+	Code    int
+	Message string
+	Cause   error
+}
+
+func (e *ExitError) Error() string {
+	return e.Message
+}
+
+// HandleError translates the errors commonly returned by the kubernetes API server, as well as timeouts, into an
+// ExitError with a concise message and a distinct exit code. Other errors, and nil, are returned as is.
+func HandleError(err error) error {
+	switch e := err.(type) {
+	case nil, *ExitError:
+		return err
+	case *core.FunctionNotFoundError:
+		return &ExitError{Code: ExitCodeNotFound, Message: err.Error(), Cause: err}
+	case *core.FunctionAlreadyExistsError:
+		return &ExitError{Code: ExitCodeAlreadyExists, Message: err.Error(), Cause: err}
+	case *core.WaitError:
+		if e.Cause == context.DeadlineExceeded {
+			return &ExitError{Code: ExitCodeTimeout, Message: err.Error(), Cause: err}
+		}
+		return err
+	case *core.BuildTimeoutError:
+		return &ExitError{Code: ExitCodeTimeout, Message: err.Error(), Cause: err}
+	case api_errors.APIStatus:
+		return handleStatusError(e, err)
+	}
+	if err == context.DeadlineExceeded {
+		return &ExitError{Code: ExitCodeTimeout, Message: err.Error(), Cause: err}
+	}
+	return err
+}
+
+// handleStatusError translates the error of the kubernetes API server with the given status.
+func handleStatusError(status api_errors.APIStatus, err error) error {
+	switch status.Status().Reason {
+	case meta_v1.StatusReasonNotFound:
+		return &ExitError{Code: ExitCodeNotFound, Message: fmt.Sprintf("%s not found", describeResource(status)), Cause: err}
+	case meta_v1.StatusReasonAlreadyExists:
+		return &ExitError{Code: ExitCodeAlreadyExists, Message: fmt.Sprintf("%s already exists", describeResource(status)), Cause: err}
+	case meta_v1.StatusReasonForbidden:
+		return &ExitError{Code: ExitCodeForbidden,
+			Message: fmt.Sprintf("not allowed to access %s; check the permissions of the kubeconfig user", describeResource(status)),
+			Cause:   err}
+	case meta_v1.StatusReasonTimeout, meta_v1.StatusReasonServerTimeout:
+		return &ExitError{Code: ExitCodeTimeout, Message: "the cluster timed out handling the request, try again later", Cause: err}
+	}
+	return err
+}
+
+// ExitCode returns the exit code of the process for an error returned by a command.
+func ExitCode(err error) int {
+	if exitErr, ok := err.(*ExitError); ok {
+		return exitErr.Code
+	}
+	return ExitCodeError
+}
+
+// describeResource names the resource an API error is about, e.g. `services.serving.knative.dev "square"`.
+func describeResource(status api_errors.APIStatus) string {
+	details := status.Status().Details
+	if details == nil || details.Kind == "" {
+		return "the resource"
+	}
+	kind := details.Kind
+	if details.Group != "" {
+		kind += "." + details.Group
+	}
+	if details.Name == "" {
+		return kind
+	}
+	return fmt.Sprintf("%s %q", kind, details.Name)
+}
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("The error handler", func() {
+
+	services := schema.GroupResource{Group: "serving.knative.dev", Resource: "services"}
+
+	It("should leave nil and unknown errors alone", func() {
+		Expect(commands.HandleError(nil)).To(BeNil())
+		err := fmt.Errorf("boom")
+		Expect(commands.HandleError(err)).To(BeIdenticalTo(err))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeError))
+	})
+
+	It("should translate not found errors", func() {
+		cause := errors.NewNotFound(services, "square")
+		err := commands.HandleError(cause)
+		Expect(err).To(MatchError(`services.serving.knative.dev "square" not found`))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeNotFound))
+		Expect(err.(*commands.ExitError).Cause).To(BeIdenticalTo(cause))
+	})
+
+	It("should translate functions that do not exist", func() {
+		err := commands.HandleError(&core.FunctionNotFoundError{Name: "square", Namespace: "default"})
+		Expect(err).To(MatchError(`function "square" does not exist in namespace "default"`))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeNotFound))
+	})
+
+	It("should translate already exists errors", func() {
+		err := commands.HandleError(errors.NewAlreadyExists(services, "square"))
+		Expect(err).To(MatchError(`services.serving.knative.dev "square" already exists`))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeAlreadyExists))
+	})
+
+	It("should translate functions that already exist", func() {
+		err := commands.HandleError(&core.FunctionAlreadyExistsError{Name: "square", Namespace: "default"})
+		Expect(err).To(MatchError(`function "square" already exists in namespace "default"`))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeAlreadyExists))
+	})
+
+	It("should translate forbidden errors", func() {
+		err := commands.HandleError(errors.NewForbidden(services, "square", fmt.Errorf(`User "me" cannot get services`)))
+		Expect(err).To(MatchError(`not allowed to access services.serving.knative.dev "square"; check the permissions of the kubeconfig user`))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeForbidden))
+	})
+
+	It("should translate timeouts", func() {
+		err := commands.HandleError(errors.NewServerTimeout(services, "create", 1))
+		Expect(err).To(MatchError("the cluster timed out handling the request, try again later"))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeTimeout))
+
+		err = commands.HandleError(&core.WaitError{Message: `function "square" did not become ready`, Cause: context.DeadlineExceeded})
+		Expect(err).To(MatchError(`function "square" did not become ready: context deadline exceeded`))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeTimeout))
+
+		err = commands.HandleError(&core.BuildTimeoutError{Function: "square", Namespace: "ns", Timeout: time.Minute})
+		Expect(err).To(MatchError(`build of function "square" in namespace "ns" did not complete within 1m0s`))
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeTimeout))
+
+		err = commands.HandleError(context.DeadlineExceeded)
+		Expect(commands.ExitCode(err)).To(Equal(commands.ExitCodeTimeout))
+	})
+
+	It("should not translate waits that were cancelled", func() {
+		cancelled := &core.WaitError{Message: `function "square" did not become ready`, Cause: context.Canceled}
+		Expect(commands.HandleError(cancelled)).To(BeIdenticalTo(cancelled))
+	})
+
+	It("should not translate errors twice", func() {
+		err := commands.HandleError(errors.NewNotFound(services, "square"))
+		Expect(commands.HandleError(err)).To(BeIdenticalTo(err))
+	})
+})
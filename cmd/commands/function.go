@@ -18,10 +18,25 @@ package commands
 
 import (
 	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
 
 	"github.com/knative/eventing/pkg/apis/channels/v1alpha1"
+	v1alpha12 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	"github.com/projectriff/riff/pkg/core"
 	"github.com/spf13/cobra"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// functionWaitTimeout is how long commands wait for a function to become ready, or be removed, by default
+	functionWaitTimeout = 10 * time.Minute
+	// functionInvokeTimeout is how long function invoke waits for a response by default
+	functionInvokeTimeout = 60 * time.Second
 )
 
 const (
@@ -30,6 +45,71 @@ const (
 	functionCreateNumberOfArgs
 )
 
+const (
+	functionCreateFromFileFileIndex = iota
+	functionCreateFromFileNumberOfArgs
+)
+
+const (
+	functionListNumberOfArgs = iota
+)
+
+const (
+	functionGetFunctionNameIndex = iota
+	functionGetNumberOfArgs
+)
+
+const (
+	functionExportFunctionNameIndex = iota
+	functionExportNumberOfArgs
+)
+
+const (
+	functionDescribeFunctionNameIndex = iota
+	functionDescribeNumberOfArgs
+)
+
+const (
+	functionUpdateFunctionNameIndex = iota
+	functionUpdateNumberOfArgs
+)
+
+const (
+	functionScaleFunctionNameIndex = iota
+	functionScaleNumberOfArgs
+)
+
+const (
+	functionEditFunctionNameIndex = iota
+	functionEditNumberOfArgs
+)
+
+const (
+	functionInvokeFunctionNameIndex = iota
+	functionInvokeNumberOfArgs
+)
+
+const (
+	functionLogsFunctionNameIndex = iota
+	functionLogsNumberOfArgs
+)
+
+const (
+	functionBuildFunctionNameIndex = iota
+	functionBuildNumberOfArgs
+)
+
+const (
+	functionCloneFunctionNameIndex = iota
+	functionCloneNewNameIndex
+	functionCloneNumberOfArgs
+)
+
+const (
+	functionSubscribeFunctionNameIndex = iota
+	functionSubscribeNumberOfArgs
+)
+
 func Function() *cobra.Command {
 	return &cobra.Command{
 		Use:   "function",
@@ -42,6 +122,12 @@ func FunctionCreate(fcTool *core.Client) *cobra.Command {
 	createChannelOptions := core.CreateChannelOptions{}
 	createFunctionOptions := core.CreateFunctionOptions{}
 	createSubscriptionOptions := core.CreateSubscriptionOptions{}
+	waitForFunctionReadyOptions := core.WaitForFunctionReadyOptions{}
+	wait, buildLogs := false, false
+	var labels, annotations, podLabels, podAnnotations []string
+	pullPolicy := ""
+	var livenessHTTP, readinessHTTP string
+	var livenessTCP, readinessTCP bool
 
 	invokers := map[string]string{
 		"command": "https://github.com/projectriff/command-function-invoker/raw/v0.0.7/command-invoker.yaml",
@@ -58,21 +144,43 @@ The INVOKER arg defines the language invoker that is added to the function code
 then used to create a Knative Service (service.serving.knative.dev) instance of the name specified for the function. 
 From then on you can use the sub-commands for the 'service' command to interact with the service created for the function. 
 
+Without a Git repo, nothing is built and the function runs the provided image as is. Its tag can then be pinned to the
+digest it currently refers to with --pin-digest, so that the function keeps running the same image even if the tag is
+later moved. The tag is kept, with a warning, if the digest can't be resolved from the registry, unless --require-digest
+is set. Registries with a certificate signed by a private authority are trusted with --registry-ca-file. For registries
+with a self-signed certificate, or not serving HTTPS, --registry-insecure skips verifying the certificate: anyone able
+to intercept the traffic to the registry could then make the function run another image.
+
 ` + channelLongDesc + `
 
 ` + envFromLongDesc + `
 `,
 		Example: `  riff function create node square --git-repo https://github.com/acme/square --image acme/square --namespace joseph-ns
-  riff function create java tweets-logger --git-repo https://github.com/acme/tweets --image acme/tweets-logger:1.0.0 --input tweets --bus kafka`,
+  riff function create java tweets-logger --git-repo https://github.com/acme/tweets --image acme/tweets-logger:1.0.0 --input tweets --bus kafka
+  riff function create node square --image acme/square:1.0.0 --pin-digest`,
 		Args: ArgValidationConjunction(
 			cobra.ExactArgs(functionCreateNumberOfArgs),
 			AtPosition(functionCreateInvokerIndex, ValidName()),
-			AtPosition(functionCreateFunctionNameIndex, ValidName()),
+			AtPosition(functionCreateFunctionNameIndex, ValidServiceName()),
 		),
 		PreRunE: FlagsValidatorAsCobraRunE(
 			FlagsValidationConjunction(
 				FlagsDependency(Set("input"), exactlyOneOfBusOrClusterBus),
 				FlagsDependency(NotSet("input"), NoneOf("bus", "cluster-bus")),
+				RequiresAllWhenSet("verify-sa", "service-account"),
+				Conflicts("liveness-http", "liveness-tcp"),
+				Conflicts("readiness-http", "readiness-tcp"),
+				RequiresAllWhenSet("arg", "command"),
+				Conflicts("server-dry-run", "dry-run", "wait", "input"),
+				RequiresAllWhenSet("poll-interval", "wait"),
+				RequiresAllWhenSet("build-logs", "wait"),
+				RequiresAllWhenSet("build-timeout", "wait"),
+				FlagsPositiveDuration("poll-interval"),
+				FlagsPositiveDuration("build-timeout"),
+				Conflicts("pin-digest", "git-repo"),
+				RequiresAllWhenSet("require-digest", "pin-digest"),
+				RequiresAllWhenSet("registry-insecure", "pin-digest"),
+				RequiresAllWhenSet("registry-ca-file", "pin-digest"),
 			),
 		),
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -84,10 +192,36 @@ From then on you can use the sub-commands for the 'service' command to interact
 				return fmt.Errorf("unknown invoker: %s", invoker)
 			}
 
+			ctx, cancel, err := ContextWithTimeout(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+
 			createFunctionOptions.Name = fnName
 			createFunctionOptions.InvokerURL = invokerURL
+			createFunctionOptions.PullPolicy = core_v1.PullPolicy(pullPolicy)
+			createFunctionOptions.LivenessProbe = newProbe(livenessHTTP, livenessTCP)
+			createFunctionOptions.ReadinessProbe = newProbe(readinessHTTP, readinessTCP)
+			if createFunctionOptions.PinDigest {
+				createFunctionOptions.Warnings = cmd.OutOrStderr()
+			}
+			if createFunctionOptions.Labels, err = parseKeyValues(labels, "label"); err != nil {
+				return err
+			}
+			if createFunctionOptions.Annotations, err = parseKeyValues(annotations, "annotation"); err != nil {
+				return err
+			}
+			if createFunctionOptions.PodLabels, err = parseKeyValues(podLabels, "pod label"); err != nil {
+				return err
+			}
+			if createFunctionOptions.PodAnnotations, err = parseKeyValues(podAnnotations, "pod annotation"); err != nil {
+				return err
+			}
 			f, err := (*fcTool).CreateFunction(createFunctionOptions)
-			if err != nil {
+			if core.IsAlreadyExists(err) {
+				return &ExitError{Code: ExitCodeAlreadyExists, Message: err.Error() + "; use `riff function update` to change it", Cause: err}
+			} else if err != nil {
 				return err
 			}
 
@@ -107,7 +241,7 @@ From then on you can use the sub-commands for the 'service' command to interact
 				}
 			}
 
-			if createFunctionOptions.DryRun {
+			if createFunctionOptions.DryRun || createFunctionOptions.ServerDryRun {
 				marshaller := NewMarshaller(cmd.OutOrStdout())
 				if err = marshaller.Marshal(f); err != nil {
 					return err
@@ -123,6 +257,26 @@ From then on you can use the sub-commands for the 'service' command to interact
 					}
 				}
 			} else {
+				if wait {
+					waitForFunctionReadyOptions.Name = fnName
+					waitForFunctionReadyOptions.Namespace = createFunctionOptions.Namespace
+					if buildLogs {
+						waitForFunctionReadyOptions.BuildLogs = cmd.OutOrStdout()
+					}
+					waitForFunctionReadyOptions.WaitForBuild = buildLogs || waitForFunctionReadyOptions.BuildTimeout > 0
+					if err = (*fcTool).WaitForFunctionReady(ctx, waitForFunctionReadyOptions); err != nil {
+						return err
+					}
+					functionURL, err := (*fcTool).FunctionURL(core.FunctionURLOptions{
+						Namespaced:   waitForFunctionReadyOptions.Namespaced,
+						Name:         fnName,
+						ClusterLocal: createFunctionOptions.ClusterLocal,
+					})
+					if err != nil {
+						return err
+					}
+					fmt.Fprintf(cmd.OutOrStdout(), "Function %q is available at %s\n", fnName, functionURL)
+				}
 				printSuccessfulCompletion(cmd)
 			}
 
@@ -158,19 +312,888 @@ From then on you can use the sub-commands for the 'service' command to interact
 		"dry-run", "", dryRunUsage,
 	).NoOptDefVal = "true"
 
+	command.Flags().BoolVar(&createFunctionOptions.ServerDryRun, "server-dry-run", false, "submit the function to the cluster for validation and print it as it would be created, without persisting it")
+
 	command.Flags().StringVar(&createChannelOptions.Bus, "bus", "", busUsage)
 	command.Flags().StringVar(&createChannelOptions.ClusterBus, "cluster-bus", "", clusterBusUsage)
 
-	command.Flags().StringVar(&createFunctionOptions.Image, "image", "", "the name of the image to build; must be a writable `repository/image[:tag]` with credentials configured")
+	command.Flags().StringVar(&createFunctionOptions.Image, "image", "", "the name of the image to build, or to run without --git-repo; must be a writable `repository/image[:tag]` with credentials configured when building")
 	command.MarkFlagRequired("image")
-	command.Flags().StringVar(&createFunctionOptions.GitRepo, "git-repo", "", "the `URL` for a git repository hosting the function code")
-	command.MarkFlagRequired("git-repo")
+	command.Flags().StringVar(&createFunctionOptions.GitRepo, "git-repo", "", "the `URL` for a git repository hosting the function code; the image is run as is, without building, if not set")
 	command.Flags().StringVar(&createFunctionOptions.GitRevision, "git-revision", "master", "the git `ref-spec` of the function code to use")
+	command.Flags().BoolVar(&createFunctionOptions.PinDigest, "pin-digest", false, "reference the image by the digest its tag currently refers to in the registry; only for prebuilt images, without --git-repo")
+	command.Flags().BoolVar(&createFunctionOptions.RequireDigest, "require-digest", false, "fail if the digest of the image can't be resolved, rather than keeping its tag; requires --pin-digest")
+	command.Flags().BoolVar(&createFunctionOptions.RegistryInsecure, "registry-insecure", false, "don't verify the certificate of the registry when pinning the digest, and fall back to plain HTTP; requires --pin-digest")
+	command.Flags().StringVar(&createFunctionOptions.RegistryCAFile, "registry-ca-file", "", "the `path` of a PEM bundle of certificate authorities to trust when pinning the digest; requires --pin-digest")
+	command.Flags().StringVar(&createFunctionOptions.BuildTemplate, "build-template", "", "the `name` of the build template to build the function with, given the image as only argument; defaults to riff, building with the invoker")
 	command.Flags().StringVar(&createFunctionOptions.Handler, "handler", "", "the name of the `method or class` to invoke, depending on the invoker used")
 	command.Flags().StringVar(&createFunctionOptions.Artifact, "artifact", "", "`path` to the function source code or jar file; auto-detected if not specified")
 
 	command.Flags().StringArrayVar(&createFunctionOptions.Env, "env", []string{}, envUsage)
 	command.Flags().StringArrayVar(&createFunctionOptions.EnvFrom, "env-from", []string{}, envFromUsage)
+	command.Flags().BoolVar(&createFunctionOptions.AllowReservedEnv, "allow-reserved-env", false, allowReservedEnvUsage)
+	command.Flags().StringVar(&createFunctionOptions.PinnedRevision, "pin-revision", "", pinRevisionUsage)
+	command.Flags().BoolVar(&wait, "wait", false, "wait until the function is ready to serve requests")
+	command.Flags().BoolVar(&buildLogs, "build-logs", false, "when waiting, show the logs of the function build and wait for it to succeed first, requires --wait")
+	command.Flags().DurationVar(&waitForFunctionReadyOptions.BuildTimeout, "build-timeout", 0, "how long to wait for the function build to complete before waiting for the function, requires --wait; defaults to the whole --timeout")
+	command.Flags().DurationVar(&waitForFunctionReadyOptions.PollInterval, "poll-interval", 0, "how often to check the function while waiting, when it can't be watched; defaults to 1s")
+	AddTimeoutFlag(command, functionWaitTimeout)
+	command.Flags().Int64Var(&createFunctionOptions.ContainerConcurrency, "concurrency", 0, "the maximum `number` of concurrent requests a function container handles; 0 for no limit, 1 for non-threadsafe functions")
+	command.Flags().IntVar(&createFunctionOptions.MinScale, "min-scale", 0, "the minimum `number` of pods to keep running, to avoid cold starts")
+	command.Flags().IntVar(&createFunctionOptions.MaxScale, "max-scale", 0, "the maximum `number` of pods the function can scale to; 0 for no limit")
+	command.Flags().Var(OneOfStringValue(&pullPolicy, string(core_v1.PullAlways), string(core_v1.PullIfNotPresent), string(core_v1.PullNever)),
+		"pull-policy", "the image pull `policy` of the function container, one of Always, IfNotPresent or Never")
+	command.Flags().StringVar(&livenessHTTP, "liveness-http", "", "the `path` of an HTTP endpoint to probe for the function container liveness")
+	command.Flags().BoolVar(&livenessTCP, "liveness-tcp", false, "probe the function container liveness by opening a TCP connection")
+	command.Flags().StringVar(&readinessHTTP, "readiness-http", "", "the `path` of an HTTP endpoint to probe before sending traffic to the function container")
+	command.Flags().BoolVar(&readinessTCP, "readiness-tcp", false, "probe the function container readiness by opening a TCP connection")
+	command.Flags().StringVar(&createFunctionOptions.ServiceAccountName, "service-account", "", "the `name` of the service account the function runs as; defaults to the namespace default service account")
+	command.Flags().BoolVar(&createFunctionOptions.VerifyServiceAccount, "verify-sa", false, "fail if the service account doesn't exist in the namespace")
+	command.Flags().StringVar(&createFunctionOptions.BuildServiceAccount, "build-service-account", "", "the `name` of the service account the function build runs as, with credentials to push the image; defaults to riff-build")
+	command.Flags().BoolVar(&createFunctionOptions.VerifyBuildServiceAccount, "verify-build-sa", false, "fail if the build service account doesn't exist in the namespace, or has no credentials for the registry of the image")
+	command.Flags().BoolVar(&createFunctionOptions.CreateNamespace, "create-namespace", false, "create the namespace of the function if it doesn't exist")
+	command.Flags().StringArrayVar(&createFunctionOptions.Command, "command", []string{}, "an element of the `command` overriding the image entrypoint; repeat for each element")
+	command.Flags().StringArrayVar(&createFunctionOptions.Args, "arg", []string{}, "an `argument` passed to the command; repeat for each argument, requires --command")
+	command.Flags().StringArrayVar(&createFunctionOptions.PullSecrets, "pull-secret", []string{}, "the `name` of a secret to use to pull the function image from a private registry")
+	command.Flags().BoolVar(&createFunctionOptions.VerifySecrets, "verify-secrets", false, "fail if any of the pull secrets doesn't exist in the namespace")
+	command.Flags().BoolVar(&createFunctionOptions.ClusterLocal, "cluster-local", false, "only make the function reachable from within the cluster")
+	command.Flags().StringArrayVar(&labels, "label", []string{}, "a label to set on the function, expressed in a 'key=value' format")
+	command.Flags().StringArrayVar(&annotations, "annotation", []string{}, "an annotation to set on the function, expressed in a 'key=value' format")
+	command.Flags().StringArrayVar(&podLabels, "pod-label", []string{}, "a label to set on the pods running the function, expressed in a 'key=value' format")
+	command.Flags().StringArrayVar(&podAnnotations, "pod-annotation", []string{}, "an annotation to set on the pods running the function, e.g. to configure sidecar injection, expressed in a 'key=value' format")
+
+	return command
+}
+
+// newProbe returns a probe of the function port, over HTTP if a path is given or TCP if asked to, or nil if neither.
+func newProbe(httpPath string, tcp bool) *core_v1.Probe {
+	switch {
+	case httpPath != "":
+		return &core_v1.Probe{Handler: core_v1.Handler{HTTPGet: &core_v1.HTTPGetAction{Path: httpPath}}}
+	case tcp:
+		return &core_v1.Probe{Handler: core_v1.Handler{TCPSocket: &core_v1.TCPSocketAction{}}}
+	default:
+		return nil
+	}
+}
+
+// parseKeyValues turns 'key=value' entries into a map (nil if there are no entries), kind being used in error
+// messages. A key can only be provided once.
+func parseKeyValues(entries []string, kind string) (map[string]string, error) {
+	var result map[string]string
+	for _, entry := range entries {
+		if result == nil {
+			result = map[string]string{}
+		}
+		kv := strings.SplitN(entry, "=", 2)
+		if len(kv) != 2 || kv[0] == "" {
+			return nil, fmt.Errorf("unable to parse '%s', %ss must be provided as 'key=value'", entry, kind)
+		}
+		if _, ok := result[kv[0]]; ok {
+			return nil, fmt.Errorf("%s '%s' is provided more than once", kind, kv[0])
+		}
+		result[kv[0]] = kv[1]
+	}
+	return result, nil
+}
+
+func FunctionDescribe(fcTool *core.Client) *cobra.Command {
+
+	describeFunctionOptions := core.DescribeFunctionOptions{}
+
+	command := &cobra.Command{
+		Use:   "describe",
+		Short: "Show details about a function, its latest revisions and its conditions",
+		Long: `Show details about a function, its latest created and ready revisions, and the URL it is reachable at.
+
+The conditions of the function and of its revisions are listed, along with their last transition time and any
+error message, to help understanding why a function fails to become ready.`,
+		Example: `  riff function describe square --namespace joseph-ns`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionDescribeNumberOfArgs),
+			AtPosition(functionDescribeFunctionNameIndex, ValidName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			describeFunctionOptions.Name = args[functionDescribeFunctionNameIndex]
+			d, err := (*fcTool).DescribeFunction(describeFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			out := cmd.OutOrStdout()
+			fmt.Fprintf(out, "Name:         %s\n", d.Name)
+			fmt.Fprintf(out, "Namespace:    %s\n", d.Namespace)
+			fmt.Fprintf(out, "Image:        %s\n", d.Image)
+			fmt.Fprintf(out, "URL:          %s\n", valueOrNone(d.URL))
+			fmt.Fprintf(out, "Conditions:\n")
+			printConditions(out, "  ", d.Conditions)
+			printRevision(out, "Latest Created Revision:", d.LatestCreatedRevision)
+			printRevision(out, "Latest Ready Revision:", d.LatestReadyRevision)
+
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&describeFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+
+	return command
+}
+
+func printRevision(out io.Writer, title string, r *core.RevisionDescription) {
+	if r == nil {
+		fmt.Fprintf(out, "%s  <none>\n", title)
+		return
+	}
+	fmt.Fprintf(out, "%s\n", title)
+	fmt.Fprintf(out, "  Name:       %s\n", r.Name)
+	fmt.Fprintf(out, "  Image:      %s\n", r.Image)
+	fmt.Fprintf(out, "  Conditions:\n")
+	printConditions(out, "    ", r.Conditions)
+}
+
+func printConditions(out io.Writer, indent string, conditions []core.ConditionDescription) {
+	for _, line := range strings.Split(strings.TrimSuffix(FormatConditions(conditions), "\n"), "\n") {
+		fmt.Fprintf(out, "%s%s\n", indent, line)
+	}
+}
+
+func valueOrNone(v string) string {
+	return valueOr(v, "<none>")
+}
+
+func FunctionUpdate(fcTool *core.Client) *cobra.Command {
+
+	updateFunctionOptions := core.UpdateFunctionOptions{}
+	var addEnv, removeEnv []string
+
+	command := &cobra.Command{
+		Use:   "update",
+		Short: "Update the image and/or environment of an existing function",
+		Long: `Update the image and/or environment of an existing function resource.
+
+Any field of the function that is not explicitly changed is left untouched. When env or env-from flags are
+given, they replace the whole set of environment variables of the function. Use add-env and remove-env to
+change individual environment variables instead.
+
+` + envFromLongDesc + `
+`,
+		Example: `  riff function update square --image acme/square:1.1 --namespace joseph-ns
+  riff function update greeter --env FOO=bar --env MESSAGE=Hello
+  riff function update greeter --add-env MESSAGE=Bonjour --remove-env FOO`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionUpdateNumberOfArgs),
+			AtPosition(functionUpdateFunctionNameIndex, ValidName()),
+		),
+		PreRunE: FlagsValidatorAsCobraRunE(AtLeastOneOf("image", "env", "env-from", "add-env", "remove-env")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			updateFunctionOptions.Name = args[functionUpdateFunctionNameIndex]
+			if len(addEnv) > 0 {
+				envAdd, err := core.ParseEnvVar(addEnv)
+				if err != nil {
+					return err
+				}
+				updateFunctionOptions.EnvAdd = envAdd
+			}
+			if len(removeEnv) > 0 {
+				updateFunctionOptions.EnvRemove = removeEnv
+			}
+			_, err := (*fcTool).UpdateFunction(updateFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&updateFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+	command.Flags().StringVar(&updateFunctionOptions.Image, "image", "", "the new `repository/image[:tag]` of the function")
+	command.Flags().StringArrayVar(&updateFunctionOptions.Env, "env", []string{}, envUsage)
+	command.Flags().StringArrayVar(&updateFunctionOptions.EnvFrom, "env-from", []string{}, envFromUsage)
+	command.Flags().BoolVar(&updateFunctionOptions.AllowReservedEnv, "allow-reserved-env", false, allowReservedEnvUsage)
+	command.Flags().StringArrayVar(&addEnv, "add-env", []string{}, "environment variable to add or change, expressed in a 'key=value' format")
+	command.Flags().StringArrayVar(&removeEnv, "remove-env", []string{}, "the `name` of an environment variable to remove")
+
+	return command
+}
+
+func FunctionScale(fcTool *core.Client) *cobra.Command {
+
+	scaleFunctionOptions := core.ScaleFunctionOptions{}
+
+	command := &cobra.Command{
+		Use:   "scale",
+		Short: "Set the minimum and/or maximum number of pods of a function",
+		Long: `Set bounds on the number of pods running a function, overriding the autoscaler.
+
+Both bounds are set at once: a bound that is not given is removed, leaving it to the autoscaler defaults (scale to
+zero, no maximum). The bounds are part of the function revision template, so scaling a function rolls out a new
+revision of it.
+`,
+		Example: `  riff function scale square --min 2 --max 5
+  riff function scale square --min 1 --namespace joseph-ns`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionScaleNumberOfArgs),
+			AtPosition(functionScaleFunctionNameIndex, ValidName()),
+		),
+		PreRunE: FlagsValidatorAsCobraRunE(AtLeastOneOf("min", "max")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+
+			scaleFunctionOptions.Name = args[functionScaleFunctionNameIndex]
+			_, err := (*fcTool).ScaleFunction(scaleFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "A new revision of function %q is being rolled out with the new scale.\n", scaleFunctionOptions.Name)
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&scaleFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+	command.Flags().IntVar(&scaleFunctionOptions.MinScale, "min", 0, "the minimum `number` of pods to keep running")
+	command.Flags().IntVar(&scaleFunctionOptions.MaxScale, "max", 0, "the maximum `number` of pods the function can scale to")
+
+	return command
+}
+
+func FunctionEdit(fcTool *core.Client) *cobra.Command {
+
+	editFunctionOptions := core.EditFunctionOptions{}
+
+	command := &cobra.Command{
+		Use:   "edit",
+		Short: "Edit a function in an editor and apply the changes",
+		Long: `Open the YAML description of a function, as printed by 'riff function export', in an editor and replace the
+function with the result once the editor exits.
+
+The editor is the one set with the editor flag, or the EDITOR environment variable, defaulting to vi. If the edited
+description is invalid, the editor is opened again with the error at the top of the file. Leaving the file unchanged,
+or emptying it, cancels the edit.
+`,
+		Example: `  riff function edit square --namespace joseph-ns
+  riff function edit square --editor "code --wait"`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionEditNumberOfArgs),
+			AtPosition(functionEditFunctionNameIndex, ValidName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			editFunctionOptions.Name = args[functionEditFunctionNameIndex]
+			_, replaced, err := (*fcTool).EditFunction(editFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			if !replaced {
+				fmt.Fprintln(cmd.OutOrStdout(), "Edit cancelled, no changes made")
+				return nil
+			}
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&editFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+	command.Flags().StringVar(&editFunctionOptions.Editor, "editor", "", "the `command` to edit the function with; defaults to $EDITOR, or vi")
+
+	return command
+}
+
+func FunctionCreateFromFile(fcTool *core.Client) *cobra.Command {
+
+	createFunctionFromFileOptions := core.CreateFunctionFromFileOptions{}
+
+	command := &cobra.Command{
+		Use:   "create-from-file",
+		Short: "Create a function from a YAML or JSON description of its knative service",
+		Long: `Create a function from a YAML or JSON description of the knative Service (service.serving.knative.dev) backing it,
+e.g. as produced by 'riff function export'. Use '-' to read the description from the standard input.
+
+The description may be partial, as long as it has the serving.knative.dev/v1alpha1 apiVersion and the Service kind.
+The --name, --image and --env flags override the corresponding parts of the description.
+`,
+		Example: `  riff function create-from-file square.yaml --namespace joseph-ns
+  riff function export square | riff function create-from-file - --name square-v2 --image acme/square:2.0`,
+		Args: cobra.ExactArgs(functionCreateFromFileNumberOfArgs),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			createFunctionFromFileOptions.Path = args[functionCreateFromFileFileIndex]
+			if createFunctionFromFileOptions.Path == "-" {
+				createFunctionFromFileOptions.Stdin = os.Stdin
+			}
+			f, err := (*fcTool).CreateFunctionFromFile(createFunctionFromFileOptions)
+			if err != nil {
+				return err
+			}
+
+			if createFunctionFromFileOptions.DryRun {
+				return NewMarshaller(cmd.OutOrStdout()).Marshal(f)
+			}
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FILE")
+
+	command.Flags().StringVarP(&createFunctionFromFileOptions.Namespace, "namespace", "n", "", "the `namespace` of the function; defaults to the one of the description, then the kubeconfig one")
+	command.Flags().SetAnnotation("namespace", NamespaceDefaultedByCommandAnnotation, []string{"true"})
+	command.Flags().StringVar(&createFunctionFromFileOptions.Name, "name", "", "the `name` of the function, overriding the one of the description")
+	command.Flags().StringVar(&createFunctionFromFileOptions.Image, "image", "", "the `repository/image[:tag]` the function runs, overriding the one of the description")
+	command.Flags().StringArrayVar(&createFunctionFromFileOptions.Env, "env", []string{}, envUsage)
+	command.Flags().BoolVar(&createFunctionFromFileOptions.DryRun, "dry-run", false, dryRunUsage)
+
+	return command
+}
+
+func FunctionClone(fcTool *core.Client) *cobra.Command {
+
+	cloneFunctionOptions := core.CloneFunctionOptions{}
+
+	command := &cobra.Command{
+		Use:   "clone",
+		Short: "Create a copy of an existing function under a new name",
+		Long: `Create a copy of an existing function under a new name.
+
+As functions can't be renamed in place, renaming a function amounts to cloning it and then deleting the original.
+`,
+		Example: `  riff function clone square square-v2 --namespace joseph-ns`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionCloneNumberOfArgs),
+			AtPosition(functionCloneFunctionNameIndex, ValidName()),
+			AtPosition(functionCloneNewNameIndex, ValidServiceName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cloneFunctionOptions.Name = args[functionCloneFunctionNameIndex]
+			cloneFunctionOptions.NewName = args[functionCloneNewNameIndex]
+			_, err := (*fcTool).CloneFunction(cloneFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME", "NEW_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&cloneFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+
+	return command
+}
+
+func FunctionSubscribe(fcTool *core.Client) *cobra.Command {
+
+	subscribeOptions := core.SubscribeOptions{}
+
+	command := &cobra.Command{
+		Use:   "subscribe",
+		Short: "Subscribe a function to an existing input channel",
+		Long: `Subscribe a function to an existing input channel.
+
+If the subscription already exists, it is updated to wire the given channel to the function, or left untouched when
+it already does. Subscribing can therefore be repeated safely, e.g. when re-running a pipeline.
+`,
+		Example: `  riff function subscribe square --input numbers --namespace joseph-ns`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionSubscribeNumberOfArgs),
+			AtPosition(functionSubscribeFunctionNameIndex, ValidName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			subscribeOptions.Function = args[functionSubscribeFunctionNameIndex]
+			s, change, err := (*fcTool).Subscribe(subscribeOptions)
+			if err != nil {
+				return err
+			}
+			if subscribeOptions.DryRun {
+				return NewMarshaller(cmd.OutOrStdout()).Marshal(s)
+			}
+			fmt.Fprintf(cmd.OutOrStdout(), "Subscription %q %s\n", s.Name, change)
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVar(&subscribeOptions.Name, "subscription", "", "`name` of the subscription (default FUNCTION_NAME)")
+	command.Flags().StringVarP(&subscribeOptions.Channel, "input", "i", "", "the name of an input `channel` for the function")
+	command.MarkFlagRequired("input")
+	command.Flags().StringVarP(&subscribeOptions.Namespace, "namespace", "n", "", "the `namespace` of the subscription, channel, and function")
+	command.Flags().BoolVar(&subscribeOptions.DryRun, "dry-run", false, dryRunUsage)
+
+	return command
+}
+
+func FunctionDelete(fcTool *core.Client) *cobra.Command {
+
+	deleteFunctionsOptions := core.DeleteFunctionsOptions{}
+
+	command := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete existing functions",
+		Long: `Delete one or more existing functions.
+
+All the functions are attempted, even if some of them can't be deleted (e.g. because they don't exist), and all the
+failures are reported at once.
+`,
+		Example: `  riff function delete square --namespace joseph-ns
+  riff function delete square cube --wait --yes`,
+		Args:    Args(1, -1, ValidName()),
+		PreRunE: FlagsValidatorAsCobraRunE(RequiredUnlessTTY("yes")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			deleteFunctionsOptions.Names = args
+			what := "function"
+			if len(args) > 1 {
+				what = "functions"
+			}
+			prompt := fmt.Sprintf("Delete %s %s%s?", what, strings.Join(args, ", "), inNamespace(deleteFunctionsOptions.Namespace))
+			if ok, err := Confirmed(cmd, prompt); err != nil {
+				return err
+			} else if !ok {
+				printAbortedCompletion(cmd)
+				return nil
+			}
+			ctx, cancel, err := ContextWithTimeout(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+			err = (*fcTool).DeleteFunctions(ctx, deleteFunctionsOptions)
+			if err != nil {
+				return err
+			}
+
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME...")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&deleteFunctionsOptions.Namespace, "namespace", "n", "", "the `namespace` of the functions")
+	command.Flags().BoolVar(&deleteFunctionsOptions.Wait, "wait", false, "wait until the functions and their underlying resources are actually removed")
+	AddTimeoutFlag(command, functionWaitTimeout)
+	AddYesFlag(command)
+
+	return command
+}
+
+func FunctionList(fcTool *core.Client) *cobra.Command {
+
+	listFunctionOptions := core.ListFunctionOptions{}
+	output := string(OutputFormatTable)
+	watchFunctions := false
+
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List function resources",
+		Example: `  riff function list
+  riff function list --namespace joseph-ns
+  riff function list --all-namespaces --selector team=payments
+  riff function list --watch`,
+		Args: cobra.ExactArgs(functionListNumberOfArgs),
+		PreRunE: FlagsValidatorAsCobraRunE(FlagsValidationConjunction(
+			AtMostOneOf("namespace", "all-namespaces"),
+			func(cmd *cobra.Command) error {
+				if watchFunctions && OutputFormat(output) != OutputFormatTable {
+					return fmt.Errorf("--watch can only be used with the %s output, not %s", OutputFormatTable, output)
+				}
+				return nil
+			},
+		)),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			functions, err := (*fcTool).ListFunctions(listFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			if OutputFormat(output) != OutputFormatTable {
+				return Render(cmd.OutOrStdout(), functions, OutputFormat(output))
+			}
+
+			if len(functions.Items) == 0 && !watchFunctions {
+				fmt.Fprintln(cmd.OutOrStdout(), "No resources found.")
+				return nil
+			}
+
+			maxNameLength := len("NAME ") // Make sure column names have enough room, even with short names
+			maxNamespaceLength := len("NAMESPACE ")
+			for _, function := range functions.Items {
+				if len(function.Name) > maxNameLength {
+					maxNameLength = len(function.Name)
+				}
+				if len(function.Namespace) > maxNamespaceLength {
+					maxNamespaceLength = len(function.Namespace)
+				}
+			}
+			var printRow func(namespace, name, status string)
+			if listFunctionOptions.AllNamespaces {
+				pad := fmt.Sprintf("%%-%ds%%-%ds%%s\n", maxNamespaceLength+1, maxNameLength+1)
+				printRow = func(namespace, name, status string) {
+					fmt.Fprintf(cmd.OutOrStdout(), pad, namespace, name, status)
+				}
+			} else {
+				pad := fmt.Sprintf("%%-%ds%%s\n", maxNameLength+1)
+				printRow = func(_, name, status string) {
+					fmt.Fprintf(cmd.OutOrStdout(), pad, name, status)
+				}
+			}
+
+			printRow("NAMESPACE", "NAME", "STATUS")
+			for _, function := range functions.Items {
+				printRow(function.Namespace, function.Name, serviceStatus(function))
+			}
+
+			if !watchFunctions {
+				return nil
+			}
+
+			w, err := (*fcTool).WatchFunctions(core.WatchFunctionsOptions{
+				Namespaced:      listFunctionOptions.Namespaced,
+				AllNamespaces:   listFunctionOptions.AllNamespaces,
+				LabelSelector:   listFunctionOptions.LabelSelector,
+				FieldSelector:   listFunctionOptions.FieldSelector,
+				ResourceVersion: functions.ResourceVersion,
+			})
+			if err != nil {
+				return err
+			}
+			defer w.Stop()
+			for event := range w.ResultChan() {
+				switch event.Type {
+				case watch.Error:
+					return errors.FromObject(event.Object)
+				case watch.Deleted:
+					if function, ok := event.Object.(*v1alpha12.Service); ok {
+						printRow(function.Namespace, function.Name, "Deleted")
+					}
+				default:
+					if function, ok := event.Object.(*v1alpha12.Service); ok {
+						printRow(function.Namespace, function.Name, serviceStatus(*function))
+					}
+				}
+			}
+			return nil
+		},
+	}
+
+	command.Flags().StringVarP(&listFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the functions to be listed")
+	command.Flags().BoolVar(&listFunctionOptions.AllNamespaces, "all-namespaces", false, "list functions across all namespaces")
+	command.Flags().StringVarP(&listFunctionOptions.LabelSelector, "selector", "l", "", "only list functions matching the given label `selector`")
+	command.Flags().StringVar(&listFunctionOptions.FieldSelector, "field-selector", "", "only list functions whose fields match the given `selector`, e.g. metadata.name=square")
+	command.Flags().BoolVarP(&watchFunctions, "watch", "w", false, "after listing functions, watch for changes and print them as they happen")
+	command.Flags().VarP(OneOfStringValue(&output, outputFormats...), "output", "o", outputUsage)
+
+	return command
+}
+
+func FunctionGet(fcTool *core.Client) *cobra.Command {
+
+	getFunctionOptions := core.GetFunctionOptions{}
+	output := string(OutputFormatTable)
+
+	command := &cobra.Command{
+		Use:     "get",
+		Short:   "Display the image, status and latest revision of a function",
+		Example: `  riff function get square --namespace joseph-ns`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionGetNumberOfArgs),
+			AtPosition(functionGetFunctionNameIndex, ValidName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			getFunctionOptions.Name = args[functionGetFunctionNameIndex]
+			f, err := (*fcTool).GetFunction(getFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			if OutputFormat(output) != OutputFormatTable {
+				return Render(cmd.OutOrStdout(), f, OutputFormat(output))
+			}
+			configuration, err := core.ServiceConfiguration(f)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Name:                        %s\n", f.Name)
+			fmt.Fprintf(cmd.OutOrStdout(), "Image:                       %s\n", configuration.RevisionTemplate.Spec.Container.Image)
+			fmt.Fprintf(cmd.OutOrStdout(), "Latest Created Revision:     %s\n", f.Status.LatestCreatedRevisionName)
+			fmt.Fprintf(cmd.OutOrStdout(), "Latest Ready Revision:       %s\n", f.Status.LatestReadyRevisionName)
+			fmt.Fprintf(cmd.OutOrStdout(), "Status:                      %s\n", serviceStatus(*f))
+
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&getFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+	command.Flags().VarP(OneOfStringValue(&output, outputFormats...), "output", "o", outputUsage)
+
+	return command
+}
+
+func FunctionExport(fcTool *core.Client) *cobra.Command {
+
+	exportFunctionOptions := core.ExportFunctionOptions{}
+	all := false
+
+	command := &cobra.Command{
+		Use:   "export",
+		Short: "Print a clean YAML description of a function, suitable for source control",
+		Long: `Print the YAML description of the knative Service (service.serving.knative.dev) backing a function, without the
+fields managed by the cluster (status, uid, resource version, creation timestamp, etc).
+
+The description can be kept in source control, and used to create the function again with 'riff function create-from-file'.
+
+With --all, all the functions of the namespace are exported, sorted by name, as a single YAML stream of documents
+separated by '---'. This backs up a namespace, which can be restored with 'kubectl apply'.
+`,
+		Example: `  riff function export square --namespace joseph-ns > square.yaml
+  riff function export --all --namespace joseph-ns > joseph-ns.yaml`,
+		Args: ArgValidationConjunction(
+			cobra.MaximumNArgs(functionExportNumberOfArgs),
+			AllPositions(ValidName()),
+		),
+		PreRunE: func(cmd *cobra.Command, args []string) error {
+			if all && len(args) > 0 {
+				return fmt.Errorf("a function name can't be given along with --all")
+			}
+			if !all && len(args) == 0 {
+				return fmt.Errorf("a function name, or --all, is required")
+			}
+			return nil
+		},
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var manifest []byte
+			var err error
+			if all {
+				manifest, err = (*fcTool).ExportFunctions(core.ExportFunctionsOptions{Namespaced: exportFunctionOptions.Namespaced})
+			} else {
+				exportFunctionOptions.Name = args[functionExportFunctionNameIndex]
+				manifest, err = (*fcTool).ExportFunction(exportFunctionOptions)
+			}
+			if err != nil {
+				return err
+			}
+
+			_, err = cmd.OutOrStdout().Write(manifest)
+			return err
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&exportFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+	command.Flags().BoolVar(&all, "all", false, "export all the functions of the namespace")
+
+	return command
+}
+
+func FunctionInvoke(fcTool *core.Client) *cobra.Command {
+
+	invokeFunctionOptions := core.InvokeFunctionOptions{}
+	data := ""
+	fail := false
+
+	command := &cobra.Command{
+		Use:   "invoke",
+		Short: "Invoke a function over http",
+		Long: `Invoke a function by sending an http request through the ingress gateway, and print the response body.
+
+Unless the fail flag is set, the command succeeds whatever the http status of the response.`,
+		Example: `  riff function invoke square --data 8 --namespace joseph-ns
+  riff function invoke greeter --method GET --path /hello --header "Accept: text/plain" --fail`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionInvokeNumberOfArgs),
+			AtPosition(functionInvokeFunctionNameIndex, ValidName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			invokeFunctionOptions.Name = args[functionInvokeFunctionNameIndex]
+			invokeFunctionOptions.Body = []byte(data)
+			ctx, cancel, err := ContextWithTimeout(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+			status, body, err := (*fcTool).InvokeFunction(ctx, invokeFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			if _, err := cmd.OutOrStdout().Write(body); err != nil {
+				return err
+			}
+			if fail && (status < 200 || status > 299) {
+				return fmt.Errorf("function responded with http status %d", status)
+			}
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&invokeFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+	command.Flags().StringVar(&invokeFunctionOptions.Method, "method", "POST", "the http `method` of the request")
+	command.Flags().StringVar(&invokeFunctionOptions.Path, "path", "/", "the `path` of the request")
+	command.Flags().StringVarP(&data, "data", "d", "", "the `body` of the request")
+	command.Flags().StringArrayVarP(&invokeFunctionOptions.Headers, "header", "H", []string{}, "a request header expressed in a 'name: value' format")
+	AddTimeoutFlag(command, functionInvokeTimeout)
+	command.Flags().BoolVar(&fail, "fail", false, "fail if the http status of the response is not 2xx")
+
+	return command
+}
+
+func FunctionLogs(fcTool *core.Client) *cobra.Command {
+
+	functionLogsOptions := core.FunctionLogsOptions{}
+
+	command := &cobra.Command{
+		Use:   "logs",
+		Short: "Display the logs of a function",
+		Long: `Display the logs of the pods running the latest revision of a function, or the revision given with the
+revision flag.
+
+Each line is prefixed with the name of the pod it comes from. When following logs, pods that are started
+afterwards, e.g. when the function scales up, are picked up as well. A revision given explicitly must have pods
+running though, which is not the case when it has been scaled to zero.`,
+		Example: `  riff function logs square --namespace joseph-ns
+  riff function logs square -f --tail 10
+  riff function logs square --revision square-00002`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionLogsNumberOfArgs),
+			AtPosition(functionLogsFunctionNameIndex, ValidName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			functionLogsOptions.Name = args[functionLogsFunctionNameIndex]
+			logs, err := (*fcTool).FunctionLogs(functionLogsOptions)
+			if err != nil {
+				return err
+			}
+			defer logs.Close()
+
+			_, err = io.Copy(cmd.OutOrStdout(), logs)
+			return err
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&functionLogsOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+	command.Flags().BoolVarP(&functionLogsOptions.Follow, "follow", "f", false, "keep streaming the logs as they are produced")
+	command.Flags().Int64Var(&functionLogsOptions.TailLines, "tail", -1, "the `number` of most recent lines to display for each pod, or all lines if negative")
+	command.Flags().StringVar(&functionLogsOptions.Revision, "revision", "", "the `name` of the revision to display the logs of (default the latest revision)")
+
+	return command
+}
+
+func FunctionBuild(fcTool *core.Client) *cobra.Command {
+
+	buildFunctionOptions := core.BuildFunctionOptions{}
+	waitForBuildOptions := core.WaitForBuildOptions{}
+	var buildArgs, buildEnv []string
+	wait, buildLogs := false, false
+
+	command := &cobra.Command{
+		Use:   "build",
+		Short: "Build a function image from source, without deploying it",
+		Long: `Build a function image from the content of the provided Git repo/revision, using a build template.
+
+The build template must be installed in the namespace of the build. The name of the created Build
+(build.build.knative.dev) is printed, so that its progress can be followed with kubectl. With --wait, the command
+waits for the build to succeed too, showing the logs of its steps with --build-logs. Builds may hang: the build
+is given up on after --build-timeout, if set, or --timeout.`,
+		Example: `  riff function build square --git-repo https://github.com/acme/square --image acme/square --build-template riff --build-arg INVOKER_PATH=https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml
+  riff function build greeter --git-repo https://github.com/acme/greeter --image acme/greeter --build-template kaniko --build-env GOFLAGS=-mod=vendor
+  riff function build square --git-repo https://github.com/acme/square --image acme/square --build-template kaniko --wait --build-logs --build-timeout 15m`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(functionBuildNumberOfArgs),
+			AtPosition(functionBuildFunctionNameIndex, ValidName()),
+		),
+		PreRunE: FlagsValidatorAsCobraRunE(
+			FlagsValidationConjunction(
+				RequiresAllWhenSet("build-logs", "wait"),
+				RequiresAllWhenSet("build-timeout", "wait"),
+				FlagsPositiveDuration("build-timeout"),
+			),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			buildFunctionOptions.Name = args[functionBuildFunctionNameIndex]
+			var err error
+			if buildFunctionOptions.BuildArgs, err = parseKeyValues(buildArgs, "build argument"); err != nil {
+				return err
+			}
+			if buildFunctionOptions.BuildEnv, err = core.ParseEnvVar(buildEnv); err != nil {
+				return err
+			}
+			b, err := (*fcTool).BuildFunction(buildFunctionOptions)
+			if err != nil {
+				return err
+			}
+
+			fmt.Fprintf(cmd.OutOrStdout(), "Created build %s\n", b.Name)
+			if wait {
+				ctx, cancel, err := ContextWithTimeout(cmd)
+				if err != nil {
+					return err
+				}
+				defer cancel()
+
+				waitForBuildOptions.Namespaced = buildFunctionOptions.Namespaced
+				waitForBuildOptions.Name = b.Name
+				waitForBuildOptions.Function = buildFunctionOptions.Name
+				if buildLogs {
+					waitForBuildOptions.Logs = cmd.OutOrStdout()
+				}
+				if err := (*fcTool).WaitForBuild(ctx, waitForBuildOptions); err != nil {
+					return err
+				}
+			}
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+
+	command.Flags().StringVarP(&buildFunctionOptions.Namespace, "namespace", "n", "", "the `namespace` of the build")
+	command.Flags().StringVar(&buildFunctionOptions.Image, "image", "", "the name of the image to build; must be a writable `repository/image[:tag]` with credentials configured")
+	command.MarkFlagRequired("image")
+	command.Flags().StringVar(&buildFunctionOptions.GitRepo, "git-repo", "", "the `URL` for a git repository hosting the function code")
+	command.MarkFlagRequired("git-repo")
+	command.Flags().StringVar(&buildFunctionOptions.GitRevision, "git-revision", "master", "the git `ref-spec` of the function code to use")
+	command.Flags().StringVar(&buildFunctionOptions.BuildTemplate, "build-template", "riff", "the `name` of the build template to use")
+	command.Flags().StringArrayVar(&buildArgs, "build-arg", []string{}, "an argument of the build template expressed in a 'NAME=value' format")
+	command.Flags().StringArrayVar(&buildEnv, "build-env", []string{}, "an environment variable set in all the build steps, expressed in a 'NAME=value' format")
+	command.Flags().StringVar(&buildFunctionOptions.BuildServiceAccount, "build-service-account", "", "the `name` of the service account the build runs as, with credentials to push the image; defaults to riff-build")
+	command.Flags().BoolVar(&buildFunctionOptions.VerifyBuildServiceAccount, "verify", false, "fail if the build service account doesn't exist in the namespace, or has no credentials for the registry of the image")
+	command.Flags().BoolVar(&wait, "wait", false, "wait until the build succeeds")
+	command.Flags().BoolVar(&buildLogs, "build-logs", false, "when waiting, show the logs of the build steps as they run, requires --wait")
+	command.Flags().DurationVar(&waitForBuildOptions.Timeout, "build-timeout", 0, "how long to wait for the build to complete, requires --wait; defaults to the whole --timeout")
+	AddTimeoutFlag(command, functionWaitTimeout)
 
 	return command
 }
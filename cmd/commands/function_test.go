@@ -18,10 +18,19 @@
 package commands_test
 
 import (
+	"context"
 	"fmt"
+	"io"
+
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
 
 	"strings"
 
+	"time"
+
+	build "github.com/knative/build/pkg/apis/build/v1alpha1"
 	v1alpha12 "github.com/knative/eventing/pkg/apis/channels/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	. "github.com/onsi/ginkgo"
@@ -31,6 +40,9 @@ import (
 	"github.com/projectriff/riff/pkg/core/mocks"
 	"github.com/spf13/cobra"
 	"github.com/stretchr/testify/mock"
+	"k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
 )
 
 var _ = Describe("The riff function command", func() {
@@ -61,9 +73,22 @@ var _ = Describe("The riff function command", func() {
 		It("should fail without required flags", func() {
 			fc.SetArgs([]string{"node", "square"})
 			err := fc.Execute()
-			Expect(err).To(MatchError(ContainSubstring("required flag(s)")))
-			Expect(err).To(MatchError(ContainSubstring("git-repo")))
-			Expect(err).To(MatchError(ContainSubstring("image")))
+			Expect(err).To(MatchError(`required flag(s) "image" not set`))
+		})
+		It("should fail when pinning the digest of an image to build", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--pin-digest"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("--pin-digest and --git-repo cannot be set together"))
+		})
+		It("should fail when requiring a digest without pinning it", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--require-digest"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("when --require-digest is set, --pin-digest must be set"))
+		})
+		It("should fail when trusting registries without pinning digests", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--registry-insecure"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("when --registry-insecure is set, --pin-digest must be set"))
 		})
 		It("should fail when input is set w/o bus or cluster-bus", func() {
 			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
@@ -101,11 +126,53 @@ var _ = Describe("The riff function command", func() {
 			o.Image = "foo/bar"
 			o.Env = []string{}
 			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
 
 			asMock.On("CreateFunction", o).Return(nil, nil)
 			err := fc.Execute()
 			Expect(err).NotTo(HaveOccurred())
 		})
+		It("should create functions from prebuilt images", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar:1.0"})
+
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.Image == "foo/bar:1.0" && o.GitRepo == "" && !o.PinDigest && o.Warnings == nil
+			})).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should pin the digest of prebuilt images, warning when it can't be resolved", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar:1.0", "--pin-digest"})
+			warnings := &strings.Builder{}
+			fc.SetOutput(warnings)
+
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.PinDigest && !o.RequireDigest && o.Warnings == io.Writer(warnings)
+			})).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should require the digest of prebuilt images when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar:1.0", "--pin-digest", "--require-digest"})
+
+			e := fmt.Errorf("unable to resolve the digest of image 'foo/bar:1.0'")
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.PinDigest && o.RequireDigest
+			})).Return(nil, e)
+			err := fc.Execute()
+			Expect(err).To(MatchError(e))
+		})
+		It("should pass the registry options along when pinning digests", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar:1.0", "--pin-digest", "--registry-insecure", "--registry-ca-file", "/etc/ca.pem"})
+
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.PinDigest && o.RegistryInsecure && o.RegistryCAFile == "/etc/ca.pem"
+			})).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
 		It("should propagate core.Client errors", func() {
 			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo"})
 
@@ -114,6 +181,16 @@ var _ = Describe("The riff function command", func() {
 			err := fc.Execute()
 			Expect(err).To(MatchError(e))
 		})
+		It("should suggest updating a function that already exists", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo"})
+
+			e := &core.FunctionAlreadyExistsError{Name: "square", Namespace: "default"}
+			asMock.On("CreateFunction", mock.Anything).Return(nil, e)
+			err := fc.Execute()
+			Expect(err).To(MatchError("function \"square\" already exists in namespace \"default\"; use `riff function update` to change it"))
+			Expect(commands.ExitCode(commands.HandleError(err))).To(Equal(commands.ExitCodeAlreadyExists))
+			Expect(err.(*commands.ExitError).Cause).To(BeIdenticalTo(e))
+		})
 		It("should add env vars when asked to", func() {
 			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
 				"--env", "FOO=bar", "--env", "BAZ=qux", "--env-from", "secretKeyRef:foo:bar"})
@@ -127,11 +204,391 @@ var _ = Describe("The riff function command", func() {
 			o.Image = "foo/bar"
 			o.Env = []string{"FOO=bar", "BAZ=qux"}
 			o.EnvFrom = []string{"secretKeyRef:foo:bar"}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should add labels and annotations when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--label", "team=math", "--label", "tier=", "--annotation", "example.com/owner=jane"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:     "https://github.com/repo",
+				GitRevision: "master",
+				InvokerURL:  "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				Labels:      map[string]string{"team": "math", "tier": ""},
+				Annotations: map[string]string{"example.com/owner": "jane"},
+			}
+			o.Name = "square"
+			o.Image = "foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should add pod labels and annotations when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--pod-label", "tier=backend", "--pod-annotation", "sidecar.istio.io/inject=true"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:        "https://github.com/repo",
+				GitRevision:    "master",
+				InvokerURL:     "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				PodLabels:      map[string]string{"tier": "backend"},
+				PodAnnotations: map[string]string{"sidecar.istio.io/inject": "true"},
+			}
+			o.Name = "square"
+			o.Image = "foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should pass pull secrets when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "registry.example.com/foo/bar", "--git-repo", "https://github.com/repo",
+				"--pull-secret", "registry-creds", "--pull-secret", "other-creds", "--verify-secrets"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:       "https://github.com/repo",
+				GitRevision:   "master",
+				InvokerURL:    "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				PullSecrets:   []string{"registry-creds", "other-creds"},
+				VerifySecrets: true,
+			}
+			o.Name = "square"
+			o.Image = "registry.example.com/foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should run the function as the given service account", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--service-account", "square-sa", "--verify-sa"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:              "https://github.com/repo",
+				GitRevision:          "master",
+				InvokerURL:           "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				ServiceAccountName:   "square-sa",
+				VerifyServiceAccount: true,
+			}
+			o.Name = "square"
+			o.Image = "foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should create cluster-local functions when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--cluster-local"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:      "https://github.com/repo",
+				GitRevision:  "master",
+				InvokerURL:   "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				ClusterLocal: true,
+			}
+			o.Name = "square"
+			o.Image = "foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should build with the given build template", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--build-template", "kaniko"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:       "https://github.com/repo",
+				GitRevision:   "master",
+				BuildTemplate: "kaniko",
+				InvokerURL:    "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+			}
+			o.Name = "square"
+			o.Image = "foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should create the namespace when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--namespace", "numbers", "--create-namespace"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:         "https://github.com/repo",
+				GitRevision:     "master",
+				InvokerURL:      "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				CreateNamespace: true,
+			}
+			o.Namespace = "numbers"
+			o.Name = "square"
+			o.Image = "foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
+			o.Command = []string{}
+			o.Args = []string{}
+
+			asMock.On("CreateFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should override the entrypoint when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--command", "/bin/square", "--command", "--verbose", "--arg", "--port=8080"})
+
+			o := core.CreateFunctionOptions{
+				GitRepo:     "https://github.com/repo",
+				GitRevision: "master",
+				InvokerURL:  "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				Command:     []string{"/bin/square", "--verbose"},
+				Args:        []string{"--port=8080"},
+			}
+			o.Name = "square"
+			o.Image = "foo/bar"
+			o.Env = []string{}
+			o.EnvFrom = []string{}
+			o.PullSecrets = []string{}
 
 			asMock.On("CreateFunction", o).Return(nil, nil)
 			err := fc.Execute()
 			Expect(err).NotTo(HaveOccurred())
 		})
+		It("should fail to pass arguments without a command", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--arg", "--port=8080"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("when --arg is set, --command must be set"))
+		})
+		It("should fail to verify the service account when none is given", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--verify-sa"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("when --verify-sa is set, --service-account must be set"))
+		})
+		It("should set the container concurrency when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--concurrency", "1"})
+
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.ContainerConcurrency == 1
+			})).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should set the scale bounds when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--min-scale", "1", "--max-scale", "5"})
+
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.MinScale == 1 && o.MaxScale == 5
+			})).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should set probes when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--readiness-http", "/healthz", "--liveness-tcp"})
+
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.ReadinessProbe.HTTPGet.Path == "/healthz" && o.ReadinessProbe.TCPSocket == nil &&
+					o.LivenessProbe.TCPSocket != nil && o.LivenessProbe.HTTPGet == nil
+			})).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should fail when both an HTTP and a TCP probe are asked for", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--readiness-http", "/healthz", "--readiness-tcp"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("--readiness-http and --readiness-tcp cannot be set together"))
+		})
+		It("should set the image pull policy when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--pull-policy", "Always"})
+
+			asMock.On("CreateFunction", mock.MatchedBy(func(o core.CreateFunctionOptions) bool {
+				return o.PullPolicy == v1.PullAlways
+			})).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should fail on unknown image pull policies", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--pull-policy", "Sometimes"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError(`invalid argument "Sometimes" for "--pull-policy" flag: must be one of Always, IfNotPresent, Never`))
+		})
+		It("should fail on malformed labels", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--label", "team"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError("unable to parse 'team', labels must be provided as 'key=value'"))
+		})
+		It("should wait for the function to be ready when asked to", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--namespace", "ns", "--wait"})
+
+			waitOptions := core.WaitForFunctionReadyOptions{
+				Name: "square",
+			}
+			waitOptions.Namespace = "ns"
+
+			urlOptions := core.FunctionURLOptions{Name: "square"}
+			urlOptions.Namespace = "ns"
+
+			stdout := &strings.Builder{}
+			fc.SetOutput(stdout)
+			asMock.On("CreateFunction", mock.Anything).Return(nil, nil)
+			asMock.On("WaitForFunctionReady", mock.MatchedBy(func(ctx context.Context) bool {
+				deadline, ok := ctx.Deadline()
+				return ok && time.Until(deadline) > 9*time.Minute
+			}), waitOptions).Return(nil)
+			asMock.On("FunctionURL", urlOptions).Return("http://square.ns.example.com", nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(HavePrefix(`Function "square" is available at http://square.ns.example.com` + "\n"))
+		})
+		It("should wait with the given poll interval", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--poll-interval", "10s"})
+
+			waitOptions := core.WaitForFunctionReadyOptions{Name: "square", PollInterval: 10 * time.Second}
+
+			asMock.On("CreateFunction", mock.Anything).Return(nil, nil)
+			asMock.On("WaitForFunctionReady", mock.Anything, waitOptions).Return(nil)
+			asMock.On("FunctionURL", mock.Anything).Return("http://square.default.example.com", nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should fail with a non positive poll interval", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--poll-interval", "0s"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError(`invalid value "0s" for --poll-interval: must be positive`))
+		})
+		It("should fail with a poll interval but no wait", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--poll-interval", "10s"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError("when --poll-interval is set, --wait must be set"))
+		})
+		It("should wait for the build first when asked to show its logs", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--build-logs"})
+
+			stdout := &strings.Builder{}
+			fc.SetOutput(stdout)
+			waitOptions := core.WaitForFunctionReadyOptions{Name: "square", WaitForBuild: true, BuildLogs: stdout}
+
+			asMock.On("CreateFunction", mock.Anything).Return(nil, nil)
+			asMock.On("WaitForFunctionReady", mock.Anything, waitOptions).Return(nil)
+			asMock.On("FunctionURL", mock.Anything).Return("http://square.default.example.com", nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should wait for the build with the given build timeout", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--build-logs", "--build-timeout", "5m"})
+
+			stdout := &strings.Builder{}
+			fc.SetOutput(stdout)
+			waitOptions := core.WaitForFunctionReadyOptions{Name: "square", WaitForBuild: true, BuildLogs: stdout, BuildTimeout: 5 * time.Minute}
+
+			asMock.On("CreateFunction", mock.Anything).Return(nil, nil)
+			asMock.On("WaitForFunctionReady", mock.Anything, waitOptions).Return(nil)
+			asMock.On("FunctionURL", mock.Anything).Return("http://square.default.example.com", nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should wait for the build with a build timeout but no build logs", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--build-timeout", "5m"})
+
+			waitOptions := core.WaitForFunctionReadyOptions{Name: "square", WaitForBuild: true, BuildTimeout: 5 * time.Minute}
+
+			asMock.On("CreateFunction", mock.Anything).Return(nil, nil)
+			asMock.On("WaitForFunctionReady", mock.Anything, waitOptions).Return(nil)
+			asMock.On("FunctionURL", mock.Anything).Return("http://square.default.example.com", nil)
+			fc.SetOutput(&strings.Builder{})
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should fail with a build timeout but no wait", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--build-timeout", "5m"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError("when --build-timeout is set, --wait must be set"))
+		})
+		It("should fail with a non positive build timeout", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--build-logs", "--build-timeout", "0s"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError(`invalid value "0s" for --build-timeout: must be positive`))
+		})
+		It("should fail with build logs but no wait", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--build-logs"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError("when --build-logs is set, --wait must be set"))
+		})
+		It("should fail with a non positive timeout", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--timeout", "-1s"})
+
+			err := fc.Execute()
+			Expect(err).To(MatchError("--timeout must be positive, got -1s"))
+		})
+		It("should propagate errors while waiting for the function to be ready", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait"})
+
+			e := fmt.Errorf("not ready")
+			asMock.On("CreateFunction", mock.Anything).Return(nil, nil)
+			asMock.On("WaitForFunctionReady", mock.Anything, mock.Anything).Return(e)
+			err := fc.Execute()
+			Expect(err).To(MatchError(e))
+		})
 		It("should create channel/subscription when asked to", func() {
 			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
 				"--input", "my-channel", "--bus", "kafka"})
@@ -145,6 +602,9 @@ var _ = Describe("The riff function command", func() {
 			functionOptions.Image = "foo/bar"
 			functionOptions.Env = []string{}
 			functionOptions.EnvFrom = []string{}
+			functionOptions.PullSecrets = []string{}
+			functionOptions.Command = []string{}
+			functionOptions.Args = []string{}
 
 			channelOptions := core.CreateChannelOptions{
 				Name: "my-channel",
@@ -175,6 +635,9 @@ var _ = Describe("The riff function command", func() {
 			functionOptions.Image = "foo/bar"
 			functionOptions.Env = []string{}
 			functionOptions.EnvFrom = []string{}
+			functionOptions.PullSecrets = []string{}
+			functionOptions.Command = []string{}
+			functionOptions.Args = []string{}
 			functionOptions.DryRun = true
 
 			channelOptions := core.CreateChannelOptions{
@@ -207,18 +670,57 @@ var _ = Describe("The riff function command", func() {
 
 			Expect(stdout.String()).To(Equal(fnCreateDryRun))
 		})
+		It("should print the function as created by the server when --server-dry-run is set", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--server-dry-run"})
 
-	})
-})
+			functionOptions := core.CreateFunctionOptions{
+				GitRepo:      "https://github.com/repo",
+				GitRevision:  "master",
+				InvokerURL:   "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+				ServerDryRun: true,
+			}
+			functionOptions.Name = "square"
+			functionOptions.Image = "foo/bar"
+			functionOptions.Env = []string{}
+			functionOptions.EnvFrom = []string{}
+			functionOptions.PullSecrets = []string{}
+			functionOptions.Command = []string{}
+			functionOptions.Args = []string{}
 
-const fnCreateDryRun = `metadata:
-  creationTimestamp: null
-  name: square
-spec: {}
-status: {}
----
-metadata:
-  creationTimestamp: null
+			f := v1alpha1.Service{}
+			f.Name = "square"
+			asMock.On("CreateFunction", functionOptions).Return(&f, nil)
+
+			stdout := &strings.Builder{}
+			fc.SetOutput(stdout)
+
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(`metadata:
+  creationTimestamp: null
+  name: square
+spec: {}
+status: {}
+---
+`))
+		})
+		It("should fail when both dry run modes are set", func() {
+			fc.SetArgs([]string{"node", "square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--server-dry-run", "--dry-run"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("--server-dry-run and --dry-run cannot be set together"))
+		})
+
+	})
+})
+
+const fnCreateDryRun = `metadata:
+  creationTimestamp: null
+  name: square
+spec: {}
+status: {}
+---
+metadata:
+  creationTimestamp: null
   name: my-channel
 spec: {}
 status: {}
@@ -232,3 +734,1288 @@ spec:
 status: {}
 ---
 `
+
+var _ = Describe("The riff function update command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fu         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fu = commands.FunctionUpdate(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fu.SetArgs([]string{})
+			err := fu.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail with invalid function name", func() {
+			fu.SetArgs([]string{".invalid"})
+			err := fu.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+		It("should fail when nothing to update is given", func() {
+			fu.SetArgs([]string{"square"})
+			err := fu.Execute()
+			Expect(err).To(MatchError("at least one of --image, --env, --env-from, --add-env, --remove-env must be set"))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fu     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fu = commands.FunctionUpdate(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fu.SetArgs([]string{"square", "--image", "foo/bar:v2", "--namespace", "ns"})
+
+			o := core.UpdateFunctionOptions{
+				Name:    "square",
+				Image:   "foo/bar:v2",
+				Env:     []string{},
+				EnvFrom: []string{},
+			}
+			o.Namespace = "ns"
+
+			asMock.On("UpdateFunction", o).Return(nil, nil)
+			err := fu.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should pass env vars when asked to", func() {
+			fu.SetArgs([]string{"square", "--env", "FOO=bar", "--env-from", "secretKeyRef:foo:bar"})
+
+			o := core.UpdateFunctionOptions{
+				Name:    "square",
+				Env:     []string{"FOO=bar"},
+				EnvFrom: []string{"secretKeyRef:foo:bar"},
+			}
+
+			asMock.On("UpdateFunction", o).Return(nil, nil)
+			err := fu.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should allow reserved env vars when asked to", func() {
+			fu.SetArgs([]string{"square", "--env", "PORT=9090", "--allow-reserved-env"})
+
+			o := core.UpdateFunctionOptions{
+				Name:             "square",
+				Env:              []string{"PORT=9090"},
+				EnvFrom:          []string{},
+				AllowReservedEnv: true,
+			}
+
+			asMock.On("UpdateFunction", o).Return(nil, nil)
+			err := fu.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should pass individual env var changes when asked to", func() {
+			fu.SetArgs([]string{"square", "--add-env", "FOO=bar", "--remove-env", "BAZ"})
+
+			o := core.UpdateFunctionOptions{
+				Name:      "square",
+				Env:       []string{},
+				EnvFrom:   []string{},
+				EnvAdd:    []v1.EnvVar{{Name: "FOO", Value: "bar"}},
+				EnvRemove: []string{"BAZ"},
+			}
+
+			asMock.On("UpdateFunction", o).Return(nil, nil)
+			err := fu.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should propagate core.Client errors", func() {
+			fu.SetArgs([]string{"square", "--image", "foo/bar:v2"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("UpdateFunction", mock.Anything).Return(nil, e)
+			err := fu.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function export command", func() {
+	var (
+		client core.Client
+		asMock *mocks.Client
+		fe     *cobra.Command
+	)
+	BeforeEach(func() {
+		client = new(mocks.Client)
+		asMock = client.(*mocks.Client)
+
+		fe = commands.FunctionExport(&client)
+	})
+	AfterEach(func() {
+		asMock.AssertExpectations(GinkgoT())
+	})
+	It("should print the exported function", func() {
+		fe.SetArgs([]string{"square", "--namespace", "ns"})
+
+		o := core.ExportFunctionOptions{Name: "square"}
+		o.Namespace = "ns"
+		asMock.On("ExportFunction", o).Return([]byte("kind: Service\n"), nil)
+
+		stdout := &strings.Builder{}
+		fe.SetOutput(stdout)
+		err := fe.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("kind: Service\n"))
+	})
+	It("should export all the functions of the namespace", func() {
+		fe.SetArgs([]string{"--all", "--namespace", "ns"})
+
+		o := core.ExportFunctionsOptions{}
+		o.Namespace = "ns"
+		asMock.On("ExportFunctions", o).Return([]byte("kind: Service\n---\nkind: Service\n"), nil)
+
+		stdout := &strings.Builder{}
+		fe.SetOutput(stdout)
+		err := fe.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("kind: Service\n---\nkind: Service\n"))
+	})
+	It("should require a function name or --all", func() {
+		fe.SetArgs([]string{})
+		err := fe.Execute()
+		Expect(err).To(MatchError("a function name, or --all, is required"))
+
+		fe.SetArgs([]string{"square", "--all"})
+		err = fe.Execute()
+		Expect(err).To(MatchError("a function name can't be given along with --all"))
+	})
+	It("should propagate core.Client errors", func() {
+		fe.SetArgs([]string{"square"})
+
+		e := fmt.Errorf("some error")
+		asMock.On("ExportFunction", mock.Anything).Return(nil, e)
+		err := fe.Execute()
+		Expect(err).To(MatchError(e))
+	})
+})
+
+var _ = Describe("The riff function scale command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fs         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fs = commands.FunctionScale(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fs.SetArgs([]string{})
+			err := fs.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail when no bound is given", func() {
+			fs.SetArgs([]string{"square"})
+			err := fs.Execute()
+			Expect(err).To(MatchError("at least one of --min, --max must be set"))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fs     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fs = commands.FunctionScale(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fs.SetArgs([]string{"square", "--min", "2", "--max", "5", "--namespace", "ns"})
+
+			o := core.ScaleFunctionOptions{
+				Name:     "square",
+				MinScale: 2,
+				MaxScale: 5,
+			}
+			o.Namespace = "ns"
+
+			asMock.On("ScaleFunction", o).Return(nil, nil)
+			stdout := &strings.Builder{}
+			fs.SetOutput(stdout)
+
+			err := fs.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(HavePrefix(`A new revision of function "square" is being rolled out with the new scale.`))
+		})
+		It("should propagate core.Client errors", func() {
+			fs.SetArgs([]string{"square", "--max", "5"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("ScaleFunction", mock.Anything).Return(nil, e)
+			err := fs.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function edit command", func() {
+	var (
+		client core.Client
+		asMock *mocks.Client
+		fe     *cobra.Command
+	)
+	BeforeEach(func() {
+		client = new(mocks.Client)
+		asMock = client.(*mocks.Client)
+
+		fe = commands.FunctionEdit(&client)
+	})
+	AfterEach(func() {
+		asMock.AssertExpectations(GinkgoT())
+	})
+	It("should edit the function with the given editor", func() {
+		fe.SetArgs([]string{"square", "--namespace", "ns", "--editor", "code --wait"})
+
+		o := core.EditFunctionOptions{Name: "square", Editor: "code --wait"}
+		o.Namespace = "ns"
+		asMock.On("EditFunction", o).Return(&v1alpha1.Service{}, true, nil)
+
+		stdout := &strings.Builder{}
+		fe.SetOutput(stdout)
+		err := fe.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("edit completed successfully\n"))
+	})
+	It("should tell when nothing was changed", func() {
+		fe.SetArgs([]string{"square"})
+
+		asMock.On("EditFunction", core.EditFunctionOptions{Name: "square"}).Return(nil, false, nil)
+
+		stdout := &strings.Builder{}
+		fe.SetOutput(stdout)
+		err := fe.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(stdout.String()).To(Equal("Edit cancelled, no changes made\n"))
+	})
+	It("should propagate core.Client errors", func() {
+		fe.SetArgs([]string{"square"})
+
+		e := fmt.Errorf("some error")
+		asMock.On("EditFunction", mock.Anything).Return(nil, false, e)
+		err := fe.Execute()
+		Expect(err).To(MatchError(e))
+	})
+})
+
+var _ = Describe("The riff function create-from-file command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fc         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fc = commands.FunctionCreateFromFile(&mockClient)
+		})
+		It("should fail with no file", func() {
+			fc.SetArgs([]string{})
+			err := fc.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fc     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fc = commands.FunctionCreateFromFile(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fc.SetArgs([]string{"square.yaml", "--namespace", "ns", "--name", "square-v2", "--image", "acme/square:2.0", "--env", "A=1"})
+
+			o := core.CreateFunctionFromFileOptions{
+				Path:  "square.yaml",
+				Name:  "square-v2",
+				Image: "acme/square:2.0",
+				Env:   []string{"A=1"},
+			}
+			o.Namespace = "ns"
+
+			asMock.On("CreateFunctionFromFile", o).Return(&v1alpha1.Service{}, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should print the function when dry running", func() {
+			fc.SetArgs([]string{"square.yaml", "--dry-run"})
+
+			s := &v1alpha1.Service{}
+			s.Name = "square"
+			asMock.On("CreateFunctionFromFile", mock.Anything).Return(s, nil)
+
+			stdout := &strings.Builder{}
+			fc.SetOutput(stdout)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(ContainSubstring("name: square\n"))
+		})
+		It("should propagate core.Client errors", func() {
+			fc.SetArgs([]string{"square.yaml"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("CreateFunctionFromFile", mock.Anything).Return(nil, e)
+			err := fc.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function clone command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fc         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fc = commands.FunctionClone(&mockClient)
+		})
+		It("should fail with no new name", func() {
+			fc.SetArgs([]string{"square"})
+			err := fc.Execute()
+			Expect(err).To(MatchError("accepts 2 arg(s), received 1"))
+		})
+		It("should fail with an invalid new name", func() {
+			fc.SetArgs([]string{"square", "Square"})
+			err := fc.Execute()
+			Expect(err).To(MatchError(ContainSubstring("a DNS-1123 label must consist of lower case alphanumeric characters")))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fc     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fc = commands.FunctionClone(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fc.SetArgs([]string{"square", "square-v2", "--namespace", "ns"})
+
+			o := core.CloneFunctionOptions{
+				Name:    "square",
+				NewName: "square-v2",
+			}
+			o.Namespace = "ns"
+
+			asMock.On("CloneFunction", o).Return(nil, nil)
+			err := fc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should propagate core.Client errors", func() {
+			fc.SetArgs([]string{"square", "square-v2"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("CloneFunction", mock.Anything).Return(nil, e)
+			err := fc.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function subscribe command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fs         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fs = commands.FunctionSubscribe(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fs.SetArgs([]string{"--input", "numbers"})
+			err := fs.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail without an input channel", func() {
+			fs.SetArgs([]string{"square"})
+			err := fs.Execute()
+			Expect(err).To(MatchError(`required flag(s) "input" not set`))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fs     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fs = commands.FunctionSubscribe(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fs.SetArgs([]string{"square", "--input", "numbers", "--namespace", "ns"})
+
+			o := core.SubscribeOptions{
+				Function: "square",
+				Channel:  "numbers",
+			}
+			o.Namespace = "ns"
+
+			s := &v1alpha12.Subscription{}
+			s.Name = "square"
+			asMock.On("Subscribe", o).Return(s, core.SubscriptionUpdated, nil)
+			stdout := &strings.Builder{}
+			fs.SetOutput(stdout)
+
+			err := fs.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(HavePrefix("Subscription \"square\" updated\n"))
+		})
+		It("should propagate core.Client errors", func() {
+			fs.SetArgs([]string{"square", "--input", "numbers"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("Subscribe", mock.Anything).Return(nil, core.SubscriptionChange(""), e)
+			err := fs.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function delete command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fd         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fd = commands.FunctionDelete(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fd.SetArgs([]string{})
+			err := fd.Execute()
+			Expect(err).To(MatchError("requires at least 1 arg(s), only received 0"))
+		})
+		It("should fail with invalid function name", func() {
+			fd.SetArgs([]string{".invalid"})
+			err := fd.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+		It("should fail when any of the function names is invalid", func() {
+			fd.SetArgs([]string{"square", ".invalid"})
+			err := fd.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fd     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fd = commands.FunctionDelete(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fd.SetArgs([]string{"square", "cube", "--namespace", "ns", "--wait", "--yes"})
+
+			o := core.DeleteFunctionsOptions{
+				Names: []string{"square", "cube"},
+				Wait:  true,
+			}
+			o.Namespace = "ns"
+
+			asMock.On("DeleteFunctions", mock.Anything, o).Return(nil)
+			err := fd.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should propagate core.Client errors", func() {
+			fd.SetArgs([]string{"square", "-y"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("DeleteFunctions", mock.Anything, mock.Anything).Return(e)
+			err := fd.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function list command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fl         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fl = commands.FunctionList(&mockClient)
+		})
+		It("should fail with args", func() {
+			fl.SetArgs([]string{"something"})
+			err := fl.Execute()
+			Expect(err).To(MatchError("accepts 0 arg(s), received 1"))
+		})
+		It("should fail when both namespace and all-namespaces are set", func() {
+			fl.SetArgs([]string{"--namespace", "ns", "--all-namespaces"})
+			err := fl.Execute()
+			Expect(err).To(MatchError("at most one of --namespace, --all-namespaces must be set"))
+		})
+		It("should fail with an unknown output format", func() {
+			fl.SetArgs([]string{"--output", "xml"})
+			err := fl.Execute()
+			Expect(err).To(MatchError(`invalid argument "xml" for "-o, --output" flag: must be one of table, json, yaml, name`))
+		})
+		It("should fail when watching with an output format other than table", func() {
+			fl.SetArgs([]string{"--watch", "--output", "json"})
+			err := fl.Execute()
+			Expect(err).To(MatchError("--watch can only be used with the table output, not json"))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fl     *cobra.Command
+			list   *v1alpha1.ServiceList
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fl = commands.FunctionList(&client)
+
+			list = &v1alpha1.ServiceList{
+				Items: []v1alpha1.Service{
+					{
+						ObjectMeta: meta_v1.ObjectMeta{Name: "foo", Namespace: "default"},
+						Status: v1alpha1.ServiceStatus{Conditions: []v1alpha1.ServiceCondition{
+							{
+								Type:    v1alpha1.ServiceConditionReady,
+								Reason:  "Failed",
+								Message: "It's dead, Jim",
+								Status:  v1.ConditionFalse,
+							},
+						}},
+					},
+					{
+						ObjectMeta: meta_v1.ObjectMeta{Name: "wizz", Namespace: "joseph-ns"},
+						Status: v1alpha1.ServiceStatus{Conditions: []v1alpha1.ServiceCondition{
+							{
+								Type:   v1alpha1.ServiceConditionReady,
+								Status: v1.ConditionTrue,
+							},
+						}},
+					},
+				},
+			}
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fl.SetArgs([]string{"--namespace", "ns", "--selector", "team=payments", "--field-selector", "metadata.name!=square"})
+
+			o := core.ListFunctionOptions{
+				LabelSelector: "team=payments",
+				FieldSelector: "metadata.name!=square",
+			}
+			o.Namespace = "ns"
+
+			asMock.On("ListFunctions", o).Return(list, nil)
+
+			stdout := &strings.Builder{}
+			fl.SetOutput(stdout)
+			err := fl.Execute()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(fnListOutput))
+		})
+		It("should display namespaces when listing across all namespaces", func() {
+			fl.SetArgs([]string{"--all-namespaces"})
+
+			o := core.ListFunctionOptions{
+				AllNamespaces: true,
+			}
+
+			asMock.On("ListFunctions", o).Return(list, nil)
+
+			stdout := &strings.Builder{}
+			fl.SetOutput(stdout)
+			err := fl.Execute()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(fnListAllNamespacesOutput))
+		})
+		It("should print changes when watching", func() {
+			fl.SetArgs([]string{"--watch"})
+
+			w := watch.NewFake()
+			list.ResourceVersion = "42"
+			asMock.On("ListFunctions", mock.Anything).Return(list, nil)
+			asMock.On("WatchFunctions", core.WatchFunctionsOptions{ResourceVersion: "42"}).Return(w, nil)
+
+			go func() {
+				defer GinkgoRecover()
+				ready := list.Items[0].DeepCopy()
+				ready.Status.Conditions[0].Status = v1.ConditionTrue
+				w.Modify(ready)
+				w.Delete(&list.Items[1])
+				w.Stop()
+			}()
+
+			stdout := &strings.Builder{}
+			fl.SetOutput(stdout)
+			err := fl.Execute()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(fnListOutput + "foo   Running\nwizz  Deleted\n"))
+		})
+		It("should render names when asked to", func() {
+			fl.SetArgs([]string{"-o", "name"})
+
+			asMock.On("ListFunctions", mock.Anything).Return(list, nil)
+
+			stdout := &strings.Builder{}
+			fl.SetOutput(stdout)
+			err := fl.Execute()
+
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal("foo\nwizz\n"))
+		})
+		It("should propagate core.Client errors", func() {
+			e := fmt.Errorf("some error")
+			asMock.On("ListFunctions", mock.Anything).Return(nil, e)
+			err := fl.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+const fnListOutput = `NAME  STATUS
+foo   Failed: It's dead, Jim
+wizz  Running
+`
+
+const fnListAllNamespacesOutput = `NAMESPACE  NAME  STATUS
+default    foo   Failed: It's dead, Jim
+joseph-ns  wizz  Running
+`
+
+var _ = Describe("The riff function get command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fg         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fg = commands.FunctionGet(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fg.SetArgs([]string{})
+			err := fg.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail with invalid function name", func() {
+			fg.SetArgs([]string{".invalid"})
+			err := fg.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fg     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fg = commands.FunctionGet(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fg.SetArgs([]string{"square", "--namespace", "ns"})
+
+			o := core.GetFunctionOptions{
+				Name: "square",
+			}
+			o.Namespace = "ns"
+
+			f := &v1alpha1.Service{
+				ObjectMeta: meta_v1.ObjectMeta{Name: "square"},
+				Spec: v1alpha1.ServiceSpec{
+					RunLatest: &v1alpha1.RunLatestType{},
+				},
+				Status: v1alpha1.ServiceStatus{
+					LatestCreatedRevisionName: "square-00002",
+					LatestReadyRevisionName:   "square-00001",
+					Conditions: []v1alpha1.ServiceCondition{
+						{
+							Type:   v1alpha1.ServiceConditionReady,
+							Status: v1.ConditionTrue,
+						},
+					},
+				},
+			}
+			f.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image = "acme/square:1.0"
+
+			asMock.On("GetFunction", o).Return(f, nil)
+
+			stdout := &strings.Builder{}
+			fg.SetOutput(stdout)
+			err := fg.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(fnGetOutput))
+		})
+		It("should propagate core.Client errors", func() {
+			fg.SetArgs([]string{"square"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("GetFunction", mock.Anything).Return(nil, e)
+			err := fg.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+const fnGetOutput = `Name:                        square
+Image:                       acme/square:1.0
+Latest Created Revision:     square-00002
+Latest Ready Revision:       square-00001
+Status:                      Running
+`
+
+var _ = Describe("The riff function invoke command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fi         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fi = commands.FunctionInvoke(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fi.SetArgs([]string{})
+			err := fi.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail with invalid function name", func() {
+			fi.SetArgs([]string{".invalid"})
+			err := fi.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fi     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fi = commands.FunctionInvoke(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client and print the response", func() {
+			fi.SetArgs([]string{"square", "--namespace", "ns", "--data", "8", "-H", "Content-Type: text/plain"})
+
+			o := core.InvokeFunctionOptions{
+				Name:    "square",
+				Method:  "POST",
+				Path:    "/",
+				Body:    []byte("8"),
+				Headers: []string{"Content-Type: text/plain"},
+			}
+			o.Namespace = "ns"
+
+			asMock.On("InvokeFunction", mock.Anything, o).Return(200, []byte("64"), nil)
+
+			stdout := &strings.Builder{}
+			fi.SetOutput(stdout)
+			err := fi.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal("64"))
+		})
+		It("should not fail on non 2xx responses by default", func() {
+			fi.SetArgs([]string{"square"})
+
+			asMock.On("InvokeFunction", mock.Anything, mock.Anything).Return(500, []byte("oops"), nil)
+			fi.SetOutput(&strings.Builder{})
+			err := fi.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should fail on non 2xx responses when --fail is set", func() {
+			fi.SetArgs([]string{"square", "--fail"})
+
+			asMock.On("InvokeFunction", mock.Anything, mock.Anything).Return(500, []byte("oops"), nil)
+			fi.SetOutput(&strings.Builder{})
+			err := fi.Execute()
+			Expect(err).To(MatchError("function responded with http status 500"))
+		})
+		It("should fail when the response can't be written, whatever its status", func() {
+			fi.SetArgs([]string{"square", "--fail"})
+
+			asMock.On("InvokeFunction", mock.Anything, mock.Anything).Return(500, []byte("oops"), nil)
+			fi.SetOutput(brokenPipe{})
+			err := fi.Execute()
+			Expect(err).To(MatchError(io.ErrClosedPipe))
+		})
+		It("should propagate core.Client errors", func() {
+			fi.SetArgs([]string{"square"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("InvokeFunction", mock.Anything, mock.Anything).Return(0, nil, e)
+			err := fi.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function logs command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fl         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fl = commands.FunctionLogs(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fl.SetArgs([]string{})
+			err := fl.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail with invalid function name", func() {
+			fl.SetArgs([]string{".invalid"})
+			err := fl.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fl     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fl = commands.FunctionLogs(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client and print the logs", func() {
+			fl.SetArgs([]string{"square", "--namespace", "ns", "-f", "--tail", "10"})
+
+			o := core.FunctionLogsOptions{
+				Name:      "square",
+				Follow:    true,
+				TailLines: 10,
+			}
+			o.Namespace = "ns"
+
+			logs := "[square-00001-deployment-abc] hello\n"
+			asMock.On("FunctionLogs", o).Return(ioutil.NopCloser(strings.NewReader(logs)), nil)
+
+			stdout := &strings.Builder{}
+			fl.SetOutput(stdout)
+			err := fl.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(logs))
+		})
+		It("should pass the revision when asked to", func() {
+			fl.SetArgs([]string{"square", "--revision", "square-00002"})
+
+			o := core.FunctionLogsOptions{
+				Name:      "square",
+				TailLines: -1,
+				Revision:  "square-00002",
+			}
+
+			asMock.On("FunctionLogs", o).Return(ioutil.NopCloser(strings.NewReader("")), nil)
+			err := fl.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should propagate core.Client errors", func() {
+			fl.SetArgs([]string{"square"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("FunctionLogs", mock.Anything).Return(nil, e)
+			err := fl.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff function build command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fb         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fb = commands.FunctionBuild(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fb.SetArgs([]string{})
+			err := fb.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail with invalid function name", func() {
+			fb.SetArgs([]string{".invalid"})
+			err := fb.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+		It("should fail without required flags", func() {
+			fb.SetArgs([]string{"square"})
+			err := fb.Execute()
+			Expect(err).To(MatchError(ContainSubstring("required flag(s)")))
+			Expect(err).To(MatchError(ContainSubstring("git-repo")))
+			Expect(err).To(MatchError(ContainSubstring("image")))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fb     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fb = commands.FunctionBuild(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--build-template", "kaniko", "--build-arg", "DOCKERFILE=Dockerfile.riff", "--build-env", "GOFLAGS=-mod=vendor",
+				"--namespace", "ns"})
+
+			o := core.BuildFunctionOptions{
+				Name:          "square",
+				Image:         "foo/bar",
+				GitRepo:       "https://github.com/repo",
+				GitRevision:   "master",
+				BuildTemplate: "kaniko",
+				BuildArgs:     map[string]string{"DOCKERFILE": "Dockerfile.riff"},
+				BuildEnv:      []v1.EnvVar{{Name: "GOFLAGS", Value: "-mod=vendor"}},
+			}
+			o.Namespace = "ns"
+
+			b := &build.Build{}
+			b.Name = "square-x7g2p"
+			asMock.On("BuildFunction", o).Return(b, nil)
+
+			stdout := &strings.Builder{}
+			fb.SetOutput(stdout)
+			err := fb.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(HavePrefix("Created build square-x7g2p\n"))
+		})
+		It("should pass the build service account", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--build-service-account", "pusher", "--verify"})
+
+			o := core.BuildFunctionOptions{
+				Name:                      "square",
+				Image:                     "foo/bar",
+				GitRepo:                   "https://github.com/repo",
+				GitRevision:               "master",
+				BuildTemplate:             "riff",
+				BuildServiceAccount:       "pusher",
+				VerifyBuildServiceAccount: true,
+			}
+
+			b := &build.Build{}
+			b.Name = "square-x7g2p"
+			asMock.On("BuildFunction", o).Return(b, nil)
+			err := fb.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
+		It("should propagate core.Client errors", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("BuildFunction", mock.Anything).Return(nil, e)
+			err := fb.Execute()
+			Expect(err).To(MatchError(e))
+		})
+		It("should reject build arguments provided more than once", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--build-arg", "DOCKERFILE=Dockerfile", "--build-arg", "DOCKERFILE=Dockerfile.riff"})
+
+			err := fb.Execute()
+			Expect(err).To(MatchError("build argument 'DOCKERFILE' is provided more than once"))
+		})
+		It("should reject malformed build environment variables", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--build-env", "GOFLAGS"})
+
+			err := fb.Execute()
+			Expect(err).To(MatchError("unable to parse 'GOFLAGS', environment variables must be provided as 'key=value'"))
+		})
+		It("should wait for the build when asked to", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo", "--namespace", "ns",
+				"--wait", "--build-logs", "--build-timeout", "15m"})
+
+			b := &build.Build{}
+			b.Name = "square-x7g2p"
+			asMock.On("BuildFunction", mock.Anything).Return(b, nil)
+
+			stdout := &strings.Builder{}
+			fb.SetOutput(stdout)
+			waitOptions := core.WaitForBuildOptions{Name: "square-x7g2p", Function: "square", Logs: stdout, Timeout: 15 * time.Minute}
+			waitOptions.Namespace = "ns"
+			asMock.On("WaitForBuild", mock.Anything, waitOptions).Return(nil)
+
+			err := fb.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(HavePrefix("Created build square-x7g2p\n"))
+		})
+		It("should report builds not completing within the build timeout", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--build-timeout", "15m"})
+
+			b := &build.Build{}
+			b.Name = "square-x7g2p"
+			asMock.On("BuildFunction", mock.Anything).Return(b, nil)
+			e := &core.BuildTimeoutError{Function: "square", Namespace: "default", Timeout: 15 * time.Minute}
+			asMock.On("WaitForBuild", mock.Anything, core.WaitForBuildOptions{Name: "square-x7g2p", Function: "square", Timeout: 15 * time.Minute}).Return(e)
+
+			fb.SetOutput(&strings.Builder{})
+			err := fb.Execute()
+			Expect(err).To(BeIdenticalTo(e))
+		})
+		It("should fail with a build timeout but no wait", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--build-timeout", "15m"})
+
+			err := fb.Execute()
+			Expect(err).To(MatchError("when --build-timeout is set, --wait must be set"))
+		})
+		It("should fail with a non positive build timeout", func() {
+			fb.SetArgs([]string{"square", "--image", "foo/bar", "--git-repo", "https://github.com/repo",
+				"--wait", "--build-timeout", "0s"})
+
+			err := fb.Execute()
+			Expect(err).To(MatchError(`invalid value "0s" for --build-timeout: must be positive`))
+		})
+	})
+})
+
+var _ = Describe("The riff function describe command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			fd         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			fd = commands.FunctionDescribe(&mockClient)
+		})
+		It("should fail with no args", func() {
+			fd.SetArgs([]string{})
+			err := fd.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+		It("should fail with invalid function name", func() {
+			fd.SetArgs([]string{".invalid"})
+			err := fd.Execute()
+			Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			fd     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			fd = commands.FunctionDescribe(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			fd.SetArgs([]string{"square", "--namespace", "ns"})
+
+			o := core.DescribeFunctionOptions{
+				Name: "square",
+			}
+			o.Namespace = "ns"
+
+			transition := time.Now().Add(-5 * time.Minute)
+			d := &core.FunctionDescription{
+				Name:      "square",
+				Namespace: "ns",
+				Image:     "acme/square:1.1",
+				URL:       "http://square.ns.example.com",
+				Conditions: []core.ConditionDescription{
+					{Type: "Ready", Status: "False", Reason: "RevisionFailed", Message: "Revision square-00002 failed", LastTransitionTime: transition},
+					{Type: "RoutesReady", Status: "True", LastTransitionTime: transition},
+				},
+				LatestCreatedRevision: &core.RevisionDescription{
+					Name:  "square-00002",
+					Image: "acme/square:1.1",
+					Conditions: []core.ConditionDescription{
+						{Type: "Ready", Status: "False", Reason: "ContainerMissing", Message: "Unable to fetch image"},
+					},
+				},
+			}
+			asMock.On("DescribeFunction", o).Return(d, nil)
+
+			stdout := &strings.Builder{}
+			fd.SetOutput(stdout)
+			err := fd.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(fnDescribeOutput))
+		})
+		It("should propagate core.Client errors", func() {
+			fd.SetArgs([]string{"square"})
+
+			e := &core.FunctionNotFoundError{Name: "square", Namespace: "default"}
+			asMock.On("DescribeFunction", mock.Anything).Return(nil, e)
+			err := fd.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+const fnDescribeOutput = `Name:         square
+Namespace:    ns
+Image:        acme/square:1.1
+URL:          http://square.ns.example.com
+Conditions:
+  TYPE         STATUS  REASON          AGE  MESSAGE
+  Ready        False   RevisionFailed  5m   Revision square-00002 failed
+  RoutesReady  True                    5m   
+Latest Created Revision:
+  Name:       square-00002
+  Image:      acme/square:1.1
+  Conditions:
+    TYPE   STATUS  REASON            AGE        MESSAGE
+    Ready  False   ContainerMissing  <unknown>  Unable to fetch image
+Latest Ready Revision:  <none>
+`
+
+var _ = Describe("The riff function create command pinning digests", func() {
+
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	var (
+		registry *httptest.Server
+		client   core.Client
+		fc       *cobra.Command
+		output   *strings.Builder
+	)
+
+	BeforeEach(func() {
+		// a registry with a self-signed certificate
+		registry = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Docker-Content-Digest", digest)
+		}))
+		// a dry run doesn't reach the cluster
+		client = core.NewClient(nil, nil, nil, nil)
+		fc = commands.FunctionCreate(&client)
+		output = &strings.Builder{}
+		fc.SetOutput(output)
+	})
+
+	AfterEach(func() {
+		registry.Close()
+	})
+
+	It("should resolve digests from insecure registries", func() {
+		image := strings.TrimPrefix(registry.URL, "https://") + "/acme/square:1.0"
+		fc.SetArgs([]string{"node", "square", "--image", image, "--pin-digest", "--require-digest", "--registry-insecure", "--dry-run"})
+
+		err := fc.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(output.String()).To(ContainSubstring("image: " + image + "@" + digest))
+	})
+
+	It("should verify the certificate of registries unless insecure", func() {
+		image := strings.TrimPrefix(registry.URL, "https://") + "/acme/square:1.0"
+		fc.SetArgs([]string{"node", "square", "--image", image, "--pin-digest", "--require-digest", "--dry-run"})
+
+		err := fc.Execute()
+		Expect(err).To(MatchError(ContainSubstring("the certificate of its registry can't be verified")))
+	})
+})
+
+// brokenPipe is an output that can't be written to, as when piping to a command that exited
+type brokenPipe struct{}
+
+func (brokenPipe) Write(p []byte) (int, error) {
+	return 0, io.ErrClosedPipe
+}
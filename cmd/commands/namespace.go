@@ -17,6 +17,9 @@
 package commands
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/spf13/cobra"
 	"github.com/projectriff/riff/pkg/core"
 )
@@ -62,3 +65,85 @@ func NamespaceInit(kc *core.KubectlClient) *cobra.Command {
 	command.MarkFlagRequired("secret")
 	return command
 }
+
+const (
+	namespaceCreateNameIndex = iota
+	namespaceCreateNumberOfArgs
+)
+
+func NamespaceCreate(fcTool *core.Client) *cobra.Command {
+	options := core.CreateNamespaceOptions{}
+
+	command := &cobra.Command{
+		Use:   "create",
+		Short: "Create a namespace, if it doesn't exist yet",
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(namespaceCreateNumberOfArgs),
+			AtPosition(namespaceCreateNameIndex, ValidName())),
+		Example: `  riff namespace create joseph-ns`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.Name = args[namespaceCreateNameIndex]
+			if _, err := (*fcTool).CreateNamespace(options); err != nil {
+				return err
+			}
+
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "NAME")
+
+	return command
+}
+
+// namespaceDeleteTimeout bounds how long deleting a namespace may wait for all its resources to be finalized
+const namespaceDeleteTimeout = 5 * time.Minute
+
+const (
+	namespaceDeleteNameIndex = iota
+	namespaceDeleteNumberOfArgs
+)
+
+func NamespaceDelete(fcTool *core.Client) *cobra.Command {
+	options := core.DeleteNamespaceOptions{}
+
+	command := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a namespace, along with all the resources in it",
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(namespaceDeleteNumberOfArgs),
+			AtPosition(namespaceDeleteNameIndex, ValidName())),
+		Example: `  riff namespace delete joseph-ns --wait`,
+		PreRunE: FlagsValidatorAsCobraRunE(RequiredUnlessTTY("yes")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			options.Name = args[namespaceDeleteNameIndex]
+			prompt := fmt.Sprintf("Delete namespace %s, along with all the resources in it?", options.Name)
+			if ok, err := Confirmed(cmd, prompt); err != nil {
+				return err
+			} else if !ok {
+				printAbortedCompletion(cmd)
+				return nil
+			}
+			ctx, cancel, err := ContextWithTimeout(cmd)
+			if err != nil {
+				return err
+			}
+			defer cancel()
+			if err := (*fcTool).DeleteNamespace(ctx, options); err != nil {
+				return err
+			}
+
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "NAME")
+
+	command.Flags().BoolVar(&options.Wait, "wait", false, "wait until the namespace and all its resources are gone")
+	AddTimeoutFlag(command, namespaceDeleteTimeout)
+	AddYesFlag(command)
+
+	return command
+}
@@ -0,0 +1,111 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/projectriff/riff/pkg/core/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/mock"
+	"k8s.io/api/core/v1"
+)
+
+var _ = Describe("The riff namespace create command", func() {
+	var (
+		client core.Client
+		asMock *mocks.Client
+		nc     *cobra.Command
+	)
+	BeforeEach(func() {
+		client = new(mocks.Client)
+		asMock = client.(*mocks.Client)
+
+		nc = commands.NamespaceCreate(&client)
+	})
+	AfterEach(func() {
+		asMock.AssertExpectations(GinkgoT())
+	})
+
+	It("should fail with no args", func() {
+		nc.SetArgs([]string{})
+		err := nc.Execute()
+		Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+	})
+	It("should fail with an invalid namespace name", func() {
+		nc.SetArgs([]string{".invalid"})
+		err := nc.Execute()
+		Expect(err).To(MatchError(ContainSubstring("must start and end with an alphanumeric character")))
+	})
+	It("should involve the core.Client", func() {
+		nc.SetArgs([]string{"numbers"})
+
+		asMock.On("CreateNamespace", core.CreateNamespaceOptions{Name: "numbers"}).Return(&v1.Namespace{}, nil)
+		err := nc.Execute()
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("should propagate core.Client errors", func() {
+		nc.SetArgs([]string{"numbers"})
+
+		e := fmt.Errorf("some error")
+		asMock.On("CreateNamespace", mock.Anything).Return(nil, e)
+		err := nc.Execute()
+		Expect(err).To(MatchError(e))
+	})
+})
+
+var _ = Describe("The riff namespace delete command", func() {
+	var (
+		client core.Client
+		asMock *mocks.Client
+		nd     *cobra.Command
+	)
+	BeforeEach(func() {
+		client = new(mocks.Client)
+		asMock = client.(*mocks.Client)
+
+		nd = commands.NamespaceDelete(&client)
+	})
+	AfterEach(func() {
+		asMock.AssertExpectations(GinkgoT())
+	})
+
+	It("should fail with no args", func() {
+		nd.SetArgs([]string{})
+		err := nd.Execute()
+		Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+	})
+	It("should involve the core.Client", func() {
+		nd.SetArgs([]string{"numbers", "--wait", "--yes"})
+
+		asMock.On("DeleteNamespace", mock.Anything, core.DeleteNamespaceOptions{Name: "numbers", Wait: true}).Return(nil)
+		err := nd.Execute()
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("should propagate core.Client errors", func() {
+		nd.SetArgs([]string{"numbers", "--yes"})
+
+		e := fmt.Errorf("some error")
+		asMock.On("DeleteNamespace", mock.Anything, mock.Anything).Return(e)
+		err := nd.Execute()
+		Expect(err).To(MatchError(e))
+	})
+})
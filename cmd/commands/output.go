@@ -0,0 +1,91 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+
+	"github.com/ghodss/yaml"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// OutputFormat is the way command results are rendered.
+type OutputFormat string
+
+const (
+	// OutputFormatTable renders results as human readable tables. It is up to each command to do so.
+	OutputFormatTable OutputFormat = "table"
+	OutputFormatJSON  OutputFormat = "json"
+	OutputFormatYAML  OutputFormat = "yaml"
+	// OutputFormatName renders only the names of the resources, one per line.
+	OutputFormatName OutputFormat = "name"
+)
+
+var outputFormats = []string{
+	string(OutputFormatTable),
+	string(OutputFormatJSON),
+	string(OutputFormatYAML),
+	string(OutputFormatName),
+}
+
+// Render writes a kubernetes object (or list of objects) to w, in any of the machine readable formats.
+func Render(w io.Writer, obj interface{}, format OutputFormat) error {
+	o, ok := obj.(runtime.Object)
+	if !ok {
+		return fmt.Errorf("unable to render %T, it is not a kubernetes object", obj)
+	}
+
+	switch format {
+	case OutputFormatJSON:
+		bs, err := json.MarshalIndent(o, "", "  ")
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "%s\n", bs)
+		return err
+	case OutputFormatYAML:
+		bs, err := yaml.Marshal(o)
+		if err != nil {
+			return err
+		}
+		_, err = w.Write(bs)
+		return err
+	case OutputFormatName:
+		items := []runtime.Object{o}
+		if meta.IsListType(o) {
+			var err error
+			if items, err = meta.ExtractList(o); err != nil {
+				return err
+			}
+		}
+		for _, item := range items {
+			accessor, err := meta.Accessor(item)
+			if err != nil {
+				return err
+			}
+			if _, err := fmt.Fprintln(w, accessor.GetName()); err != nil {
+				return err
+			}
+		}
+		return nil
+	default:
+		return fmt.Errorf("unsupported output format %q", format)
+	}
+}
@@ -0,0 +1,79 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"strings"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+var _ = Describe("The output rendering", func() {
+
+	var (
+		out  *strings.Builder
+		list *v1alpha1.ServiceList
+	)
+
+	BeforeEach(func() {
+		out = &strings.Builder{}
+		list = &v1alpha1.ServiceList{
+			Items: []v1alpha1.Service{
+				{ObjectMeta: meta_v1.ObjectMeta{Name: "square"}},
+				{ObjectMeta: meta_v1.ObjectMeta{Name: "tweets-logger"}},
+			},
+		}
+	})
+
+	It("should render names, one per line", func() {
+		err := commands.Render(out, list, commands.OutputFormatName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(Equal("square\ntweets-logger\n"))
+	})
+
+	It("should render the name of a single object", func() {
+		err := commands.Render(out, &list.Items[0], commands.OutputFormatName)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(Equal("square\n"))
+	})
+
+	It("should render json", func() {
+		err := commands.Render(out, &list.Items[0], commands.OutputFormatJSON)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(MatchJSON(`{"metadata": {"name": "square", "creationTimestamp": null}, "spec": {}, "status": {}}`))
+	})
+
+	It("should render yaml", func() {
+		err := commands.Render(out, &list.Items[0], commands.OutputFormatYAML)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(out.String()).To(MatchYAML("metadata: {name: square, creationTimestamp: null}\nspec: {}\nstatus: {}\n"))
+	})
+
+	It("should fail to render values that are not kubernetes objects", func() {
+		err := commands.Render(out, "square", commands.OutputFormatJSON)
+		Expect(err).To(MatchError("unable to render string, it is not a kubernetes object"))
+	})
+
+	It("should fail for formats it does not support", func() {
+		err := commands.Render(out, list, commands.OutputFormatTable)
+		Expect(err).To(MatchError(`unsupported output format "table"`))
+	})
+})
@@ -0,0 +1,134 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands
+
+import (
+	"fmt"
+	"text/tabwriter"
+	"time"
+
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/spf13/cobra"
+)
+
+func Revision() *cobra.Command {
+	return &cobra.Command{
+		Use:   "revision",
+		Short: "Interact with the revisions of functions",
+	}
+}
+
+const (
+	revisionListFunctionNameIndex = iota
+	revisionListNumberOfArgs
+)
+
+const (
+	revisionDeleteRevisionNameIndex = iota
+	revisionDeleteNumberOfArgs
+)
+
+func RevisionList(fcTool *core.Client) *cobra.Command {
+	listRevisionsOptions := core.ListRevisionsOptions{}
+
+	command := &cobra.Command{
+		Use:   "list",
+		Short: "List the revisions of a function",
+		Long: `List the revisions of a function, most recent first.
+
+The share of the function traffic each revision receives is displayed, which helps choosing a revision to pin the
+function to.`,
+		Example: `  riff revision list square --namespace joseph-ns`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(revisionListNumberOfArgs),
+			AtPosition(revisionListFunctionNameIndex, ValidName()),
+		),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			listRevisionsOptions.Function = args[revisionListFunctionNameIndex]
+			revisions, err := (*fcTool).ListRevisions(listRevisionsOptions)
+			if err != nil {
+				return err
+			}
+
+			if len(revisions) == 0 {
+				fmt.Fprintln(cmd.OutOrStdout(), "No resources found.")
+				return nil
+			}
+			w := tabwriter.NewWriter(cmd.OutOrStdout(), 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "NAME\tTRAFFIC\tREADY\tCREATED\tIMAGE")
+			for _, r := range revisions {
+				ready := "Unknown"
+				if r.Ready != nil {
+					ready = r.Ready.Status
+				}
+				fmt.Fprintf(w, "%s\t%d%%\t%s\t%s\t%s\n", r.Name, r.TrafficPercent, ready, r.Created.UTC().Format(time.RFC3339), r.Image)
+			}
+			return w.Flush()
+		},
+	}
+
+	LabelArgs(command, "FUNCTION_NAME")
+	CompleteFunctionNames(command)
+
+	command.Flags().StringVarP(&listRevisionsOptions.Namespace, "namespace", "n", "", "the `namespace` of the function")
+
+	return command
+}
+
+func RevisionDelete(fcTool *core.Client) *cobra.Command {
+	deleteRevisionOptions := core.DeleteRevisionOptions{}
+
+	command := &cobra.Command{
+		Use:   "delete",
+		Short: "Delete a revision of a function",
+		Long: `Delete a revision of a function.
+
+A revision the function still uses is not deleted, unless the force flag is set, as this would break the function:
+one still receiving traffic, the one the function is pinned to, or the latest ready one of a function running the
+latest revision.`,
+		Example: `  riff revision delete square-00001 --namespace joseph-ns`,
+		Args: ArgValidationConjunction(
+			cobra.ExactArgs(revisionDeleteNumberOfArgs),
+			AtPosition(revisionDeleteRevisionNameIndex, ValidName()),
+		),
+		PreRunE: FlagsValidatorAsCobraRunE(RequiredUnlessTTY("yes")),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			deleteRevisionOptions.Name = args[revisionDeleteRevisionNameIndex]
+			prompt := fmt.Sprintf("Delete revision %s%s?", deleteRevisionOptions.Name, inNamespace(deleteRevisionOptions.Namespace))
+			if ok, err := Confirmed(cmd, prompt); err != nil {
+				return err
+			} else if !ok {
+				printAbortedCompletion(cmd)
+				return nil
+			}
+			if err := (*fcTool).DeleteRevision(deleteRevisionOptions); err != nil {
+				return err
+			}
+
+			printSuccessfulCompletion(cmd)
+			return nil
+		},
+	}
+
+	LabelArgs(command, "REVISION_NAME")
+
+	command.Flags().StringVarP(&deleteRevisionOptions.Namespace, "namespace", "n", "", "the `namespace` of the revision")
+	command.Flags().BoolVar(&deleteRevisionOptions.Force, "force", false, "delete the revision even if the function still uses it")
+	AddYesFlag(command)
+
+	return command
+}
@@ -0,0 +1,150 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/projectriff/riff/pkg/core"
+	"github.com/projectriff/riff/pkg/core/mocks"
+	"github.com/spf13/cobra"
+	"github.com/stretchr/testify/mock"
+)
+
+var _ = Describe("The riff revision list command", func() {
+	Context("when given wrong args or flags", func() {
+		var (
+			mockClient core.Client
+			rl         *cobra.Command
+		)
+		BeforeEach(func() {
+			mockClient = nil
+			rl = commands.RevisionList(&mockClient)
+		})
+		It("should fail with no args", func() {
+			rl.SetArgs([]string{})
+			err := rl.Execute()
+			Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+		})
+	})
+
+	Context("when given suitable args and flags", func() {
+		var (
+			client core.Client
+			asMock *mocks.Client
+			rl     *cobra.Command
+		)
+		BeforeEach(func() {
+			client = new(mocks.Client)
+			asMock = client.(*mocks.Client)
+
+			rl = commands.RevisionList(&client)
+		})
+		AfterEach(func() {
+			asMock.AssertExpectations(GinkgoT())
+		})
+		It("should involve the core.Client", func() {
+			rl.SetArgs([]string{"square", "--namespace", "ns"})
+
+			o := core.ListRevisionsOptions{Function: "square"}
+			o.Namespace = "ns"
+
+			revisions := []core.RevisionSummary{
+				{
+					Name:           "square-00002",
+					Image:          "acme/square:2",
+					Created:        time.Date(2018, 10, 2, 9, 30, 0, 0, time.UTC),
+					Ready:          &core.ConditionDescription{Type: "Ready", Status: "Unknown"},
+					TrafficPercent: 0,
+				},
+				{
+					Name:           "square-00001",
+					Image:          "acme/square:1",
+					Created:        time.Date(2018, 10, 1, 9, 30, 0, 0, time.UTC),
+					Ready:          &core.ConditionDescription{Type: "Ready", Status: "True"},
+					TrafficPercent: 100,
+				},
+			}
+			asMock.On("ListRevisions", o).Return(revisions, nil)
+
+			stdout := &strings.Builder{}
+			rl.SetOutput(stdout)
+			err := rl.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(stdout.String()).To(Equal(revisionListOutput))
+		})
+		It("should propagate core.Client errors", func() {
+			rl.SetArgs([]string{"square"})
+
+			e := fmt.Errorf("some error")
+			asMock.On("ListRevisions", mock.Anything).Return(nil, e)
+			err := rl.Execute()
+			Expect(err).To(MatchError(e))
+		})
+	})
+})
+
+var _ = Describe("The riff revision delete command", func() {
+	var (
+		client core.Client
+		asMock *mocks.Client
+		rd     *cobra.Command
+	)
+	BeforeEach(func() {
+		client = new(mocks.Client)
+		asMock = client.(*mocks.Client)
+
+		rd = commands.RevisionDelete(&client)
+	})
+	AfterEach(func() {
+		asMock.AssertExpectations(GinkgoT())
+	})
+	It("should fail with no args", func() {
+		rd.SetArgs([]string{})
+		err := rd.Execute()
+		Expect(err).To(MatchError("accepts 1 arg(s), received 0"))
+	})
+	It("should involve the core.Client", func() {
+		rd.SetArgs([]string{"square-00001", "--namespace", "ns", "--force", "--yes"})
+
+		o := core.DeleteRevisionOptions{Name: "square-00001", Force: true}
+		o.Namespace = "ns"
+
+		asMock.On("DeleteRevision", o).Return(nil)
+		rd.SetOutput(&strings.Builder{})
+		err := rd.Execute()
+		Expect(err).NotTo(HaveOccurred())
+	})
+	It("should propagate core.Client errors", func() {
+		rd.SetArgs([]string{"square-00001", "--yes"})
+
+		e := fmt.Errorf("some error")
+		asMock.On("DeleteRevision", mock.Anything).Return(e)
+		err := rd.Execute()
+		Expect(err).To(MatchError(e))
+	})
+})
+
+const revisionListOutput = `NAME          TRAFFIC  READY    CREATED               IMAGE
+square-00002  0%       Unknown  2018-10-02T09:30:00Z  acme/square:2
+square-00001  100%     True     2018-10-01T09:30:00Z  acme/square:1
+`
@@ -87,7 +87,7 @@ func ServiceCreate(fcTool *core.Client) *cobra.Command {
   riff service create tweets-logger --image acme/tweets-logger:1.0.0 --input tweets --bus kafka`,
 		Args: ArgValidationConjunction(
 			cobra.ExactArgs(serviceCreateNumberOfArgs),
-			AtPosition(serviceCreateServiceNameIndex, ValidName()),
+			AtPosition(serviceCreateServiceNameIndex, ValidServiceName()),
 		),
 		PreRunE: FlagsValidatorAsCobraRunE(
 			FlagsValidationConjunction(
@@ -179,6 +179,8 @@ func ServiceCreate(fcTool *core.Client) *cobra.Command {
 
 	command.Flags().StringArrayVar(&createServiceOptions.Env, "env", []string{}, envUsage)
 	command.Flags().StringArrayVar(&createServiceOptions.EnvFrom, "env-from", []string{}, envFromUsage)
+	command.Flags().BoolVar(&createServiceOptions.AllowReservedEnv, "allow-reserved-env", false, allowReservedEnvUsage)
+	command.Flags().StringVar(&createServiceOptions.PinnedRevision, "pin-revision", "", pinRevisionUsage)
 
 	return command
 }
@@ -226,6 +228,7 @@ func ServiceStatus(fcClient *core.Client) *cobra.Command {
 
 func ServiceList(fcClient *core.Client) *cobra.Command {
 	listServiceOptions := core.ListServiceOptions{}
+	output := string(OutputFormatTable)
 
 	command := &cobra.Command{
 		Use:   "list",
@@ -239,6 +242,10 @@ func ServiceList(fcClient *core.Client) *cobra.Command {
 				return err
 			}
 
+			if OutputFormat(output) != OutputFormatTable {
+				return Render(cmd.OutOrStdout(), services, OutputFormat(output))
+			}
+
 			if len(services.Items) == 0 {
 				fmt.Fprintln(cmd.OutOrStdout(), "No resources found.")
 			} else {
@@ -252,22 +259,7 @@ func ServiceList(fcClient *core.Client) *cobra.Command {
 
 				fmt.Fprintf(cmd.OutOrStdout(), pad, "NAME", "STATUS")
 				for _, service := range services.Items {
-					cond := service.Status.GetCondition(v1alpha12.ServiceConditionReady)
-					var status string
-					if cond == nil {
-						status = "Unknown"
-					} else {
-						switch cond.Status {
-						case v1.ConditionTrue:
-							status = "Running"
-						case v1.ConditionFalse:
-							status = fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
-						default:
-							status = "Unknown"
-						}
-					}
-
-					fmt.Fprintf(cmd.OutOrStdout(), pad, service.Name, status)
+					fmt.Fprintf(cmd.OutOrStdout(), pad, service.Name, serviceStatus(service))
 				}
 			}
 
@@ -276,6 +268,7 @@ func ServiceList(fcClient *core.Client) *cobra.Command {
 	}
 
 	command.Flags().StringVarP(&listServiceOptions.Namespace, "namespace", "n", "", "the `namespace` of the services to be listed")
+	command.Flags().VarP(OneOfStringValue(&output, outputFormats...), "output", "o", outputUsage)
 
 	return command
 }
@@ -394,9 +387,17 @@ func ServiceDelete(fcClient *core.Client) *cobra.Command {
 			cobra.ExactArgs(serviceDeleteNumberOfArgs),
 			AtPosition(serviceDeleteServiceNameIndex, ValidName()),
 		),
+		PreRunE: FlagsValidatorAsCobraRunE(RequiredUnlessTTY("yes")),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			fnName := args[serviceDeleteServiceNameIndex]
 			deleteServiceOptions.Name = fnName
+			prompt := fmt.Sprintf("Delete service %s%s?", fnName, inNamespace(deleteServiceOptions.Namespace))
+			if ok, err := Confirmed(cmd, prompt); err != nil {
+				return err
+			} else if !ok {
+				printAbortedCompletion(cmd)
+				return nil
+			}
 			err := (*fcClient).DeleteService(deleteServiceOptions)
 			if err != nil {
 				return err
@@ -410,10 +411,27 @@ func ServiceDelete(fcClient *core.Client) *cobra.Command {
 	LabelArgs(command, "SERVICE_NAME")
 
 	command.Flags().StringVarP(&deleteServiceOptions.Namespace, "namespace", "n", "", "the `namespace` of the service")
+	AddYesFlag(command)
 
 	return command
 }
 
+// serviceStatus returns a short, human readable status of a service, based on its Ready condition.
+func serviceStatus(service v1alpha12.Service) string {
+	cond := service.Status.GetCondition(v1alpha12.ServiceConditionReady)
+	if cond == nil {
+		return "Unknown"
+	}
+	switch cond.Status {
+	case v1.ConditionTrue:
+		return "Running"
+	case v1.ConditionFalse:
+		return fmt.Sprintf("%s: %s", cond.Reason, cond.Message)
+	default:
+		return "Unknown"
+	}
+}
+
 // subscriptionNameFromService returns the name to use for the subscription being created alongside
 // a service/function. By convention, this is chosen to be the name of the service.
 func subscriptionNameFromService(fnName string) string {
@@ -129,6 +129,21 @@ var _ = Describe("The riff service create command", func() {
 			err := sc.Execute()
 			Expect(err).NotTo(HaveOccurred())
 		})
+		It("should pin to a revision when asked to", func() {
+			sc.SetArgs([]string{"my-service", "--image", "foo/bar", "--pin-revision", "my-service-00001"})
+
+			o := core.CreateServiceOptions{
+				Name:           "my-service",
+				Image:          "foo/bar",
+				Env:            []string{},
+				EnvFrom:        []string{},
+				PinnedRevision: "my-service-00001",
+			}
+
+			asMock.On("CreateService", o).Return(nil, nil)
+			err := sc.Execute()
+			Expect(err).NotTo(HaveOccurred())
+		})
 		It("should print when --dry-run is set", func() {
 			sc.SetArgs([]string{"square", "--image", "foo/bar",
 				"--input", "my-channel", "--bus", "kafka", "--dry-run"})
@@ -505,7 +520,7 @@ var _ = Describe("The riff service delete command", func() {
 			asMock.AssertExpectations(GinkgoT())
 		})
 		It("should involve the core.Client", func() {
-			sd.SetArgs([]string{"my-service", "--namespace", "ns"})
+			sd.SetArgs([]string{"my-service", "--namespace", "ns", "--yes"})
 
 			o := core.DeleteServiceOptions{
 				Name: "my-service",
@@ -517,7 +532,7 @@ var _ = Describe("The riff service delete command", func() {
 			Expect(err).NotTo(HaveOccurred())
 		})
 		It("should propagate core.Client errors", func() {
-			sd.SetArgs([]string{"my-service"})
+			sd.SetArgs([]string{"my-service", "--yes"})
 
 			e := fmt.Errorf("some error")
 			asMock.On("DeleteService", mock.Anything).Return(e)
@@ -19,6 +19,7 @@ package commands
 import (
 	"fmt"
 	"os/user"
+	"sort"
 	"strings"
 
 	eventing "github.com/knative/eventing/pkg/client/clientset/versioned"
@@ -31,16 +32,31 @@ import (
 	clientcmdapi "k8s.io/client-go/tools/clientcmd/api"
 )
 
-var realClientSetFactory = func(kubeconfig string, masterURL string) (clientcmd.ClientConfig, kubernetes.Interface, eventing.Interface, serving.Interface, error) {
+var realClientSetFactory = func(kubeconfig string, kubeContext string, masterURL string) (clientcmd.ClientConfig, kubernetes.Interface, eventing.Interface, serving.Interface, error) {
 
-	kubeconfig, err := resolveHomePath(kubeconfig)
-	if err != nil {
-		return nil, nil, nil, nil, err
+	// Honor $KUBECONFIG (and its default) unless a kubeconfig is explicitly provided
+	loadingRules := clientcmd.NewDefaultClientConfigLoadingRules()
+	if kubeconfig != "" {
+		kubeconfig, err := resolveHomePath(kubeconfig)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		loadingRules.ExplicitPath = kubeconfig
 	}
 
 	clientConfig := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
-		&clientcmd.ConfigOverrides{ClusterInfo: clientcmdapi.Cluster{Server: masterURL}})
+		loadingRules,
+		&clientcmd.ConfigOverrides{CurrentContext: kubeContext, ClusterInfo: clientcmdapi.Cluster{Server: masterURL}})
+
+	if kubeContext != "" {
+		rawConfig, err := clientConfig.RawConfig()
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		if _, ok := rawConfig.Contexts[kubeContext]; !ok {
+			return nil, nil, nil, nil, fmt.Errorf("context %q does not exist in the kubeconfig, available contexts are %v", kubeContext, contextNames(rawConfig))
+		}
+	}
 
 	cfg, err := clientConfig.ClientConfig()
 	if err != nil {
@@ -59,6 +75,15 @@ var realClientSetFactory = func(kubeconfig string, masterURL string) (clientcmd.
 	return clientConfig, kubeClientSet, eventingClientSet, servingClientSet, err
 }
 
+func contextNames(config clientcmdapi.Config) []string {
+	names := make([]string, 0, len(config.Contexts))
+	for name := range config.Contexts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
 func resolveHomePath(p string) (string, error) {
 	if strings.HasPrefix(p, "~/") {
 		u, err := user.Current()
@@ -76,12 +101,62 @@ func resolveHomePath(p string) (string, error) {
 
 }
 
+// KubeOptions holds the flags locating the kubernetes cluster to talk to, and the clients built from them once the
+// command runs.
+type KubeOptions struct {
+	Kubeconfig string
+	Context    string
+	MasterURL  string
+
+	// DefaultNamespace is the namespace of the kubeconfig context in use, which resources are in when commands don't
+	// name one. The --namespace flag of the command run is set to it when not given.
+	DefaultNamespace string
+
+	Client        core.Client
+	KubectlClient core.KubectlClient
+}
+
+// NamespaceDefaultedByCommandAnnotation marks a --namespace flag that must stay empty unless given, as the command
+// has a default of its own to try before the namespace of the kubeconfig context.
+const NamespaceDefaultedByCommandAnnotation = "riff_namespace_defaulted_by_command"
+
+// RegisterKubeFlags defines the --kubeconfig, --context and --master persistent flags on cmd, so that they are
+// accepted by all its subcommands, and makes cmd resolve the default namespace and build the clients stashed in opts
+// before any of them runs. The --namespace flag of the subcommand run, if any and not given, is set to the default
+// namespace, so that it is known to the command, e.g. to name it in prompts.
+func RegisterKubeFlags(cmd *cobra.Command, opts *KubeOptions) {
+	cmd.PersistentFlags().StringVar(&opts.Kubeconfig, "kubeconfig", "", "the `path` of a kubeconfig; defaults to $KUBECONFIG, then ~/.kube/config")
+	cmd.PersistentFlags().StringVar(&opts.Context, "context", "", "the `name` of the kubeconfig context to use; defaults to the current context")
+	cmd.PersistentFlags().StringVar(&opts.MasterURL, "master", "", "the `address` of the Kubernetes API server; overrides any value in kubeconfig")
+
+	cmd.PersistentPreRunE = func(cmd *cobra.Command, args []string) error {
+		clientConfig, kubeClientSet, eventingClientSet, servingClientSet, err := realClientSetFactory(opts.Kubeconfig, opts.Context, opts.MasterURL)
+		if err != nil {
+			return err
+		}
+		opts.DefaultNamespace = core.DefaultNamespace(clientConfig)
+		if err := defaultNamespaceFlag(cmd, opts.DefaultNamespace); err != nil {
+			return err
+		}
+		opts.Client = core.NewClient(clientConfig, kubeClientSet, eventingClientSet, servingClientSet)
+		opts.KubectlClient = core.NewKubectlClient(kubeClientSet)
+		return nil
+	}
+}
+
+// defaultNamespaceFlag sets the --namespace flag of cmd to namespace, unless the flag was given, is marked with
+// NamespaceDefaultedByCommandAnnotation or doesn't exist.
+func defaultNamespaceFlag(cmd *cobra.Command, namespace string) error {
+	flag := cmd.Flags().Lookup("namespace")
+	if flag == nil || flag.Changed || len(flag.Annotations[NamespaceDefaultedByCommandAnnotation]) > 0 {
+		return nil
+	}
+	return flag.Value.Set(namespace)
+}
+
 func CreateAndWireRootCommand() *cobra.Command {
 
-	kubeconfig := ""
-	masterURL := ""
-	var client core.Client
-	var kc core.KubectlClient
+	kube := &KubeOptions{}
 
 	rootCmd := &cobra.Command{
 		Use:   "riff",
@@ -93,74 +168,90 @@ See https://projectriff.io and https://github.com/knative/docs`,
 		SilenceErrors:              true, // We'll print errors ourselves (after usage rather than before)
 		DisableAutoGenTag:          true,
 		SuggestionsMinimumDistance: 2,
-		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			var err error
-			clientConfig, kubeClientSet, eventingClientSet, servingClientSet, err := realClientSetFactory(kubeconfig, masterURL)
-			if err != nil {
-				return err
-			}
-			client = core.NewClient(clientConfig, kubeClientSet, eventingClientSet, servingClientSet)
-			kc = core.NewKubectlClient(kubeClientSet)
-			return nil
-		},
 	}
 
 	installAdvancedUsage(rootCmd)
-
-	rootCmd.PersistentFlags().StringVar(&kubeconfig, "kubeconfig", "~/.kube/config", "the `path` of a kubeconfig")
-	rootCmd.PersistentFlags().StringVar(&masterURL, "master", "", "the `address` of the Kubernetes API server; overrides any value in kubeconfig")
+	RegisterKubeFlags(rootCmd, kube)
+	rootCmd.PersistentFlags().Bool("verbose", false, "print the original error, as returned by the cluster, when a command fails")
 
 	function := Function()
 	function.AddCommand(
-		FunctionCreate(&client),
+		FunctionList(&kube.Client),
+		FunctionGet(&kube.Client),
+		FunctionDescribe(&kube.Client),
+		FunctionExport(&kube.Client),
+		FunctionCreate(&kube.Client),
+		FunctionCreateFromFile(&kube.Client),
+		FunctionBuild(&kube.Client),
+		FunctionUpdate(&kube.Client),
+		FunctionEdit(&kube.Client),
+		FunctionScale(&kube.Client),
+		FunctionClone(&kube.Client),
+		FunctionSubscribe(&kube.Client),
+		FunctionInvoke(&kube.Client),
+		FunctionLogs(&kube.Client),
+		FunctionDelete(&kube.Client),
 	)
 
 	service := Service()
 	service.AddCommand(
-		ServiceList(&client),
-		ServiceCreate(&client),
-		ServiceStatus(&client),
-		ServiceInvoke(&client),
-		ServiceSubscribe(&client),
-		ServiceDelete(&client),
+		ServiceList(&kube.Client),
+		ServiceCreate(&kube.Client),
+		ServiceStatus(&kube.Client),
+		ServiceInvoke(&kube.Client),
+		ServiceSubscribe(&kube.Client),
+		ServiceDelete(&kube.Client),
+	)
+
+	revision := Revision()
+	revision.AddCommand(
+		RevisionList(&kube.Client),
+		RevisionDelete(&kube.Client),
 	)
 
 	channel := Channel()
 	channel.AddCommand(
-		ChannelList(&client),
-		ChannelCreate(&client),
-		ChannelDelete(&client),
+		ChannelList(&kube.Client),
+		ChannelCreate(&kube.Client),
+		ChannelDelete(&kube.Client),
 	)
 
 	namespace := Namespace()
 	namespace.AddCommand(
-		NamespaceInit(&kc),
+		NamespaceInit(&kube.KubectlClient),
+		NamespaceCreate(&kube.Client),
+		NamespaceDelete(&kube.Client),
 	)
 
 	system := System()
 	system.AddCommand(
-		SystemInstall(&kc),
-		SystemUninstall(&kc),
+		SystemInstall(&kube.KubectlClient),
+		SystemUninstall(&kube.KubectlClient),
 	)
 
 	rootCmd.AddCommand(
 		function,
+		revision,
 		service,
 		channel,
 		namespace,
 		system,
+		Doctor(&kube.Client),
 		Docs(rootCmd),
+		Completion(rootCmd),
+		CompleteFunctionNamesCommand(&kube.Client),
 		Version(),
 	)
 
 	Visit(rootCmd, func(c *cobra.Command) error {
 		// Disable usage printing as soon as we enter RunE(), as errors that happen from then on
-		// are not mis-usage error, but "regular" runtime errors
+		// are not mis-usage error, but "regular" runtime errors. Those are translated to concise messages and exit
+		// codes scripts can rely on
 		exec := c.RunE
 		if exec != nil {
 			c.RunE = func(cmd *cobra.Command, args []string) error {
 				c.SilenceUsage = true
-				return exec(cmd, args)
+				return HandleError(exec(cmd, args))
 			}
 		}
 		return nil
@@ -0,0 +1,206 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package commands_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/cmd/commands"
+	"github.com/spf13/cobra"
+)
+
+const wiringKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- cluster: {server: "https://127.0.0.1:6443"}
+  name: local
+contexts:
+- context: {cluster: local, user: me}
+  name: dev
+- context: {cluster: local, user: me, namespace: team-b}
+  name: prod
+current-context: dev
+users:
+- name: me
+  user: {token: secret}
+`
+
+var _ = Describe("The riff root command", func() {
+
+	var (
+		rootCommand *cobra.Command
+		kubeconfig  string
+	)
+
+	BeforeEach(func() {
+		rootCommand = commands.CreateAndWireRootCommand()
+		rootCommand.SetOutput(&strings.Builder{})
+
+		f, err := ioutil.TempFile("", "kubeconfig")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		_, err = f.WriteString(wiringKubeconfig)
+		Expect(err).NotTo(HaveOccurred())
+		kubeconfig = f.Name()
+	})
+
+	AfterEach(func() {
+		os.Remove(kubeconfig)
+	})
+
+	It("should accept an existing context", func() {
+		rootCommand.SetArgs([]string{"version", "--kubeconfig", kubeconfig, "--context", "prod"})
+		err := rootCommand.Execute()
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should fail for a context that does not exist in the kubeconfig", func() {
+		rootCommand.SetArgs([]string{"version", "--kubeconfig", kubeconfig, "--context", "staging"})
+		err := rootCommand.Execute()
+		Expect(err).To(MatchError(`context "staging" does not exist in the kubeconfig, available contexts are [dev prod]`))
+	})
+
+	It("should honor $KUBECONFIG when no kubeconfig is provided", func() {
+		previous, set := os.LookupEnv("KUBECONFIG")
+		os.Setenv("KUBECONFIG", kubeconfig)
+		defer func() {
+			if set {
+				os.Setenv("KUBECONFIG", previous)
+			} else {
+				os.Unsetenv("KUBECONFIG")
+			}
+		}()
+
+		rootCommand.SetArgs([]string{"version", "--context", "staging"})
+		err := rootCommand.Execute()
+		Expect(err).To(MatchError(`context "staging" does not exist in the kubeconfig, available contexts are [dev prod]`))
+	})
+})
+
+var _ = Describe("The kubernetes flags", func() {
+
+	var (
+		parent  *cobra.Command
+		child   *cobra.Command
+		options *commands.KubeOptions
+	)
+
+	BeforeEach(func() {
+		parent = &cobra.Command{Use: "parent"}
+		child = &cobra.Command{Use: "child", RunE: func(cmd *cobra.Command, args []string) error { return nil }}
+		parent.AddCommand(child)
+		parent.SetOutput(&strings.Builder{})
+
+		options = &commands.KubeOptions{}
+		commands.RegisterKubeFlags(parent, options)
+	})
+
+	It("should be accepted by subcommands and build the clients", func() {
+		parent.SetArgs([]string{"child", "--kubeconfig", "/dev/null", "--master", "https://127.0.0.1:6443", "--context", ""})
+		err := parent.Execute()
+		Expect(err).NotTo(HaveOccurred())
+		Expect(options.Kubeconfig).To(Equal("/dev/null"))
+		Expect(options.MasterURL).To(Equal("https://127.0.0.1:6443"))
+		Expect(options.Client).NotTo(BeNil())
+		Expect(options.KubectlClient).NotTo(BeNil())
+	})
+
+	Context("with a kubeconfig", func() {
+
+		var kubeconfig string
+
+		BeforeEach(func() {
+			f, err := ioutil.TempFile("", "kubeconfig")
+			Expect(err).NotTo(HaveOccurred())
+			defer f.Close()
+			_, err = f.WriteString(wiringKubeconfig)
+			Expect(err).NotTo(HaveOccurred())
+			kubeconfig = f.Name()
+		})
+
+		AfterEach(func() {
+			os.Remove(kubeconfig)
+		})
+
+		It("should default to the namespace of the given context", func() {
+			parent.SetArgs([]string{"child", "--kubeconfig", kubeconfig, "--context", "prod"})
+			err := parent.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(options.DefaultNamespace).To(Equal("team-b"))
+		})
+
+		It("should default to the namespace of the current context of $KUBECONFIG", func() {
+			previous, set := os.LookupEnv("KUBECONFIG")
+			os.Setenv("KUBECONFIG", kubeconfig)
+			defer func() {
+				if set {
+					os.Setenv("KUBECONFIG", previous)
+				} else {
+					os.Unsetenv("KUBECONFIG")
+				}
+			}()
+
+			parent.SetArgs([]string{"child"})
+			err := parent.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(options.DefaultNamespace).To(Equal("default"))
+		})
+
+		It("should set the namespace flag of the command to the default namespace when not given", func() {
+			namespace := ""
+			child.Flags().StringVarP(&namespace, "namespace", "n", "", "the `namespace`")
+
+			parent.SetArgs([]string{"child", "--kubeconfig", kubeconfig, "--context", "prod"})
+			err := parent.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespace).To(Equal("team-b"))
+			Expect(child.Flags().Changed("namespace")).To(BeFalse())
+		})
+
+		It("should leave a given namespace flag alone", func() {
+			namespace := ""
+			child.Flags().StringVarP(&namespace, "namespace", "n", "", "the `namespace`")
+
+			parent.SetArgs([]string{"child", "--kubeconfig", kubeconfig, "--context", "prod", "--namespace", "team-a"})
+			err := parent.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespace).To(Equal("team-a"))
+		})
+
+		It("should leave namespace flags the command defaults itself alone", func() {
+			namespace := ""
+			child.Flags().StringVarP(&namespace, "namespace", "n", "", "the `namespace`")
+			child.Flags().SetAnnotation("namespace", commands.NamespaceDefaultedByCommandAnnotation, []string{"true"})
+
+			parent.SetArgs([]string{"child", "--kubeconfig", kubeconfig, "--context", "prod"})
+			err := parent.Execute()
+			Expect(err).NotTo(HaveOccurred())
+			Expect(namespace).To(BeEmpty())
+		})
+	})
+
+	It("should not build clients when the kubeconfig is invalid", func() {
+		parent.SetArgs([]string{"child", "--kubeconfig", "/dev/null", "--context", "staging"})
+		err := parent.Execute()
+		Expect(err).To(HaveOccurred())
+		Expect(options.Client).To(BeNil())
+	})
+})
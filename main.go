@@ -31,6 +31,10 @@ func main() {
 	err := root.Execute()
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
-		os.Exit(1)
+		exitErr, ok := err.(*commands.ExitError)
+		if verbose, _ := root.PersistentFlags().GetBool("verbose"); verbose && ok {
+			fmt.Fprintf(os.Stderr, "Cause: %v\n", exitErr.Cause)
+		}
+		os.Exit(commands.ExitCode(err))
 	}
 }
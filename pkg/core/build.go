@@ -0,0 +1,209 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	build "github.com/knative/build/pkg/apis/build/v1alpha1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+const (
+	// buildAPIPath is the root of the knative build API. There is no typed client for it, so builds are handled
+	// with raw REST calls.
+	buildAPIPath = "/apis/build.knative.dev/v1alpha1"
+
+	// defaultBuildServiceAccount is the service account builds run as unless configured otherwise. It is created by
+	// `riff namespace init`, referencing the secret with the credentials to push images.
+	defaultBuildServiceAccount = "riff-build"
+
+	// buildDockerSecretAnnotationPrefix prefixes the annotations knative build reads to tell which registry a secret
+	// of a build service account holds credentials for, e.g. build.knative.dev/docker-0: https://gcr.io
+	buildDockerSecretAnnotationPrefix = "build.knative.dev/docker-"
+)
+
+type BuildFunctionOptions struct {
+	Namespaced
+	Name          string
+	GitRepo       string
+	GitRevision   string
+	Image         string
+	BuildTemplate string
+	// BuildArgs are additional arguments of the build template, keyed by name
+	BuildArgs map[string]string
+	// BuildEnv are environment variables set in all the steps of the build template
+	BuildEnv []core_v1.EnvVar
+	// BuildServiceAccount is the service account the build runs as, which must reference a secret with credentials
+	// for the registry the image is pushed to. Defaults to the one created by `riff namespace init`.
+	BuildServiceAccount string
+	// VerifyBuildServiceAccount makes the build fail early if the BuildServiceAccount doesn't exist in the namespace,
+	// or has no credentials for the registry of the image
+	VerifyBuildServiceAccount bool
+}
+
+// BuildFunction creates a knative Build producing the function image from sources in a git repository, using the
+// given build template. The template must already be installed in the namespace. The created Build is returned so
+// that its status can be watched.
+func (c *client) BuildFunction(options BuildFunctionOptions) (*build.Build, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	arguments, err := buildArguments(options.Image, options.BuildArgs)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateBuildEnv(options.BuildEnv); err != nil {
+		return nil, err
+	}
+
+	serviceAccount, err := buildServiceAccount(options.BuildServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := c.checkBuildTemplate(ns, options.BuildTemplate); err != nil {
+		return nil, err
+	}
+	if options.VerifyBuildServiceAccount {
+		if err := c.checkBuildServiceAccount(ns, serviceAccount, options.Image); err != nil {
+			return nil, err
+		}
+	}
+
+	b := build.Build{
+		TypeMeta: meta_v1.TypeMeta{
+			APIVersion: "build.knative.dev/v1alpha1",
+			Kind:       "Build",
+		},
+		ObjectMeta: meta_v1.ObjectMeta{
+			GenerateName: options.Name + "-",
+			Labels:       map[string]string{functionLabel: options.Name},
+		},
+		Spec: build.BuildSpec{
+			ServiceAccountName: serviceAccount,
+			Source: &build.SourceSpec{
+				Git: &build.GitSourceSpec{
+					Url:      options.GitRepo,
+					Revision: options.GitRevision,
+				},
+			},
+			Template: &build.TemplateInstantiationSpec{
+				Name:      options.BuildTemplate,
+				Arguments: arguments,
+				Env:       options.BuildEnv,
+			},
+		},
+	}
+
+	body, err := json.Marshal(b)
+	if err != nil {
+		return nil, err
+	}
+	restClient := c.kubeClient.Discovery().RESTClient()
+	result, err := restClient.Post().AbsPath(buildAPIPath, "namespaces", ns, "builds").
+		SetHeader("Content-Type", "application/json").Body(body).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+
+	created := build.Build{}
+	err = json.Unmarshal(result, &created)
+	return &created, err
+}
+
+// checkBuildTemplate makes sure the build template is installed in the namespace, so that builds don't get stuck.
+func (c *client) checkBuildTemplate(ns string, template string) error {
+	restClient := c.kubeClient.Discovery().RESTClient()
+	_, err := restClient.Get().AbsPath(buildAPIPath, "namespaces", ns, "buildtemplates", template).DoRaw()
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("build template %q is not installed in namespace %q", template, ns)
+	}
+	return err
+}
+
+// buildServiceAccount returns the service account a build runs as, the default one unless name is given.
+func buildServiceAccount(name string) (string, error) {
+	if name == "" {
+		return defaultBuildServiceAccount, nil
+	}
+	if msgs := validation.IsDNS1123Subdomain(name); len(msgs) > 0 {
+		return "", fmt.Errorf("invalid build service account name %q: %s", name, strings.Join(msgs, ", "))
+	}
+	return name, nil
+}
+
+// checkBuildServiceAccount makes sure the service account a build runs as exists, and references a secret knative
+// build uses to authenticate to the registry of the image, so that the build doesn't fail once the image is built.
+func (c *client) checkBuildServiceAccount(ns string, serviceAccount string, image string) error {
+	sa, err := c.kubeClient.CoreV1().ServiceAccounts(ns).Get(serviceAccount, meta_v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("build service account %q does not exist in namespace %q", serviceAccount, ns)
+	} else if err != nil {
+		return err
+	}
+
+	registry, _, _ := splitImageReference(image)
+	for _, ref := range sa.Secrets {
+		secret, err := c.kubeClient.CoreV1().Secrets(ns).Get(ref.Name, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			continue
+		} else if err != nil {
+			return err
+		}
+		for k, v := range secret.Annotations {
+			if strings.HasPrefix(k, buildDockerSecretAnnotationPrefix) && configKeyRegistry(v) == registry {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("build service account %q in namespace %q has no credentials for registry %s, so the image can't be pushed; "+
+		"reference a secret annotated with %s<n>: <registry url> from the service account", serviceAccount, ns, registry,
+		buildDockerSecretAnnotationPrefix)
+}
+
+// buildArguments returns the arguments of the build template, the image to build coming first and the other ones
+// sorted by name. The image argument can't be overridden.
+func buildArguments(image string, buildArgs map[string]string) ([]build.ArgumentSpec, error) {
+	arguments := []build.ArgumentSpec{{Name: buildImageArgument, Value: image}}
+	for _, name := range sortedKeys(buildArgs) {
+		if name == buildImageArgument {
+			return nil, fmt.Errorf("build argument %s can't be set, it is the image to build", buildImageArgument)
+		}
+		if msgs := validation.IsEnvVarName(name); len(msgs) > 0 {
+			return nil, fmt.Errorf("invalid build argument name %q: %s", name, strings.Join(msgs, ", "))
+		}
+		arguments = append(arguments, build.ArgumentSpec{Name: name, Value: buildArgs[name]})
+	}
+	return arguments, nil
+}
+
+// validateBuildEnv rejects environment variables set more than once, as only one of them would be used by the build.
+func validateBuildEnv(env []core_v1.EnvVar) error {
+	seen := map[string]bool{}
+	for _, e := range env {
+		if seen[e.Name] {
+			return fmt.Errorf("build environment variable %q is set more than once", e.Name)
+		}
+		seen[e.Name] = true
+	}
+	return nil
+}
@@ -0,0 +1,188 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	build "github.com/knative/build/pkg/apis/build/v1alpha1"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/api/core/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Building functions", func() {
+
+	var (
+		client  core.Client
+		options core.BuildFunctionOptions
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		options = core.BuildFunctionOptions{
+			Namespaced:    core.Namespaced{Namespace: "ns"},
+			Name:          "square",
+			GitRepo:       "https://github.com/repo",
+			Image:         "foo/bar",
+			BuildTemplate: "kaniko",
+		}
+	})
+
+	It("should reject invalid build argument names", func() {
+		options.BuildArgs = map[string]string{"NOT VALID": "value"}
+
+		_, err := client.BuildFunction(options)
+		Expect(err).To(MatchError(HavePrefix(`invalid build argument name "NOT VALID": `)))
+	})
+
+	It("should not let the image argument be overridden", func() {
+		options.BuildArgs = map[string]string{"IMAGE": "foo/baz"}
+
+		_, err := client.BuildFunction(options)
+		Expect(err).To(MatchError("build argument IMAGE can't be set, it is the image to build"))
+	})
+
+	It("should reject duplicate build environment variables", func() {
+		options.BuildEnv = []v1.EnvVar{{Name: "GOFLAGS", Value: "-mod=vendor"}, {Name: "GOFLAGS", Value: ""}}
+
+		_, err := client.BuildFunction(options)
+		Expect(err).To(MatchError(`build environment variable "GOFLAGS" is set more than once`))
+	})
+})
+
+var _ = Describe("Building functions with a build service account", func() {
+
+	var (
+		server   *httptest.Server
+		created  *build.Build
+		client   core.Client
+		options  core.BuildFunctionOptions
+		accounts map[string]string
+		secrets  map[string]string
+	)
+
+	BeforeEach(func() {
+		created = nil
+		accounts = map[string]string{
+			"pusher":    `{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"pusher"},"secrets":[{"name":"missing"},{"name":"gcr"},{"name":"hub"}]}`,
+			"anonymous": `{"apiVersion":"v1","kind":"ServiceAccount","metadata":{"name":"anonymous"}}`,
+		}
+		secrets = map[string]string{
+			"gcr": `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"gcr","annotations":{"build.knative.dev/docker-0":"https://gcr.io"}}}`,
+			"hub": `{"apiVersion":"v1","kind":"Secret","metadata":{"name":"hub","annotations":{"build.knative.dev/docker-0":"https://index.docker.io/v1/"}}}`,
+		}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/apis/build.knative.dev/v1alpha1/namespaces/ns/buildtemplates/kaniko":
+				fmt.Fprint(w, `{"apiVersion":"build.knative.dev/v1alpha1","kind":"BuildTemplate","metadata":{"name":"kaniko"}}`)
+				return
+			case "/apis/build.knative.dev/v1alpha1/namespaces/ns/builds":
+				body, _ := ioutil.ReadAll(r.Body)
+				created = &build.Build{}
+				Expect(json.Unmarshal(body, created)).To(Succeed())
+				w.Write(body)
+				return
+			}
+			var found string
+			if _, err := fmt.Sscanf(r.URL.Path, "/api/v1/namespaces/ns/serviceaccounts/%s", &found); err == nil && accounts[found] != "" {
+				fmt.Fprint(w, accounts[found])
+				return
+			}
+			if _, err := fmt.Sscanf(r.URL.Path, "/api/v1/namespaces/ns/secrets/%s", &found); err == nil && secrets[found] != "" {
+				fmt.Fprint(w, secrets[found])
+				return
+			}
+			w.WriteHeader(http.StatusNotFound)
+			fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+		}))
+		kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, nil)
+		options = core.BuildFunctionOptions{
+			Namespaced:    core.Namespaced{Namespace: "ns"},
+			Name:          "square",
+			GitRepo:       "https://github.com/repo",
+			Image:         "gcr.io/acme/square",
+			BuildTemplate: "kaniko",
+		}
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should run as riff-build by default", func() {
+		_, err := client.BuildFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Spec.ServiceAccountName).To(Equal("riff-build"))
+	})
+
+	It("should run as the given service account, once verified", func() {
+		options.BuildServiceAccount = "pusher"
+		options.VerifyBuildServiceAccount = true
+
+		_, err := client.BuildFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Spec.ServiceAccountName).To(Equal("pusher"))
+
+		options.Image = "acme/square"
+		_, err = client.BuildFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+	})
+
+	It("should reject invalid service account names", func() {
+		options.BuildServiceAccount = "Pusher"
+
+		_, err := client.BuildFunction(options)
+		Expect(err).To(MatchError(HavePrefix(`invalid build service account name "Pusher": `)))
+		Expect(created).To(BeNil())
+	})
+
+	It("should fail for a missing service account when verifying it", func() {
+		options.BuildServiceAccount = "ghost"
+		options.VerifyBuildServiceAccount = true
+
+		_, err := client.BuildFunction(options)
+		Expect(err).To(MatchError(`build service account "ghost" does not exist in namespace "ns"`))
+		Expect(created).To(BeNil())
+	})
+
+	It("should fail for a service account without credentials for the registry", func() {
+		options.BuildServiceAccount = "pusher"
+		options.VerifyBuildServiceAccount = true
+		options.Image = "registry.example.com/acme/square"
+
+		_, err := client.BuildFunction(options)
+		Expect(err).To(MatchError(`build service account "pusher" in namespace "ns" has no credentials for registry registry.example.com, ` +
+			`so the image can't be pushed; reference a secret annotated with build.knative.dev/docker-<n>: <registry url> from the service account`))
+		Expect(created).To(BeNil())
+
+		options.BuildServiceAccount = "anonymous"
+		options.Image = "gcr.io/acme/square"
+		_, err = client.BuildFunction(options)
+		Expect(err).To(MatchError(HavePrefix(`build service account "anonymous" in namespace "ns" has no credentials for registry gcr.io`)))
+	})
+})
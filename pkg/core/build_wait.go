@@ -0,0 +1,305 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	build "github.com/knative/build/pkg/apis/build/v1alpha1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	typed_core_v1 "k8s.io/client-go/kubernetes/typed/core/v1"
+)
+
+// buildStepContainerPrefix prefixes the names of the init containers knative build runs the steps of a build in, one
+// after the other, in the build pod
+const buildStepContainerPrefix = "build-step-"
+
+type WaitForBuildOptions struct {
+	Namespaced
+	// Name is the name of the build, as returned by BuildFunction
+	Name string
+	// Function is the name of the function the build is for, which errors refer to
+	Function string
+	// PollInterval is how often the build is looked up, 1s if zero
+	PollInterval time.Duration
+	// Logs receives the logs of the steps of the build as they run, followed by a line telling the build succeeded
+	Logs io.Writer
+	// Timeout is how long the build is waited for, if positive, before giving up with a BuildTimeoutError. Otherwise
+	// the build is waited for as long as ctx allows.
+	Timeout time.Duration
+}
+
+// WaitForBuild waits for a build created by BuildFunction to succeed, or ctx to be done. A build that fails is reported
+// as a BuildFailedError, one that doesn't complete within the timeout as a BuildTimeoutError.
+func (c *client) WaitForBuild(ctx context.Context, options WaitForBuildOptions) error {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	interval := options.PollInterval
+	if interval == 0 {
+		interval = functionReadyPollInterval
+	} else if interval < 0 {
+		return fmt.Errorf("poll interval must be positive, got %v", interval)
+	}
+	if options.Timeout < 0 {
+		return fmt.Errorf("build timeout must be positive, got %v", options.Timeout)
+	}
+	return withBuildTimeout(ctx, ns, options.Function, options.Timeout, func(ctx context.Context) error {
+		return c.followBuild(ctx, ns, options.Function, options.Name, interval, options.Logs)
+	})
+}
+
+// waitForFunctionBuild waits for the build of the latest revision of a function to succeed, copying the logs of its
+// steps to logs as they run, and then its success, unless nil. A build that fails is reported as a BuildFailedError,
+// one that doesn't complete within the timeout, if positive, as a BuildTimeoutError.
+func (c *client) waitForFunctionBuild(ctx context.Context, ns string, name string, interval time.Duration, timeout time.Duration, logs io.Writer) error {
+	return withBuildTimeout(ctx, ns, name, timeout, func(ctx context.Context) error {
+		buildName, err := c.functionBuildName(ctx, ns, name, interval)
+		if err != nil {
+			return err
+		}
+		return c.followBuild(ctx, ns, name, buildName, interval, logs)
+	})
+}
+
+// withBuildTimeout runs follow with a context also done once the timeout, if positive, elapsed. Giving up then is
+// reported as a BuildTimeoutError, and giving up as ctx is done as a WaitError.
+func withBuildTimeout(ctx context.Context, ns string, function string, timeout time.Duration, follow func(ctx context.Context) error) error {
+	buildCtx, cancel := ctx, context.CancelFunc(func() {})
+	if timeout > 0 {
+		buildCtx, cancel = context.WithTimeout(ctx, timeout)
+	}
+	defer cancel()
+
+	err := follow(buildCtx)
+	if err != nil && err == buildCtx.Err() {
+		if ctx.Err() == nil {
+			return &BuildTimeoutError{Function: function, Namespace: ns, Timeout: timeout}
+		}
+		return &WaitError{Message: fmt.Sprintf("build of function %q in namespace %q did not complete", function, ns), Cause: ctx.Err()}
+	}
+	return err
+}
+
+// followBuild waits for a build to succeed, copying the logs of its steps to logs as they run, unless nil.
+func (c *client) followBuild(ctx context.Context, ns string, name string, buildName string, interval time.Duration, logs io.Writer) error {
+	if logs != nil {
+		pod, err := c.buildPod(ctx, ns, buildName, interval)
+		if err != nil {
+			return err
+		}
+		if pod != "" {
+			if err := c.streamBuildLogs(ctx, ns, pod, interval, logs); err != nil {
+				return err
+			}
+		}
+	}
+
+	var b *build.Build
+	err := WaitForCondition(ctx, interval, func() (meta_v1.Object, []ConditionDescription, error) {
+		var err error
+		if b, err = c.getBuild(ns, buildName); err != nil {
+			return nil, nil, err
+		}
+		return b, buildConditions(b), nil
+	}, func(conditions []ConditionDescription) (bool, error) {
+		for _, cond := range conditions {
+			if cond.Type != string(build.BuildSucceeded) {
+				continue
+			}
+			switch core_v1.ConditionStatus(cond.Status) {
+			case core_v1.ConditionTrue:
+				return true, nil
+			case core_v1.ConditionFalse:
+				return false, &BuildFailedError{Function: name, Namespace: ns, Build: buildName,
+					Step: c.failedBuildStep(ns, b), Reason: cond.Reason, Message: cond.Message}
+			}
+		}
+		return false, nil
+	})
+	if err == nil && logs != nil {
+		fmt.Fprintf(logs, "Build %q of function %q succeeded\n", buildName, name)
+	}
+	return err
+}
+
+// functionBuildName returns the name of the build of the latest revision of a function, once knative created it.
+func (c *client) functionBuildName(ctx context.Context, ns string, name string, interval time.Duration) (string, error) {
+	var buildName string
+	err := pollUntilDone(ctx, interval, func() (bool, error) {
+		s, err := c.function(Namespaced{Namespace: ns}, name)
+		if err != nil {
+			return false, err
+		}
+		configuration, err := ServiceConfiguration(s)
+		if err != nil {
+			return false, err
+		}
+		if configuration.Build == nil {
+			return false, fmt.Errorf("function %q is not built from source, it has no build to wait for", name)
+		}
+		revision := s.Status.LatestCreatedRevisionName
+		if revision == "" {
+			return false, nil
+		}
+		r, err := c.serving.ServingV1alpha1().Revisions(ns).Get(revision, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return false, nil
+		} else if err != nil {
+			return false, err
+		}
+		buildName = r.Spec.BuildName
+		return buildName != "", nil
+	})
+	return buildName, err
+}
+
+// buildPod returns the name of the pod running the steps of a build, once scheduled. It is empty if the build
+// completed without one, e.g. because it is invalid.
+func (c *client) buildPod(ctx context.Context, ns string, name string, interval time.Duration) (string, error) {
+	var podName string
+	err := pollUntilDone(ctx, interval, func() (bool, error) {
+		b, err := c.getBuild(ns, name)
+		if err != nil {
+			return false, err
+		}
+		if b.Status.Cluster != nil && b.Status.Cluster.PodName != "" {
+			podName = b.Status.Cluster.PodName
+			return true, nil
+		}
+		cond := b.Status.GetCondition(build.BuildSucceeded)
+		return cond != nil && cond.Status != core_v1.ConditionUnknown, nil
+	})
+	return podName, err
+}
+
+// streamBuildLogs copies the logs of each step of a build to w, in order and as they run, each line prefixed with the
+// name of the step. It returns once the last step ran, or as soon as the build pod stopped before running a step.
+func (c *client) streamBuildLogs(ctx context.Context, ns string, podName string, interval time.Duration, w io.Writer) error {
+	pods := c.kubeClient.CoreV1().Pods(ns)
+	pod, err := pods.Get(podName, meta_v1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	for i, container := range pod.Spec.InitContainers {
+		started, err := waitForBuildStep(ctx, pods, podName, i, interval)
+		if err != nil || !started {
+			return err
+		}
+		stream, err := pods.GetLogs(podName, &core_v1.PodLogOptions{Container: container.Name, Follow: true}).Stream()
+		if err != nil {
+			return err
+		}
+		if err := copyBuildStepLogs(ctx, stream, strings.TrimPrefix(container.Name, buildStepContainerPrefix), w); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// waitForBuildStep waits for the step of the build running as the init container at the given index to start. It
+// returns false if the pod stopped before.
+func waitForBuildStep(ctx context.Context, pods typed_core_v1.PodInterface, podName string, step int, interval time.Duration) (bool, error) {
+	started := false
+	err := pollUntilDone(ctx, interval, func() (bool, error) {
+		pod, err := pods.Get(podName, meta_v1.GetOptions{})
+		if err != nil {
+			return false, err
+		}
+		if statuses := pod.Status.InitContainerStatuses; step < len(statuses) {
+			if state := statuses[step].State; state.Running != nil || state.Terminated != nil {
+				started = true
+				return true, nil
+			}
+		}
+		return pod.Status.Phase == core_v1.PodFailed || pod.Status.Phase == core_v1.PodSucceeded, nil
+	})
+	return started, err
+}
+
+// copyBuildStepLogs copies the log lines of a build step to w until the step ends, or ctx is done.
+func copyBuildStepLogs(ctx context.Context, stream io.ReadCloser, step string, w io.Writer) error {
+	defer stream.Close()
+	copied := make(chan struct{})
+	defer close(copied)
+	go func() {
+		select {
+		case <-ctx.Done():
+			stream.Close()
+		case <-copied:
+		}
+	}()
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		if _, err := fmt.Fprintf(w, "[%s] %s\n", step, scanner.Text()); err != nil {
+			return err
+		}
+	}
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return scanner.Err()
+}
+
+// failedBuildStep returns the name of the step a build failed at, or an empty string if it can't be told.
+func (c *client) failedBuildStep(ns string, b *build.Build) string {
+	if b.Status.Cluster == nil || b.Status.Cluster.PodName == "" {
+		return ""
+	}
+	pod, err := c.kubeClient.CoreV1().Pods(ns).Get(b.Status.Cluster.PodName, meta_v1.GetOptions{})
+	if err != nil {
+		return ""
+	}
+	for _, status := range pod.Status.InitContainerStatuses {
+		if status.State.Terminated != nil && status.State.Terminated.ExitCode != 0 {
+			return strings.TrimPrefix(status.Name, buildStepContainerPrefix)
+		}
+	}
+	return ""
+}
+
+func (c *client) getBuild(ns string, name string) (*build.Build, error) {
+	restClient := c.kubeClient.Discovery().RESTClient()
+	result, err := restClient.Get().AbsPath(buildAPIPath, "namespaces", ns, "builds", name).DoRaw()
+	if err != nil {
+		return nil, err
+	}
+	b := &build.Build{}
+	err = json.Unmarshal(result, b)
+	return b, err
+}
+
+func buildConditions(b *build.Build) []ConditionDescription {
+	var conditions []ConditionDescription
+	for _, cond := range b.Status.Conditions {
+		conditions = append(conditions, ConditionDescription{
+			Type:    string(cond.Type),
+			Status:  string(cond.Status),
+			Reason:  cond.Reason,
+			Message: cond.Message,
+		})
+	}
+	return conditions
+}
@@ -0,0 +1,193 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Waiting for the build of a function", func() {
+
+	var (
+		server *httptest.Server
+		// fromSource tells whether the function is built from a git repository
+		fromSource bool
+		// buildStatus and exitCode are the status of the Succeeded condition of the build, and the exit code of its
+		// last step
+		buildStatus string
+		exitCode    int
+		client      core.Client
+		options     core.WaitForFunctionReadyOptions
+	)
+
+	BeforeEach(func() {
+		fromSource = true
+		buildStatus = "True"
+		exitCode = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square":
+				build := ""
+				if fromSource {
+					build = `"build":{"template":{"name":"riff"}},`
+				}
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns","uid":"1"},`+
+					`"spec":{"runLatest":{"configuration":{%s"revisionTemplate":{"spec":{"container":{"image":"acme/square"}}}}}},`+
+					`"status":{"latestCreatedRevisionName":"square-00001","conditions":[{"type":"Ready","status":"True"}]}}`, build)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/revisions/square-00001":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Revision","metadata":{"name":"square-00001"},"spec":{"buildName":"square-00001"}}`)
+			case "/apis/build.knative.dev/v1alpha1/namespaces/ns/builds/square-00001":
+				fmt.Fprintf(w, `{"apiVersion":"build.knative.dev/v1alpha1","kind":"Build","metadata":{"name":"square-00001","uid":"2"},`+
+					`"status":{"cluster":{"podName":"square-00001-abc"},"conditions":[{"state":"Succeeded","status":"%s","reason":"BuildFailed","message":"step exited with code 1"}]}}`, buildStatus)
+			case "/api/v1/namespaces/ns/pods/square-00001-abc":
+				fmt.Fprintf(w, `{"apiVersion":"v1","kind":"Pod","metadata":{"name":"square-00001-abc"},`+
+					`"spec":{"initContainers":[{"name":"build-step-git-source"},{"name":"build-step-build"}],"containers":[{"name":"nop"}]},`+
+					`"status":{"phase":"Succeeded","initContainerStatuses":[`+
+					`{"name":"build-step-git-source","state":{"terminated":{"exitCode":0}}},`+
+					`{"name":"build-step-build","state":{"terminated":{"exitCode":%d}}}]}}`, exitCode)
+			case "/api/v1/namespaces/ns/pods/square-00001-abc/log":
+				w.Header().Set("Content-Type", "text/plain")
+				fmt.Fprintf(w, "running %s\n", strings.TrimPrefix(r.URL.Query().Get("container"), "build-step-"))
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.WaitForFunctionReadyOptions{Name: "square", PollInterval: 10 * time.Millisecond, WaitForBuild: true}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should show the logs of each step before waiting for the function", func() {
+		logs := &strings.Builder{}
+		options.BuildLogs = logs
+
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(logs.String()).To(Equal("[git-source] running git-source\n[build] running build\n" +
+			"Build \"square-00001\" of function \"square\" succeeded\n"))
+	})
+
+	It("should report the step a build failed at", func() {
+		buildStatus = "False"
+		exitCode = 1
+
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).To(MatchError(`build "square-00001" of function "square" failed at step "build": step exited with code 1`))
+		Expect(err).To(BeAssignableToTypeOf(&core.BuildFailedError{}))
+	})
+
+	It("should give up when the build doesn't complete in time", func() {
+		buildStatus = "Unknown"
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := client.WaitForFunctionReady(ctx, options)
+		Expect(err).To(MatchError(`build of function "square" in namespace "ns" did not complete: context deadline exceeded`))
+		Expect(err.(*core.WaitError).Cause).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("should report a build not completing within the build timeout", func() {
+		buildStatus = "Unknown"
+		options.BuildTimeout = 50 * time.Millisecond
+
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).To(MatchError(`build of function "square" in namespace "ns" did not complete within 50ms`))
+		Expect(err).To(BeAssignableToTypeOf(&core.BuildTimeoutError{}))
+	})
+
+	It("should reject a negative build timeout", func() {
+		options.BuildTimeout = -time.Second
+
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).To(MatchError("build timeout must be positive, got -1s"))
+	})
+
+	It("should fail for a function that is not built from source", func() {
+		fromSource = false
+
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).To(MatchError(`function "square" is not built from source, it has no build to wait for`))
+	})
+
+	Context("when waiting for a build on its own", func() {
+
+		var buildOptions core.WaitForBuildOptions
+
+		BeforeEach(func() {
+			buildOptions = core.WaitForBuildOptions{Name: "square-00001", Function: "square", PollInterval: 10 * time.Millisecond}
+			buildOptions.Namespace = "ns"
+		})
+
+		It("should show the logs of each step until the build succeeds", func() {
+			logs := &strings.Builder{}
+			buildOptions.Logs = logs
+
+			err := client.WaitForBuild(context.Background(), buildOptions)
+			Expect(err).NotTo(HaveOccurred())
+			Expect(logs.String()).To(Equal("[git-source] running git-source\n[build] running build\n" +
+				"Build \"square-00001\" of function \"square\" succeeded\n"))
+		})
+
+		It("should report the step a build failed at", func() {
+			buildStatus = "False"
+			exitCode = 1
+
+			err := client.WaitForBuild(context.Background(), buildOptions)
+			Expect(err).To(MatchError(`build "square-00001" of function "square" failed at step "build": step exited with code 1`))
+		})
+
+		It("should report a build not completing within the timeout", func() {
+			buildStatus = "Unknown"
+			buildOptions.Timeout = 50 * time.Millisecond
+
+			err := client.WaitForBuild(context.Background(), buildOptions)
+			Expect(err).To(MatchError(`build of function "square" in namespace "ns" did not complete within 50ms`))
+			Expect(err).To(BeAssignableToTypeOf(&core.BuildTimeoutError{}))
+		})
+
+		It("should reject a negative timeout", func() {
+			buildOptions.Timeout = -time.Second
+
+			err := client.WaitForBuild(context.Background(), buildOptions)
+			Expect(err).To(MatchError("build timeout must be positive, got -1s"))
+		})
+	})
+})
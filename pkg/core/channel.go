@@ -18,16 +18,20 @@ package core
 
 import (
 	"github.com/knative/eventing/pkg/apis/channels/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 type ListChannelOptions struct {
 	Namespaced
+	// LabelSelector, if not empty, restricts the listed channels to those matching it, e.g. to the channels owned
+	// by a team or an application
+	LabelSelector string
 }
 
 func (c *client) ListChannels(options ListChannelOptions) (*v1alpha1.ChannelList, error) {
 	ns := c.explicitOrConfigNamespace(options.Namespaced)
-	return c.eventing.ChannelsV1alpha1().Channels(ns).List(meta_v1.ListOptions{})
+	return c.eventing.ChannelsV1alpha1().Channels(ns).List(meta_v1.ListOptions{LabelSelector: options.LabelSelector})
 }
 
 type CreateChannelOptions struct {
@@ -65,12 +69,32 @@ func (c *client) CreateChannel(options CreateChannelOptions) (*v1alpha1.Channel,
 type DeleteChannelOptions struct {
 	Namespaced
 	Name string
+	// Cascade also deletes the subscriptions to the channel, which would otherwise be left dangling
+	Cascade bool
 }
 
+// DeleteChannel deletes a channel and, if asked to, the subscriptions to it. Subscriptions are deleted last, so that
+// they are left untouched if the channel can't be deleted.
 func (c *client) DeleteChannel(options DeleteChannelOptions) error {
 	ns := c.explicitOrConfigNamespace(options.Namespaced)
 
 	err := c.eventing.ChannelsV1alpha1().Channels(ns).Delete(options.Name, nil)
+	if err != nil || !options.Cascade {
+		return err
+	}
 
-	return err
+	subscriptions := c.eventing.ChannelsV1alpha1().Subscriptions(ns)
+	list, err := subscriptions.List(meta_v1.ListOptions{})
+	if err != nil {
+		return err
+	}
+	for _, subscription := range list.Items {
+		if subscription.Spec.Channel != options.Name {
+			continue
+		}
+		if err := subscriptions.Delete(subscription.Name, nil); err != nil && !errors.IsNotFound(err) {
+			return err
+		}
+	}
+	return nil
 }
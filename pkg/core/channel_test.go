@@ -0,0 +1,146 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	eventing "github.com/knative/eventing/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Deleting channels", func() {
+
+	const channelsPath = "/apis/channels.knative.dev/v1alpha1/namespaces/ns/"
+
+	var (
+		server   *httptest.Server
+		requests []string
+		// channelMissing makes the channel not exist
+		channelMissing bool
+		// failures maps the subscriptions failing to be deleted to the status code they fail with
+		failures map[string]int
+		client   core.Client
+		options  core.DeleteChannelOptions
+	)
+
+	status := func(w http.ResponseWriter, code int, reason string) {
+		w.WriteHeader(code)
+		fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"%s","code":%d}`, reason, code)
+	}
+
+	BeforeEach(func() {
+		requests = []string{}
+		channelMissing = false
+		failures = map[string]int{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			requests = append(requests, r.Method+" "+strings.TrimPrefix(r.URL.Path, channelsPath))
+			switch {
+			case r.Method == http.MethodDelete && r.URL.Path == channelsPath+"channels/numbers" && channelMissing:
+				status(w, http.StatusNotFound, "NotFound")
+			case r.Method == http.MethodGet && r.URL.Path == channelsPath+"subscriptions":
+				items := []string{}
+				for name, channel := range map[string]string{"square": "numbers", "upper": "letters", "cube": "numbers", "half": "numbers"} {
+					items = append(items, fmt.Sprintf(`{"metadata":{"name":"%s","namespace":"ns"},"spec":{"channel":"%s","subscriber":"%s"}}`, name, channel, name))
+				}
+				fmt.Fprintf(w, `{"apiVersion":"channels.knative.dev/v1alpha1","kind":"SubscriptionList","items":[%s]}`, strings.Join(items, ","))
+			case r.Method == http.MethodDelete && strings.HasPrefix(r.URL.Path, channelsPath+"subscriptions/"):
+				switch code := failures[strings.TrimPrefix(r.URL.Path, channelsPath+"subscriptions/")]; code {
+				case 0:
+					fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+				case http.StatusNotFound:
+					status(w, code, "NotFound")
+				default:
+					status(w, code, "InternalError")
+				}
+			case r.Method == http.MethodDelete:
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			default:
+				status(w, http.StatusNotFound, "NotFound")
+			}
+		}))
+		eventingClient, err := eventing.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, eventingClient, nil)
+
+		options = core.DeleteChannelOptions{Name: "numbers", Cascade: true}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	// deletedSubscriptions returns the names of the subscriptions a delete request was made for, sorted
+	deletedSubscriptions := func() []string {
+		names := []string{}
+		for _, request := range requests {
+			if strings.HasPrefix(request, "DELETE subscriptions/") {
+				names = append(names, strings.TrimPrefix(request, "DELETE subscriptions/"))
+			}
+		}
+		return names
+	}
+
+	It("should only delete the channel unless cascading", func() {
+		options.Cascade = false
+
+		err := client.DeleteChannel(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{"DELETE channels/numbers"}))
+	})
+
+	It("should delete the subscriptions to the channel when cascading", func() {
+		err := client.DeleteChannel(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests[0]).To(Equal("DELETE channels/numbers"))
+		Expect(deletedSubscriptions()).To(ConsistOf("square", "cube", "half"))
+	})
+
+	It("should ignore subscriptions that are already gone", func() {
+		failures["square"] = http.StatusNotFound
+
+		err := client.DeleteChannel(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(deletedSubscriptions()).To(ConsistOf("square", "cube", "half"))
+	})
+
+	It("should stop at the first subscription failing to be deleted", func() {
+		failures["square"] = http.StatusInternalServerError
+		failures["cube"] = http.StatusInternalServerError
+		failures["half"] = http.StatusInternalServerError
+
+		err := client.DeleteChannel(options)
+		Expect(err).To(HaveOccurred())
+		Expect(deletedSubscriptions()).To(HaveLen(1))
+	})
+
+	It("should leave the subscriptions untouched when the channel can't be deleted", func() {
+		channelMissing = true
+
+		err := client.DeleteChannel(options)
+		Expect(err).To(HaveOccurred())
+		Expect(requests).To(Equal([]string{"DELETE channels/numbers"}))
+	})
+})
@@ -18,20 +18,59 @@
 package core
 
 import (
+	"context"
+	"io"
+
+	build "github.com/knative/build/pkg/apis/build/v1alpha1"
 	eventing "github.com/knative/eventing/pkg/apis/channels/v1alpha1"
 	eventing_cs "github.com/knative/eventing/pkg/client/clientset/versioned"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	serving "github.com/knative/serving/pkg/apis/serving/v1alpha1"
 	serving_cs "github.com/knative/serving/pkg/client/clientset/versioned"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/watch"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
 //go:generate mockery -name=Client
 type Client interface {
+	ListFunctions(options ListFunctionOptions) (*serving.ServiceList, error)
+	FunctionStatuses(options ListFunctionOptions) ([]FunctionStatus, error)
+	WatchFunctions(options WatchFunctionsOptions) (watch.Interface, error)
+	GetFunction(options GetFunctionOptions) (*serving.Service, error)
+	FunctionExists(options FunctionExistsOptions) (bool, error)
+	DescribeFunction(options DescribeFunctionOptions) (*FunctionDescription, error)
 	CreateFunction(options CreateFunctionOptions) (*serving.Service, error)
+	CreateFunctionWithResult(options CreateFunctionOptions) (*CreateResult, error)
+	CreateFunctionAsync(options CreateFunctionOptions) (*FunctionHandle, error)
+	CreateFunctionFromFile(options CreateFunctionFromFileOptions) (*serving.Service, error)
+	CreateFunctions(ctx context.Context, options CreateFunctionsOptions) ([]CreateFunctionResult, error)
+	ApplyFunction(options CreateFunctionOptions) (*serving.Service, bool, error)
+	UpdateFunction(options UpdateFunctionOptions) (*serving.Service, error)
+	SetFunctionImage(options SetFunctionImageOptions) (*serving.Service, error)
+	ScaleFunction(options ScaleFunctionOptions) (*serving.Service, error)
+	PatchFunction(options PatchFunctionOptions) (*serving.Service, error)
+	ReplaceFunction(options ReplaceFunctionOptions) (*serving.Service, error)
+	EditFunction(options EditFunctionOptions) (*serving.Service, bool, error)
+	CloneFunction(options CloneFunctionOptions) (*serving.Service, error)
+	ExportFunction(options ExportFunctionOptions) ([]byte, error)
+	ExportFunctions(options ExportFunctionsOptions) ([]byte, error)
+	DeleteFunction(ctx context.Context, options DeleteFunctionOptions) error
+	DeleteFunctions(ctx context.Context, options DeleteFunctionsOptions) error
+	FunctionURL(options FunctionURLOptions) (string, error)
+	InvokeFunction(ctx context.Context, options InvokeFunctionOptions) (statusCode int, body []byte, err error)
+	WaitForFunctionReady(ctx context.Context, options WaitForFunctionReadyOptions) error
+	FunctionLogs(options FunctionLogsOptions) (io.ReadCloser, error)
+	BuildFunction(options BuildFunctionOptions) (*build.Build, error)
+	WaitForBuild(ctx context.Context, options WaitForBuildOptions) error
+	BuildFromLocal(options LocalBuildOptions) (*serving.Service, error)
+
+	ListRevisions(options ListRevisionsOptions) ([]RevisionSummary, error)
+	DeleteRevision(options DeleteRevisionOptions) error
 
 	CreateSubscription(options CreateSubscriptionOptions) (*eventing.Subscription, error)
+	Subscribe(options SubscribeOptions) (*eventing.Subscription, SubscriptionChange, error)
 
 	ListChannels(options ListChannelOptions) (*eventing.ChannelList, error)
 	CreateChannel(options CreateChannelOptions) (*eventing.Channel, error)
@@ -42,6 +81,12 @@ type Client interface {
 	DeleteService(options DeleteServiceOptions) error
 	ServiceStatus(options ServiceStatusOptions) (*v1alpha1.ServiceCondition, error)
 	ServiceCoordinates(options ServiceInvokeOptions) (ingressIP string, hostName string, err error)
+
+	CreateNamespace(options CreateNamespaceOptions) (*core_v1.Namespace, error)
+	DeleteNamespace(ctx context.Context, options DeleteNamespaceOptions) error
+
+	Ping(ctx context.Context) error
+	ServingVersion(ctx context.Context) (string, error)
 }
 
 type client struct {
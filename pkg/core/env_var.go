@@ -17,11 +17,36 @@
 package core
 
 import (
-	"k8s.io/api/core/v1"
 	"errors"
 	"fmt"
 	"strings"
-		)
+
+	"k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// reservedEnvVars are set by knative in the container of every revision, and can't be overridden reliably
+var reservedEnvVars = []string{"PORT", "K_SERVICE", "K_CONFIGURATION", "K_REVISION"}
+
+// checkReservedEnvVars rejects environment variables colliding with the ones knative reserves, unless allowed.
+func checkReservedEnvVars(env []v1.EnvVar, allowReserved bool) error {
+	if allowReserved {
+		return nil
+	}
+	var names []string
+	for _, reserved := range reservedEnvVars {
+		for _, e := range env {
+			if e.Name == reserved {
+				names = append(names, reserved)
+				break
+			}
+		}
+	}
+	if len(names) > 0 {
+		return &ReservedEnvVarError{Names: names}
+	}
+	return nil
+}
 
 func ParseEnvVar(envVars []string) ([]v1.EnvVar, error) {
 	var results []v1.EnvVar
@@ -90,5 +115,44 @@ func splitEnvVarEntry(env string) ([]string, error) {
 	if len(envEntry[0]) < 1 {
 		return nil, errors.New(fmt.Sprintf("unable to parse '%s', the key part is missing", env))
 	}
+	if msgs := validation.IsEnvVarName(envEntry[0]); len(msgs) > 0 {
+		return nil, errors.New(fmt.Sprintf("unable to parse '%s', the key part is not a valid environment variable name: %s", env, strings.Join(msgs, ", ")))
+	}
 	return envEntry, nil
-}
\ No newline at end of file
+}
+
+// MergeEnvVars returns the current environment variables, with the ones in add set (replacing any variable with the
+// same name, in place) and the ones named in remove deleted. The order of untouched variables is preserved, and new
+// variables are appended. A name can't be both added and removed.
+func MergeEnvVars(current []v1.EnvVar, add []v1.EnvVar, remove []string) ([]v1.EnvVar, error) {
+	removed := map[string]bool{}
+	for _, name := range remove {
+		removed[name] = true
+	}
+	added := map[string]v1.EnvVar{}
+	for _, env := range add {
+		if removed[env.Name] {
+			return nil, fmt.Errorf("environment variable '%s' can't be both added and removed", env.Name)
+		}
+		added[env.Name] = env
+	}
+
+	var results []v1.EnvVar
+	for _, env := range current {
+		if removed[env.Name] {
+			continue
+		}
+		if replacement, ok := added[env.Name]; ok {
+			env = replacement
+			delete(added, env.Name)
+		}
+		results = append(results, env)
+	}
+	for _, env := range add {
+		if _, ok := added[env.Name]; ok {
+			results = append(results, env)
+			delete(added, env.Name)
+		}
+	}
+	return results, nil
+}
@@ -56,6 +56,16 @@ var _ = Describe("Environment variable parsing", func() {
 			})
 		})
 
+		Context("when key is not a valid environment variable name", func() {
+			BeforeEach(func() {
+				input = []string{"FOO BAR=baz"}
+			})
+
+			It("should fail with a suitable error", func() {
+				Expect(err).To(MatchError(HavePrefix("unable to parse 'FOO BAR=baz', the key part is not a valid environment variable name: ")))
+			})
+		})
+
 		Context("when given empty input", func() {
 			BeforeEach(func() {
 				input = []string{}
@@ -84,6 +94,23 @@ var _ = Describe("Environment variable parsing", func() {
 			})
 		})
 
+		Context("when the value contains an equal sign", func() {
+			BeforeEach(func() {
+				input = []string{"JAVA_OPTS=-Dfoo=bar"}
+			})
+
+			It("should only split on the first equal sign", func() {
+				expected := []v1.EnvVar{
+					{
+						Name:  "JAVA_OPTS",
+						Value: "-Dfoo=bar",
+					},
+				}
+				Expect(err).NotTo(HaveOccurred())
+				Expect(output).To(Equal(expected))
+			})
+		})
+
 		Context("when given multiple env vars", func() {
 			BeforeEach(func() {
 				input = []string{"FOO=bar", "BAZ=foo"}
@@ -257,4 +284,20 @@ var _ = Describe("Environment variable parsing", func() {
 			})
 		})
 	})
-})
\ No newline at end of file
+
+	Describe("MergeEnvVars", func() {
+		current := []v1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}, {Name: "C", Value: "3"}}
+
+		It("should replace in place, append new variables and remove", func() {
+			merged, err := core.MergeEnvVars(current,
+				[]v1.EnvVar{{Name: "D", Value: "4"}, {Name: "B", Value: "two"}}, []string{"A"})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(merged).To(Equal([]v1.EnvVar{{Name: "B", Value: "two"}, {Name: "C", Value: "3"}, {Name: "D", Value: "4"}}))
+		})
+
+		It("should fail when a variable is both added and removed", func() {
+			_, err := core.MergeEnvVars(current, []v1.EnvVar{{Name: "B", Value: "two"}}, []string{"B"})
+			Expect(err).To(MatchError("environment variable 'B' can't be both added and removed"))
+		})
+	})
+})
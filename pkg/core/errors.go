@@ -0,0 +1,186 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// FunctionNotFoundError is returned by operations on a function that does not exist.
+type FunctionNotFoundError struct {
+	Name      string
+	Namespace string
+}
+
+func (e *FunctionNotFoundError) Error() string {
+	return fmt.Sprintf("function %q does not exist in namespace %q", e.Name, e.Namespace)
+}
+
+// IsNotFound tells whether err is a FunctionNotFoundError, or a NotFound error returned by the kubernetes API server.
+func IsNotFound(err error) bool {
+	if _, ok := err.(*FunctionNotFoundError); ok {
+		return true
+	}
+	return errors.IsNotFound(err)
+}
+
+// FunctionAlreadyExistsError is returned when creating a function with the name of an existing one.
+type FunctionAlreadyExistsError struct {
+	Name      string
+	Namespace string
+}
+
+func (e *FunctionAlreadyExistsError) Error() string {
+	return fmt.Sprintf("function %q already exists in namespace %q", e.Name, e.Namespace)
+}
+
+// IsAlreadyExists tells whether err is a FunctionAlreadyExistsError, or an AlreadyExists error returned by the
+// kubernetes API server.
+func IsAlreadyExists(err error) bool {
+	if _, ok := err.(*FunctionAlreadyExistsError); ok {
+		return true
+	}
+	return errors.IsAlreadyExists(err)
+}
+
+// FunctionNotReadyError is returned when looking up the URL of a function knative hasn't made addressable yet.
+type FunctionNotReadyError struct {
+	Name      string
+	Namespace string
+}
+
+func (e *FunctionNotReadyError) Error() string {
+	return fmt.Sprintf("function %q in namespace %q is not ready yet, it has no URL", e.Name, e.Namespace)
+}
+
+// BuildFailedError is returned when waiting for the build of a function that fails, as opposed to the function failing
+// to become ready once built.
+type BuildFailedError struct {
+	Function  string
+	Namespace string
+	Build     string
+	// Step is the name of the build step that failed, empty if it can't be told
+	Step    string
+	Reason  string
+	Message string
+}
+
+func (e *BuildFailedError) Error() string {
+	if e.Step != "" {
+		return fmt.Sprintf("build %q of function %q failed at step %q: %s", e.Build, e.Function, e.Step, e.Message)
+	}
+	return fmt.Sprintf("build %q of function %q failed: %s: %s", e.Build, e.Function, e.Reason, e.Message)
+}
+
+// BuildTimeoutError is returned when the build of a function being waited for doesn't complete in time. The build may
+// still be running, or be stuck.
+type BuildTimeoutError struct {
+	Function  string
+	Namespace string
+	Timeout   time.Duration
+}
+
+func (e *BuildTimeoutError) Error() string {
+	return fmt.Sprintf("build of function %q in namespace %q did not complete within %v", e.Function, e.Namespace, e.Timeout)
+}
+
+// WaitError is returned when waiting for a resource gives up, as the context is done. Cause is the error of the
+// context, context.DeadlineExceeded on timeouts.
+type WaitError struct {
+	// Message tells what didn't happen in time, e.g. `function "square" in namespace "ns" did not become ready`
+	Message string
+	Cause   error
+}
+
+func (e *WaitError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Message, e.Cause)
+}
+
+// NamespaceNotFoundError is returned when creating a function in a namespace that does not exist.
+type NamespaceNotFoundError struct {
+	Name string
+}
+
+func (e *NamespaceNotFoundError) Error() string {
+	return fmt.Sprintf("namespace %q does not exist; create it first or pass --create-namespace", e.Name)
+}
+
+// ReservedEnvVarError is returned when setting environment variables that knative reserves for itself, e.g. PORT.
+type ReservedEnvVarError struct {
+	Names []string
+}
+
+func (e *ReservedEnvVarError) Error() string {
+	return fmt.Sprintf("environment variables reserved by knative can't be set: %s; pass --allow-reserved-env to set them anyway",
+		strings.Join(e.Names, ", "))
+}
+
+// ClusterUnreachableError is returned by Ping when the kubernetes API server can't be reached.
+type ClusterUnreachableError struct {
+	Cause error
+}
+
+func (e *ClusterUnreachableError) Error() string {
+	return fmt.Sprintf("cluster unreachable: %v", e.Cause)
+}
+
+// ServingNotInstalledError is returned by Ping when the cluster doesn't serve the knative serving API riff relies on.
+type ServingNotInstalledError struct {
+	GroupVersion string
+}
+
+func (e *ServingNotInstalledError) Error() string {
+	return fmt.Sprintf("knative serving not installed, the cluster doesn't serve %s services", e.GroupVersion)
+}
+
+// IncompatibleServingError is returned when the cluster serves knative serving, but not the version of its API riff
+// supports.
+type IncompatibleServingError struct {
+	Supported string
+	Served    []string
+}
+
+func (e *IncompatibleServingError) Error() string {
+	return fmt.Sprintf("knative serving %s is not supported by this version of riff, which requires %s",
+		strings.Join(e.Served, ", "), e.Supported)
+}
+
+// RegistryError is returned by ResolveDigest when a call to the registry of an image fails. Call describes the call,
+// e.g. "authenticate to", and Cause is the error it failed with, e.g. the one of the keychain providing credentials.
+type RegistryError struct {
+	Registry string
+	Call     string
+	Cause    error
+}
+
+func (e *RegistryError) Error() string {
+	return fmt.Sprintf("unable to %s registry %s: %v", e.Call, e.Registry, e.Cause)
+}
+
+// function returns the service backing a function, turning a NotFound error into a FunctionNotFoundError.
+func (c *client) function(namespaced Namespaced, name string) (*v1alpha1.Service, error) {
+	s, err := c.service(namespaced, name)
+	if errors.IsNotFound(err) {
+		return nil, &FunctionNotFoundError{Name: name, Namespace: c.explicitOrConfigNamespace(namespaced)}
+	}
+	return s, err
+}
@@ -0,0 +1,49 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/runtime/schema"
+)
+
+var _ = Describe("Not found errors", func() {
+
+	It("should describe the missing function", func() {
+		err := &core.FunctionNotFoundError{Name: "square", Namespace: "ns"}
+		Expect(err).To(MatchError(`function "square" does not exist in namespace "ns"`))
+	})
+
+	It("should recognize function not found errors", func() {
+		Expect(core.IsNotFound(&core.FunctionNotFoundError{Name: "square"})).To(BeTrue())
+	})
+
+	It("should recognize kubernetes not found errors", func() {
+		err := errors.NewNotFound(schema.GroupResource{Group: "serving.knative.dev", Resource: "services"}, "square")
+		Expect(core.IsNotFound(err)).To(BeTrue())
+	})
+
+	It("should not recognize other errors", func() {
+		Expect(core.IsNotFound(fmt.Errorf("function square does not exist"))).To(BeFalse())
+		Expect(core.IsNotFound(nil)).To(BeFalse())
+	})
+})
@@ -0,0 +1,32 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"io"
+	"time"
+)
+
+// StubLocalBuildTools replaces the command line tools run by local builds with the given funcs, until the returned
+// func restores them.
+func StubLocalBuildTools(exec func(string, []string, time.Duration) ([]byte, error), stream func(string, []string, io.Writer) error) func() {
+	previousExec, previousStream := execTool, streamTool
+	execTool, streamTool = exec, stream
+	return func() {
+		execTool, streamTool = previousExec, previousStream
+	}
+}
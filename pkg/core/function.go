@@ -17,54 +17,1414 @@
 package core
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
 	build "github.com/knative/build/pkg/apis/build/v1alpha1"
 	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/intstr"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+const (
+	// functionLabel is set on all services created as riff functions, with the function name as value.
+	functionLabel = "riff.projectriff.io/function"
+
+	buildImageArgument = "IMAGE"
+
+	// riffBuildTemplate is the build template installed along with riff, building functions with their invoker
+	riffBuildTemplate = "riff"
+
+	// minScaleAnnotation and maxScaleAnnotation are set on revisions to bound the number of pods of a function
+	minScaleAnnotation = "autoscaling.knative.dev/minScale"
+	maxScaleAnnotation = "autoscaling.knative.dev/maxScale"
+
+	// servingLabelPrefix is the prefix of the labels knative serving sets on the revisions it creates, e.g. the name
+	// of their configuration
+	servingLabelPrefix = "serving.knative.dev/"
+
+	// defaultFunctionServiceAccount is the service account function revisions run as, unless configured otherwise
+	defaultFunctionServiceAccount = "default"
+
+	functionDeletionPollInterval = 1 * time.Second
+
+	// functionReadyPollInterval is how often functions are looked up while waiting for them to be ready, when they
+	// can't be watched
+	functionReadyPollInterval = 1 * time.Second
+
+	// functionApplyAttempts is how many times ApplyFunction tries, in case of conflicting concurrent changes
+	functionApplyAttempts = 5
+	// functionUpdateAttempts is how many times SetFunctionImage tries, in case of conflicting concurrent changes
+	functionUpdateAttempts = 5
+
+	// firstRevisionSuffix is appended by knative to the name of a configuration to name its first revision
+	firstRevisionSuffix = "-00001"
+
+	// servingDomainConfigMap, in the servingNamespace, holds the domains knative serving exposes routes under. Routes
+	// are exposed under defaultServingDomain if none is configured.
+	servingNamespace       = "knative-serving"
+	servingDomainConfigMap = "config-domain"
+	defaultServingDomain   = "example.com"
+
+	// visibilityLabel set to clusterLocalVisibility on a service keeps it from being exposed outside the cluster, under
+	// clusterLocalDomain instead of the serving domain
+	visibilityLabel        = "serving.knative.dev/visibility"
+	clusterLocalVisibility = "cluster-local"
+	clusterLocalDomain     = "svc.cluster.local"
 )
 
 type CreateFunctionOptions struct {
 	CreateServiceOptions
 
+	// GitRepo and GitRevision locate the function sources, built in the cluster. When GitRepo is empty, no build
+	// is performed and Image must already exist.
 	GitRepo     string
 	GitRevision string
+	// BuildTemplate is the build template building the function image along with its revision. Defaults to the riff
+	// one, building the sources with the invoker. Any other template is only given the image to build, as the IMAGE
+	// argument, and must be installed in the namespace.
+	BuildTemplate string
 
 	InvokerURL string
 	Handler    string
 	Artifact   string
+
+	// Labels and Annotations are added to the metadata of the function service. The riff function label is always
+	// set and cannot be overridden.
+	Labels      map[string]string
+	Annotations map[string]string
+	// PodLabels and PodAnnotations are added to the metadata of the revision template instead, and so end up on the
+	// pods running the function, where e.g. sidecar injection annotations are expected. The scale annotations are
+	// set with MinScale and MaxScale only, and knative owns the serving.knative.dev labels.
+	PodLabels      map[string]string
+	PodAnnotations map[string]string
+
+	// ClusterLocal makes the function only reachable from within the cluster, by labelling its service with the
+	// cluster-local visibility. Releases of knative serving not handling the label directly need a config-domain
+	// entry mapping it to the svc.cluster.local domain.
+	ClusterLocal bool
+
+	// ServiceAccountName is the service account function revisions run as, the namespace default one if empty
+	ServiceAccountName string
+	// VerifyServiceAccount makes creation fail early if the ServiceAccountName doesn't exist in the namespace
+	VerifyServiceAccount bool
+	// BuildServiceAccount is the service account the function build runs as, when built from a git repository, as
+	// for BuildFunction
+	BuildServiceAccount string
+	// VerifyBuildServiceAccount makes creation fail early if the BuildServiceAccount doesn't exist in the namespace, or
+	// has no credentials for the registry of the image
+	VerifyBuildServiceAccount bool
+
+	// PullSecrets are the names of secrets used to pull the function image, from a private registry. As revisions
+	// don't support image pull secrets directly, they are added to the service account the function runs as.
+	PullSecrets []string
+	// VerifySecrets makes creation fail early if any of the PullSecrets doesn't exist in the namespace
+	VerifySecrets bool
+
+	// ContainerConcurrency is the maximum number of requests a function container handles at once, 0 meaning no
+	// limit. The version of knative serving in use only supports 0 or 1 (a single request at a time).
+	ContainerConcurrency int64
+
+	// MinScale and MaxScale bound the number of pods of the function revisions. 0 leaves the bound to the autoscaler
+	// defaults (scale to zero, no maximum).
+	MinScale int
+	MaxScale int
+
+	// PullPolicy is the image pull policy of the function container. Empty leaves it to the kubernetes default.
+	PullPolicy core_v1.PullPolicy
+
+	// LivenessProbe and ReadinessProbe are set on the function container, if not nil. Knative serving routes probes
+	// to the function port itself, so HTTP and TCP probes must not specify one.
+	LivenessProbe  *core_v1.Probe
+	ReadinessProbe *core_v1.Probe
+
+	// Command and Args override the entrypoint of the function image, and its arguments. As riff has no access to the
+	// image metadata (it may not even be built yet), Args can only be set along with Command.
+	Command []string
+	Args    []string
+
+	// PinDigest makes the function reference its image by digest, resolved from the registry at creation time, so
+	// that its revision keeps running the same image even if the tag is later moved. Only prebuilt images (no
+	// GitRepo) can be pinned. Unless RequireDigest is set, the tag is kept if the registry can't be reached, and a
+	// warning is written to Warnings.
+	PinDigest     bool
+	RequireDigest bool
+	Warnings      io.Writer
+	// Keychain provides the credentials used to get digests from private registries, DefaultKeychain when nil
+	Keychain Keychain
+	// RegistryInsecure and RegistryCAFile tell how to trust the registry digests are resolved from, see
+	// RegistryOptions
+	RegistryInsecure bool
+	RegistryCAFile   string
+
+	// ServerDryRun submits the function to the API server in dry run mode: it is validated, admission webhooks
+	// included, but not persisted. Nothing else (namespace, service account) is changed either. The service as the
+	// server would have stored it is returned. Only supported by CreateFunction.
+	ServerDryRun bool
+
+	// CreateNamespace makes creation create the target namespace if it doesn't exist, rather than failing
+	CreateNamespace bool
+
+	// CreateBackoff controls how creation is retried on transient errors (conflicts, server timeouts, throttling).
+	// Defaults to 5 attempts, starting 100ms apart and doubling, when nil.
+	CreateBackoff *wait.Backoff
 }
 
+// CreateFunction creates the service backing a function. If a function of the same name already exists, a
+// FunctionAlreadyExistsError is returned.
 func (c *client) CreateFunction(options CreateFunctionOptions) (*v1alpha1.Service, error) {
 	ns := c.explicitOrConfigNamespace(options.Namespaced)
 
+	if err := pinImageDigest(&options); err != nil {
+		return nil, err
+	}
+	s, err := newFunction(options)
+	if err != nil {
+		return nil, err
+	}
+
+	if !options.DryRun {
+		if err := c.checkServingCompatible(); err != nil {
+			return nil, err
+		}
+		if options.ServerDryRun {
+			return c.createFunctionServerDryRun(ns, s)
+		}
+		if err := c.ensureNamespace(ns, options.CreateNamespace); err != nil {
+			return nil, err
+		}
+		if err := c.checkFunctionBuildTemplate(ns, options); err != nil {
+			return nil, err
+		}
+		if err := c.prepareServiceAccount(ns, options); err != nil {
+			return nil, err
+		}
+		backoff := defaultCreateBackoff
+		if options.CreateBackoff != nil {
+			backoff = *options.CreateBackoff
+		}
+		err := retryOnTransientError(backoff, func() error {
+			_, err := c.serving.ServingV1alpha1().Services(ns).Create(s)
+			return err
+		})
+		if errors.IsAlreadyExists(err) {
+			return nil, &FunctionAlreadyExistsError{Name: s.Name, Namespace: ns}
+		}
+		return s, err
+	} else {
+		return s, nil
+	}
+
+}
+
+// CreateResult describes a function that was just created, with details computed without waiting for knative to
+// reconcile it.
+type CreateResult struct {
+	Function *v1alpha1.Service
+	// RevisionName is the name knative gives to the first revision of the function
+	RevisionName string
+	// URL is where the function will be available through the ingress gateway once ready, with the domain knative
+	// serving is configured with, or the cluster-local domain for cluster-local functions. It is empty for (client
+	// side) dry runs, which don't reach the cluster.
+	URL string
+}
+
+// CreateFunctionWithResult creates a function like CreateFunction does, and describes the result.
+func (c *client) CreateFunctionWithResult(options CreateFunctionOptions) (*CreateResult, error) {
+	s, err := c.CreateFunction(options)
+	if err != nil {
+		return nil, err
+	}
+	result := &CreateResult{Function: s, RevisionName: options.Name + firstRevisionSuffix}
+	if !options.DryRun {
+		ns := c.explicitOrConfigNamespace(options.Namespaced)
+		domain := clusterLocalDomain
+		if !options.ClusterLocal {
+			domain = c.servingDomain()
+		}
+		result.URL = fmt.Sprintf("http://%s.%s.%s", options.Name, ns, domain)
+	}
+	return result, nil
+}
+
+// FunctionHandle refers to a function that was created without waiting for it to be ready, so that callers decide
+// when to block, e.g. once several functions have been created.
+type FunctionHandle struct {
+	Name      string
+	Namespace string
+	Function  *v1alpha1.Service
+	// wait blocks until the function is ready, nil when there is nothing to wait for (dry runs)
+	wait func(ctx context.Context) error
+}
+
+// Wait blocks until the function is ready, or ctx is done, as WaitForFunctionReady does. Functions created in dry run
+// mode don't exist, so there is nothing to wait for.
+func (h *FunctionHandle) Wait(ctx context.Context) error {
+	if h.wait == nil {
+		return nil
+	}
+	return h.wait(ctx)
+}
+
+// CreateFunctionAsync creates a function like CreateFunction does, and returns a handle to wait for it to be ready
+// later on.
+func (c *client) CreateFunctionAsync(options CreateFunctionOptions) (*FunctionHandle, error) {
+	s, err := c.CreateFunction(options)
+	if err != nil {
+		return nil, err
+	}
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+	handle := &FunctionHandle{Name: options.Name, Namespace: ns, Function: s}
+	if !options.DryRun && !options.ServerDryRun {
+		handle.wait = func(ctx context.Context) error {
+			return c.WaitForFunctionReady(ctx, WaitForFunctionReadyOptions{Namespaced: Namespaced{Namespace: ns}, Name: options.Name})
+		}
+	}
+	return handle, nil
+}
+
+// WaitForFunctions waits for the functions of all the handles to be ready, concurrently. One of the functions failing
+// to become ready doesn't stop waiting for the others: all failures are reported at once, as an aggregate error.
+func WaitForFunctions(ctx context.Context, handles ...*FunctionHandle) error {
+	errs := make([]error, len(handles))
+	var waiting sync.WaitGroup
+	for i, handle := range handles {
+		waiting.Add(1)
+		go func(i int, handle *FunctionHandle) {
+			defer waiting.Done()
+			errs[i] = handle.Wait(ctx)
+		}(i, handle)
+	}
+	waiting.Wait()
+	return utilerrors.NewAggregate(errs)
+}
+
+// servingDomain returns the default domain functions are exposed under, as configured in knative serving. Domains
+// restricted to routes with given labels are not considered. The knative default is used if the configuration can't
+// be read.
+func (c *client) servingDomain() string {
+	config, err := c.kubeClient.CoreV1().ConfigMaps(servingNamespace).Get(servingDomainConfigMap, meta_v1.GetOptions{})
+	if err != nil {
+		return defaultServingDomain
+	}
+	for _, domain := range sortedKeys(config.Data) {
+		if strings.TrimSpace(config.Data[domain]) == "" {
+			return domain
+		}
+	}
+	return defaultServingDomain
+}
+
+// pinImageDigest replaces the image of the options with its digest form, if asked to.
+func pinImageDigest(options *CreateFunctionOptions) error {
+	if !options.PinDigest {
+		return nil
+	}
+	if options.GitRepo != "" {
+		return fmt.Errorf("the digest of image '%s' can't be pinned, as the image is only built after the function is created", options.Image)
+	}
+	keychain := options.Keychain
+	if keychain == nil {
+		keychain = DefaultKeychain
+	}
+	pinned, err := ResolveDigest(options.Image, RegistryOptions{
+		Keychain: keychain,
+		Insecure: options.RegistryInsecure,
+		CAFile:   options.RegistryCAFile,
+	})
+	if err != nil {
+		if options.RequireDigest {
+			return err
+		}
+		if options.Warnings != nil {
+			fmt.Fprintf(options.Warnings, "Warning: not pinning the digest of image '%s': %v\n", options.Image, err)
+		}
+		return nil
+	}
+	options.Image = pinned
+	return nil
+}
+
+// ensureNamespace fails with a NamespaceNotFoundError if the function namespace doesn't exist, or creates it when
+// asked to. Failing to look the namespace up (e.g. not being allowed to read namespaces) is not an error, the
+// function creation itself reports any actual problem.
+func (c *client) ensureNamespace(ns string, create bool) error {
+	_, err := c.kubeClient.CoreV1().Namespaces().Get(ns, meta_v1.GetOptions{})
+	if !errors.IsNotFound(err) {
+		return nil
+	}
+	if !create {
+		return &NamespaceNotFoundError{Name: ns}
+	}
+	_, err = c.CreateNamespace(CreateNamespaceOptions{Name: ns})
+	return err
+}
+
+// prepareServiceAccount verifies the service account the function runs as exists, if asked to, and makes it
+// reference the function image pull secrets.
+func (c *client) prepareServiceAccount(ns string, options CreateFunctionOptions) error {
+	serviceAccount := options.ServiceAccountName
+	if serviceAccount == "" {
+		serviceAccount = defaultFunctionServiceAccount
+	} else if options.VerifyServiceAccount {
+		_, err := c.kubeClient.CoreV1().ServiceAccounts(ns).Get(serviceAccount, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return fmt.Errorf("service account %q does not exist in namespace %q", serviceAccount, ns)
+		} else if err != nil {
+			return err
+		}
+	}
+	return c.ensurePullSecrets(ns, serviceAccount, options.PullSecrets, options.VerifySecrets)
+}
+
+// ensurePullSecrets makes the given service account, which function revisions run as, reference the given image
+// pull secrets.
+func (c *client) ensurePullSecrets(ns string, serviceAccount string, secrets []string, verify bool) error {
+	if len(secrets) == 0 {
+		return nil
+	}
+	if verify {
+		for _, secret := range secrets {
+			_, err := c.kubeClient.CoreV1().Secrets(ns).Get(secret, meta_v1.GetOptions{})
+			if errors.IsNotFound(err) {
+				return fmt.Errorf("image pull secret %q does not exist in namespace %q", secret, ns)
+			} else if err != nil {
+				return err
+			}
+		}
+	}
+
+	serviceAccounts := c.kubeClient.CoreV1().ServiceAccounts(ns)
+	sa, err := serviceAccounts.Get(serviceAccount, meta_v1.GetOptions{})
+	if err != nil {
+		return err
+	}
+	changed := false
+	for _, secret := range secrets {
+		found := false
+		for _, ref := range sa.ImagePullSecrets {
+			if ref.Name == secret {
+				found = true
+				break
+			}
+		}
+		if !found {
+			sa.ImagePullSecrets = append(sa.ImagePullSecrets, core_v1.LocalObjectReference{Name: secret})
+			changed = true
+		}
+	}
+	if changed {
+		_, err = serviceAccounts.Update(sa)
+	}
+	return err
+}
+
+// ApplyFunction creates the function if it does not exist yet, or updates the existing one to match the provided
+// options otherwise. The returned bool tells whether the function was created. Conflicting concurrent changes (e.g.
+// the function being created by someone else in the meantime) are retried.
+func (c *client) ApplyFunction(options CreateFunctionOptions) (*v1alpha1.Service, bool, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+	if options.ServerDryRun {
+		return nil, false, fmt.Errorf("server-side dry run is only supported when creating functions")
+	}
+
+	if err := pinImageDigest(&options); err != nil {
+		return nil, false, err
+	}
+	s, err := newFunction(options)
+	if err != nil {
+		return nil, false, err
+	}
+	if !options.DryRun {
+		if err := c.checkServingCompatible(); err != nil {
+			return nil, false, err
+		}
+		if err := c.ensureNamespace(ns, options.CreateNamespace); err != nil {
+			return nil, false, err
+		}
+		if err := c.checkFunctionBuildTemplate(ns, options); err != nil {
+			return nil, false, err
+		}
+		if err := c.prepareServiceAccount(ns, options); err != nil {
+			return nil, false, err
+		}
+	}
+
+	services := c.serving.ServingV1alpha1().Services(ns)
+	for attempt := 1; ; attempt++ {
+		existing, err := services.Get(options.Name, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			if options.DryRun {
+				return s, true, nil
+			}
+			_, err = services.Create(s)
+			if errors.IsAlreadyExists(err) && attempt < functionApplyAttempts {
+				continue
+			}
+			return s, true, err
+		} else if err != nil {
+			return nil, false, err
+		}
+
+		updated := existing.DeepCopy()
+		updated.Spec = s.Spec
+		if updated.Labels == nil {
+			updated.Labels = map[string]string{}
+		}
+		for k, v := range s.Labels {
+			updated.Labels[k] = v
+		}
+		if len(s.Annotations) > 0 && updated.Annotations == nil {
+			updated.Annotations = map[string]string{}
+		}
+		for k, v := range s.Annotations {
+			updated.Annotations[k] = v
+		}
+		if options.DryRun {
+			return updated, false, nil
+		}
+		updated, err = services.Update(updated)
+		if errors.IsConflict(err) && attempt < functionApplyAttempts {
+			continue
+		}
+		return updated, false, err
+	}
+}
+
+func newFunction(options CreateFunctionOptions) (*v1alpha1.Service, error) {
+	if err := ValidateImageReference(options.Image); err != nil {
+		return nil, err
+	}
+
 	s, err := newService(options.CreateServiceOptions)
 	if err != nil {
 		return nil, err
 	}
 
-	s.Spec.RunLatest.Configuration.Build = &build.BuildSpec{
-		ServiceAccountName: "riff-build",
+	if err := validateMetadata(options.Labels, options.Annotations); err != nil {
+		return nil, err
+	}
+	s.Labels = map[string]string{}
+	for k, v := range options.Labels {
+		s.Labels[k] = v
+	}
+	s.Labels[functionLabel] = options.Name
+	if options.ClusterLocal {
+		s.Labels[visibilityLabel] = clusterLocalVisibility
+	}
+	if len(options.Annotations) > 0 {
+		s.Annotations = map[string]string{}
+		for k, v := range options.Annotations {
+			s.Annotations[k] = v
+		}
+	}
+
+	configuration, err := ServiceConfiguration(s)
+	if err != nil {
+		return nil, err
+	}
+	switch {
+	case options.ContainerConcurrency < 0:
+		return nil, fmt.Errorf("container concurrency must not be negative, got %d", options.ContainerConcurrency)
+	case options.ContainerConcurrency == 1:
+		configuration.RevisionTemplate.Spec.ConcurrencyModel = v1alpha1.RevisionRequestConcurrencyModelSingle
+	case options.ContainerConcurrency > 1:
+		return nil, fmt.Errorf("container concurrency of %d is not supported, only 0 (unlimited) or 1 (single) are", options.ContainerConcurrency)
+	}
+	if err := validateScale(options.MinScale, options.MaxScale); err != nil {
+		return nil, err
+	}
+	if err := validatePodMetadata(options.PodLabels, options.PodAnnotations); err != nil {
+		return nil, err
+	}
+	revisionTemplate := &configuration.RevisionTemplate
+	if len(options.PodLabels) > 0 {
+		revisionTemplate.Labels = map[string]string{}
+		for k, v := range options.PodLabels {
+			revisionTemplate.Labels[k] = v
+		}
+	}
+	if len(options.PodAnnotations) > 0 || options.MinScale > 0 || options.MaxScale > 0 {
+		revisionTemplate.Annotations = map[string]string{}
+		for k, v := range options.PodAnnotations {
+			revisionTemplate.Annotations[k] = v
+		}
+		setScaleAnnotation(revisionTemplate.Annotations, minScaleAnnotation, options.MinScale)
+		setScaleAnnotation(revisionTemplate.Annotations, maxScaleAnnotation, options.MaxScale)
+	}
+	if options.ServiceAccountName != "" {
+		if msgs := validation.IsDNS1123Subdomain(options.ServiceAccountName); len(msgs) > 0 {
+			return nil, fmt.Errorf("invalid service account name %q: %s", options.ServiceAccountName, strings.Join(msgs, ", "))
+		}
+		configuration.RevisionTemplate.Spec.ServiceAccountName = options.ServiceAccountName
+	}
+	switch options.PullPolicy {
+	case "", core_v1.PullAlways, core_v1.PullIfNotPresent, core_v1.PullNever:
+		configuration.RevisionTemplate.Spec.Container.ImagePullPolicy = options.PullPolicy
+	default:
+		return nil, fmt.Errorf("unsupported image pull policy %q, must be one of %s, %s or %s", options.PullPolicy,
+			core_v1.PullAlways, core_v1.PullIfNotPresent, core_v1.PullNever)
+	}
+	if err := validateProbe("liveness", options.LivenessProbe); err != nil {
+		return nil, err
+	}
+	if err := validateProbe("readiness", options.ReadinessProbe); err != nil {
+		return nil, err
+	}
+	configuration.RevisionTemplate.Spec.Container.LivenessProbe = options.LivenessProbe
+	configuration.RevisionTemplate.Spec.Container.ReadinessProbe = options.ReadinessProbe
+	if err := validateEntrypoint(options.Image, options.Command, options.Args); err != nil {
+		return nil, err
+	}
+	if len(options.Command) > 0 {
+		configuration.RevisionTemplate.Spec.Container.Command = options.Command
+	}
+	if len(options.Args) > 0 {
+		configuration.RevisionTemplate.Spec.Container.Args = options.Args
+	}
+	if options.GitRepo == "" {
+		if options.BuildTemplate != "" {
+			return nil, fmt.Errorf("build template %q requires a git repository to build the function from", options.BuildTemplate)
+		}
+		// the image has been built beforehand, e.g. locally
+		return s, nil
+	}
+	template, err := functionBuildTemplate(options)
+	if err != nil {
+		return nil, err
+	}
+	serviceAccount, err := buildServiceAccount(options.BuildServiceAccount)
+	if err != nil {
+		return nil, err
+	}
+	configuration.Build = &build.BuildSpec{
+		ServiceAccountName: serviceAccount,
 		Source: &build.SourceSpec{
 			Git: &build.GitSourceSpec{
 				Url:      options.GitRepo,
 				Revision: options.GitRevision,
 			},
 		},
-		Template: &build.TemplateInstantiationSpec{
-			Name: "riff",
+		Template: template,
+	}
+	return s, nil
+}
+
+// checkFunctionBuildTemplate makes sure a build template other than the riff one, which is installed along with riff,
+// is installed in the namespace. The build service account is checked as well, if asked to.
+func (c *client) checkFunctionBuildTemplate(ns string, options CreateFunctionOptions) error {
+	if options.GitRepo == "" {
+		return nil
+	}
+	if options.VerifyBuildServiceAccount {
+		serviceAccount, err := buildServiceAccount(options.BuildServiceAccount)
+		if err != nil {
+			return err
+		}
+		if err := c.checkBuildServiceAccount(ns, serviceAccount, options.Image); err != nil {
+			return err
+		}
+	}
+	if options.BuildTemplate == "" || options.BuildTemplate == riffBuildTemplate {
+		return nil
+	}
+	return c.checkBuildTemplate(ns, options.BuildTemplate)
+}
+
+// functionBuildTemplate returns the build template instantiation building the function image, with the arguments
+// expected by the riff template or only the image for other templates.
+func functionBuildTemplate(options CreateFunctionOptions) (*build.TemplateInstantiationSpec, error) {
+	if options.BuildTemplate == "" || options.BuildTemplate == riffBuildTemplate {
+		return &build.TemplateInstantiationSpec{
+			Name: riffBuildTemplate,
 			Arguments: []build.ArgumentSpec{
-				{Name: "IMAGE", Value: options.Image},
+				{Name: buildImageArgument, Value: options.Image},
 				{Name: "INVOKER_PATH", Value: options.InvokerURL},
 				{Name: "FUNCTION_ARTIFACT", Value: options.Artifact},
 				{Name: "FUNCTION_HANDLER", Value: options.Handler},
 				{Name: "FUNCTION_NAME", Value: options.Name},
 			},
-		},
+		}, nil
+	}
+	if msgs := validation.IsDNS1123Subdomain(options.BuildTemplate); len(msgs) > 0 {
+		return nil, fmt.Errorf("invalid build template name %q: %s", options.BuildTemplate, strings.Join(msgs, ", "))
 	}
+	return &build.TemplateInstantiationSpec{
+		Name:      options.BuildTemplate,
+		Arguments: []build.ArgumentSpec{{Name: buildImageArgument, Value: options.Image}},
+	}, nil
+}
 
-	if !options.DryRun {
-		_, err := c.serving.ServingV1alpha1().Services(ns).Create(s)
-		return s, err
+// validateEntrypoint checks that an overridden entrypoint names an executable, and that arguments don't rely on an
+// entrypoint of the image that can't be verified.
+func validateEntrypoint(image string, command []string, args []string) error {
+	if len(command) > 0 && command[0] == "" {
+		return fmt.Errorf("the command must start with the executable to run, got an empty string")
+	}
+	if len(args) > 0 && len(command) == 0 {
+		return fmt.Errorf("arguments require a command, as the entrypoint of image '%s' can't be determined", image)
+	}
+	return nil
+}
+
+// validateMetadata checks the keys and values of labels and the keys of annotations, reporting all invalid entries
+// at once.
+func validateMetadata(labels map[string]string, annotations map[string]string) error {
+	var errs []error
+	for _, k := range sortedKeys(labels) {
+		if msgs := validation.IsQualifiedName(k); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid label key %q: %s", k, strings.Join(msgs, ", ")))
+		}
+		if msgs := validation.IsValidLabelValue(labels[k]); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid value %q for label %q: %s", labels[k], k, strings.Join(msgs, ", ")))
+		}
+	}
+	for _, k := range sortedKeys(annotations) {
+		if msgs := validation.IsQualifiedName(strings.ToLower(k)); len(msgs) > 0 {
+			errs = append(errs, fmt.Errorf("invalid annotation key %q: %s", k, strings.Join(msgs, ", ")))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validatePodMetadata validates the labels and annotations of the revision template like validateMetadata does, and
+// rejects the ones set by other means: the scale annotations, which have their own options, and the labels knative
+// sets on revisions.
+func validatePodMetadata(labels map[string]string, annotations map[string]string) error {
+	if err := validateMetadata(labels, annotations); err != nil {
+		return err
+	}
+	var errs []error
+	for _, k := range sortedKeys(labels) {
+		if strings.HasPrefix(k, servingLabelPrefix) {
+			errs = append(errs, fmt.Errorf("pod label %q can't be set, %s labels are managed by knative", k, servingLabelPrefix))
+		}
+	}
+	for _, k := range []string{minScaleAnnotation, maxScaleAnnotation} {
+		if _, ok := annotations[k]; ok {
+			errs = append(errs, fmt.Errorf("pod annotation %q can't be set, use the min and max scale options instead", k))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+// validateProbe checks that a probe, if any, has exactly one handler and no port, which knative serving manages.
+func validateProbe(kind string, probe *core_v1.Probe) error {
+	if probe == nil {
+		return nil
+	}
+	handlers := 0
+	if probe.Exec != nil {
+		handlers++
+	}
+	if probe.HTTPGet != nil {
+		handlers++
+		if probe.HTTPGet.Port != (intstr.IntOrString{}) {
+			return fmt.Errorf("the %s probe must not specify a port", kind)
+		}
+	}
+	if probe.TCPSocket != nil {
+		handlers++
+		if probe.TCPSocket.Port != (intstr.IntOrString{}) {
+			return fmt.Errorf("the %s probe must not specify a port", kind)
+		}
+	}
+	if handlers != 1 {
+		return fmt.Errorf("the %s probe must have exactly one handler, got %d", kind, handlers)
+	}
+	return nil
+}
+
+func sortedKeys(m map[string]string) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+type ListFunctionOptions struct {
+	Namespaced
+	AllNamespaces bool
+	LabelSelector string
+	// FieldSelector restricts the listed functions to those whose fields match it. As functions are custom resources,
+	// only metadata.name and metadata.namespace are supported by the API server.
+	FieldSelector string
+}
+
+// ListFunctions returns the services that were created as riff functions, further restricted by the optional label
+// and field selectors. Results are sorted by namespace and name.
+func (c *client) ListFunctions(options ListFunctionOptions) (*v1alpha1.ServiceList, error) {
+	ns := meta_v1.NamespaceAll
+	if !options.AllNamespaces {
+		ns = c.explicitOrConfigNamespace(options.Namespaced)
+	}
+
+	listOptions, err := functionListOptions(options.LabelSelector, options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := c.serving.ServingV1alpha1().Services(ns).List(listOptions)
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(list.Items, func(i, j int) bool {
+		if list.Items[i].Namespace != list.Items[j].Namespace {
+			return list.Items[i].Namespace < list.Items[j].Namespace
+		}
+		return list.Items[i].Name < list.Items[j].Name
+	})
+	return list, nil
+}
+
+// functionListOptions returns the options selecting functions further restricted by the given selectors, which are
+// parsed upfront to report malformed ones with a clearer error than the API server would.
+func functionListOptions(labelSelector string, fieldSelector string) (meta_v1.ListOptions, error) {
+	selector := functionLabel
+	if labelSelector != "" {
+		if _, err := labels.Parse(labelSelector); err != nil {
+			return meta_v1.ListOptions{}, fmt.Errorf("invalid label selector %q: %v", labelSelector, err)
+		}
+		selector = selector + "," + labelSelector
+	}
+	if fieldSelector != "" {
+		if _, err := fields.ParseSelector(fieldSelector); err != nil {
+			return meta_v1.ListOptions{}, fmt.Errorf("invalid field selector %q: %v", fieldSelector, err)
+		}
+	}
+	return meta_v1.ListOptions{LabelSelector: selector, FieldSelector: fieldSelector}, nil
+}
+
+type GetFunctionOptions struct {
+	Namespaced
+	Name string
+}
+
+// GetFunction returns the service backing a function. If the function does not exist, a FunctionNotFoundError is
+// returned.
+func (c *client) GetFunction(options GetFunctionOptions) (*v1alpha1.Service, error) {
+	return c.function(options.Namespaced, options.Name)
+}
+
+type FunctionExistsOptions struct {
+	Namespaced
+	Name string
+}
+
+// FunctionExists tells whether a function exists, without decoding the service backing it. A missing function is not
+// an error, any other failure to look it up is.
+func (c *client) FunctionExists(options FunctionExistsOptions) (bool, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	err := c.serving.ServingV1alpha1().RESTClient().Get().
+		Namespace(ns).Resource("services").Name(options.Name).Do().Error()
+	if errors.IsNotFound(err) {
+		return false, nil
+	}
+	return err == nil, err
+}
+
+type UpdateFunctionOptions struct {
+	Namespaced
+	Name    string
+	Image   string
+	Env     []string
+	EnvFrom []string
+
+	// EnvAdd and EnvRemove change individual environment variables, leaving the others untouched
+	EnvAdd    []core_v1.EnvVar
+	EnvRemove []string
+
+	// AllowReservedEnv allows setting environment variables knative reserves, such as PORT
+	AllowReservedEnv bool
+}
+
+// UpdateFunction changes the image and/or environment of an existing function, leaving any other field of the
+// service untouched. Providing env or env-from entries replaces the whole set of environment variables, before
+// EnvAdd and EnvRemove are merged in.
+func (c *client) UpdateFunction(options UpdateFunctionOptions) (*v1alpha1.Service, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	s, err := c.function(options.Namespaced, options.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := ServiceConfiguration(s)
+	if err != nil {
+		return nil, err
+	}
+	container := &configuration.RevisionTemplate.Spec.Container
+
+	if options.Image != "" {
+		setImage(configuration, options.Image)
+	}
+
+	if len(options.Env) > 0 || len(options.EnvFrom) > 0 {
+		envVars, err := ParseEnvVar(options.Env)
+		if err != nil {
+			return nil, err
+		}
+		envVarsFrom, err := ParseEnvVarSource(options.EnvFrom)
+		if err != nil {
+			return nil, err
+		}
+		container.Env = append(envVars, envVarsFrom...)
+	}
+
+	if len(options.EnvAdd) > 0 || len(options.EnvRemove) > 0 {
+		if container.Env, err = MergeEnvVars(container.Env, options.EnvAdd, options.EnvRemove); err != nil {
+			return nil, err
+		}
+	}
+	if len(options.Env) > 0 || len(options.EnvFrom) > 0 || len(options.EnvAdd) > 0 {
+		if err := checkReservedEnvVars(container.Env, options.AllowReservedEnv); err != nil {
+			return nil, err
+		}
+	}
+
+	return c.serving.ServingV1alpha1().Services(ns).Update(s)
+}
+
+// setImage changes the image the configuration runs, and the image its build, if any, produces.
+func setImage(configuration *v1alpha1.ConfigurationSpec, image string) {
+	configuration.RevisionTemplate.Spec.Container.Image = image
+	if configuration.Build != nil && configuration.Build.Template != nil {
+		arguments := configuration.Build.Template.Arguments
+		for i := range arguments {
+			if arguments[i].Name == buildImageArgument {
+				arguments[i].Value = image
+			}
+		}
+	}
+}
+
+type SetFunctionImageOptions struct {
+	Namespaced
+	Name  string
+	Image string
+}
+
+// SetFunctionImage changes the image of a function, and nothing else. The update is tried again, on the latest version
+// of the function, if it conflicts with a concurrent change. The function is left untouched, and no revision is
+// created, if it already runs the image.
+func (c *client) SetFunctionImage(options SetFunctionImageOptions) (*v1alpha1.Service, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	if err := ValidateImageReference(options.Image); err != nil {
+		return nil, err
+	}
+
+	for attempt := 1; ; attempt++ {
+		s, err := c.function(options.Namespaced, options.Name)
+		if err != nil {
+			return nil, err
+		}
+		configuration, err := ServiceConfiguration(s)
+		if err != nil {
+			return nil, err
+		}
+		if configuration.RevisionTemplate.Spec.Container.Image == options.Image {
+			return s, nil
+		}
+		setImage(configuration, options.Image)
+
+		updated, err := c.serving.ServingV1alpha1().Services(ns).Update(s)
+		if errors.IsConflict(err) && attempt < functionUpdateAttempts {
+			continue
+		}
+		return updated, err
+	}
+}
+
+type ScaleFunctionOptions struct {
+	Namespaced
+	Name string
+	// MinScale and MaxScale bound the number of pods of the function, as when creating it. 0 removes the bound.
+	MinScale int
+	MaxScale int
+}
+
+// ScaleFunction changes the bounds of the number of pods of a function. The bounds are annotations of the revision
+// template, so changing them makes knative roll out a new revision of the function.
+func (c *client) ScaleFunction(options ScaleFunctionOptions) (*v1alpha1.Service, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	if err := validateScale(options.MinScale, options.MaxScale); err != nil {
+		return nil, err
+	}
+
+	s, err := c.function(options.Namespaced, options.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	configuration, err := ServiceConfiguration(s)
+	if err != nil {
+		return nil, err
+	}
+	template := &configuration.RevisionTemplate
+	if template.Annotations == nil {
+		template.Annotations = map[string]string{}
+	}
+	setScaleAnnotation(template.Annotations, minScaleAnnotation, options.MinScale)
+	setScaleAnnotation(template.Annotations, maxScaleAnnotation, options.MaxScale)
+
+	return c.serving.ServingV1alpha1().Services(ns).Update(s)
+}
+
+// validateScale rejects negative scale bounds, and a minimum above the maximum when there is one.
+func validateScale(minScale int, maxScale int) error {
+	switch {
+	case minScale < 0 || maxScale < 0:
+		return fmt.Errorf("min and max scale must not be negative, got %d and %d", minScale, maxScale)
+	case maxScale > 0 && minScale > maxScale:
+		return fmt.Errorf("min scale (%d) must not be greater than max scale (%d)", minScale, maxScale)
+	}
+	return nil
+}
+
+func setScaleAnnotation(annotations map[string]string, annotation string, scale int) {
+	if scale > 0 {
+		annotations[annotation] = strconv.Itoa(scale)
 	} else {
-		return s, nil
+		delete(annotations, annotation)
+	}
+}
+
+type PatchFunctionOptions struct {
+	Namespaced
+	Name string
+	// Patch is applied to the service backing the function, as described by PatchType
+	Patch     []byte
+	PatchType types.PatchType
+}
+
+// PatchFunction applies a patch to the service backing a function. Unlike UpdateFunction, the function isn't read
+// first and the patch is merged server side, so that targeted changes can't conflict with concurrent ones. Note that
+// knative services are custom resources, which don't support strategic merge patches: use a JSON merge or JSON patch.
+func (c *client) PatchFunction(options PatchFunctionOptions) (*v1alpha1.Service, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	s, err := c.serving.ServingV1alpha1().Services(ns).Patch(options.Name, options.PatchType, options.Patch)
+	if errors.IsNotFound(err) {
+		return nil, &FunctionNotFoundError{Name: options.Name, Namespace: ns}
+	}
+	return s, err
+}
+
+// FunctionImagePatch returns a JSON merge patch (types.MergePatchType) changing the image of a function, to be
+// applied with PatchFunction. Only functions running the latest revision, as riff creates them, can be patched:
+// pinned functions keep serving their pinned revision whatever the image, so they are refused. Functions built
+// from source also reference their image in the build arguments, use UpdateFunction for those.
+func FunctionImagePatch(function *v1alpha1.Service, image string) ([]byte, error) {
+	if image == "" {
+		return nil, fmt.Errorf("image must not be empty")
 	}
+	if function.Spec.RunLatest == nil {
+		if function.Spec.Pinned != nil {
+			return nil, fmt.Errorf("function %q is pinned to revision %q, only functions running the latest revision can have their image patched", function.Name, function.Spec.Pinned.RevisionName)
+		}
+		return nil, fmt.Errorf("function %q does not run the latest revision, only such functions can have their image patched", function.Name)
+	}
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"runLatest": map[string]interface{}{
+				"configuration": map[string]interface{}{
+					"revisionTemplate": map[string]interface{}{
+						"spec": map[string]interface{}{
+							"container": map[string]interface{}{
+								"image": image,
+							},
+						},
+					},
+				},
+			},
+		},
+	}
+	return json.Marshal(patch)
+}
+
+type ReplaceFunctionOptions struct {
+	Namespaced
+	// Function is the complete service to replace the existing function with. Its namespace, if set, must match the
+	// one of the options.
+	Function *v1alpha1.Service
+}
+
+// ReplaceFunction replaces an existing function with the given service as a whole, unlike UpdateFunction which only
+// changes some fields. The resource version of the existing function is used if the service has none, in which case
+// replacing is attempted once more against the latest version after a conflicting concurrent change. A service with a
+// resource version is only replaced if the function is still at that version: a conflict is returned otherwise, so
+// that changes made since the service was read are not overwritten.
+func (c *client) ReplaceFunction(options ReplaceFunctionOptions) (*v1alpha1.Service, error) {
+	s := options.Function.DeepCopy()
+	if options.Namespace == "" {
+		options.Namespace = s.Namespace
+	} else if s.Namespace != "" && s.Namespace != options.Namespace {
+		return nil, fmt.Errorf("function %q is in namespace %q, not %q", s.Name, s.Namespace, options.Namespace)
+	}
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+	s.Namespace = ns
+	if s.Labels == nil {
+		s.Labels = map[string]string{}
+	}
+	s.Labels[functionLabel] = s.Name
+
+	services := c.serving.ServingV1alpha1().Services(ns)
+	if s.ResourceVersion != "" {
+		return services.Update(s)
+	}
+	for attempt := 1; ; attempt++ {
+		existing, err := c.function(options.Namespaced, s.Name)
+		if err != nil {
+			return nil, err
+		}
+		s.ResourceVersion = existing.ResourceVersion
+		replaced, err := services.Update(s)
+		if errors.IsConflict(err) && attempt < 2 {
+			continue
+		}
+		return replaced, err
+	}
+}
+
+type DeleteFunctionOptions struct {
+	Namespaced
+	Name string
+	Wait bool
+}
+
+// DeleteFunction deletes the service backing a function. If the function does not exist, a FunctionNotFoundError is
+// returned, so that callers can tell it apart using IsNotFound(). When Wait is set, this blocks until the service and
+// its underlying configuration and route are actually gone, or ctx is done.
+func (c *client) DeleteFunction(ctx context.Context, options DeleteFunctionOptions) error {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	err := c.serving.ServingV1alpha1().Services(ns).Delete(options.Name, nil)
+	if errors.IsNotFound(err) {
+		return &FunctionNotFoundError{Name: options.Name, Namespace: ns}
+	}
+	if err != nil || !options.Wait {
+		return err
+	}
+
+	err = wait.PollImmediateUntil(functionDeletionPollInterval, func() (bool, error) {
+		return c.functionRemoved(ns, options.Name)
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return &WaitError{Message: fmt.Sprintf("function %q in namespace %q was not removed", options.Name, ns), Cause: ctx.Err()}
+	}
+	return err
+}
+
+type DeleteFunctionsOptions struct {
+	Namespaced
+	Names []string
+	Wait  bool
+}
+
+// DeleteFunctions deletes several functions, as DeleteFunction does. A failure to delete one of them, including it not
+// existing, doesn't prevent the others from being deleted: all failures are reported at once, as an aggregate error.
+func (c *client) DeleteFunctions(ctx context.Context, options DeleteFunctionsOptions) error {
+	var errs []error
+	for _, name := range options.Names {
+		err := c.DeleteFunction(ctx, DeleteFunctionOptions{Namespaced: options.Namespaced, Name: name, Wait: options.Wait})
+		if err != nil {
+			errs = append(errs, fmt.Errorf("unable to delete function %q: %v", name, err))
+		}
+	}
+	return utilerrors.NewAggregate(errs)
+}
+
+type CreateFunctionsOptions struct {
+	Functions []CreateFunctionOptions
+	// Parallelism is the maximum number of functions being created at once, 1 if not positive
+	Parallelism int
+}
+
+// CreateFunctionResult is the outcome of the creation of one of the functions passed to CreateFunctions.
+type CreateFunctionResult struct {
+	Name     string
+	Function *v1alpha1.Service
+	// Handle allows waiting for the function to be ready, see WaitForFunctions. It is nil if the creation failed.
+	Handle *FunctionHandle
+	Err    error
+}
+
+// CreateFunctions creates several functions concurrently, as CreateFunction does, by a bounded pool of workers. The
+// results are returned in the order of the options. A failure to create one of the functions doesn't prevent the
+// others from being created: all failures are also reported at once, as an aggregate error. Once ctx is done, the
+// functions not being created yet are skipped, their result holding the context error. The functions are not waited
+// for: pass the handles of the results to WaitForFunctions to do so.
+func (c *client) CreateFunctions(ctx context.Context, options CreateFunctionsOptions) ([]CreateFunctionResult, error) {
+	results := make([]CreateFunctionResult, len(options.Functions))
+	parallelism := options.Parallelism
+	if parallelism < 1 {
+		parallelism = 1
+	}
+
+	pending := make(chan int)
+	var workers sync.WaitGroup
+	for w := 0; w < parallelism; w++ {
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			for i := range pending {
+				results[i] = CreateFunctionResult{Name: options.Functions[i].Name}
+				handle, err := c.CreateFunctionAsync(options.Functions[i])
+				if err != nil {
+					results[i].Err = err
+					continue
+				}
+				results[i].Function = handle.Function
+				results[i].Handle = handle
+			}
+		}()
+	}
+	for i := range options.Functions {
+		if ctx.Err() == nil {
+			select {
+			case pending <- i:
+				continue
+			case <-ctx.Done():
+			}
+		}
+		results[i] = CreateFunctionResult{Name: options.Functions[i].Name, Err: ctx.Err()}
+	}
+	close(pending)
+	workers.Wait()
+
+	var errs []error
+	for _, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("unable to create function %q: %v", result.Name, result.Err))
+		}
+	}
+	return results, utilerrors.NewAggregate(errs)
+}
+
+// functionRemoved returns true once none of the service, configuration and route making up a function exist anymore.
+func (c *client) functionRemoved(ns string, name string) (bool, error) {
+	serving := c.serving.ServingV1alpha1()
+	lookups := []func() error{
+		func() error {
+			_, err := serving.Services(ns).Get(name, meta_v1.GetOptions{})
+			return err
+		},
+		func() error {
+			_, err := serving.Configurations(ns).Get(name, meta_v1.GetOptions{})
+			return err
+		},
+		func() error {
+			_, err := serving.Routes(ns).Get(name, meta_v1.GetOptions{})
+			return err
+		},
+	}
+	for _, lookup := range lookups {
+		err := lookup()
+		if err == nil {
+			return false, nil
+		} else if !errors.IsNotFound(err) {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+type InvokeFunctionOptions struct {
+	Namespaced
+	Name    string
+	Method  string
+	Path    string
+	Body    []byte
+	Headers []string
+}
+
+// InvokeFunction sends an http request to a function, through the ingress gateway and using the host of the function
+// URL (see FunctionURL) as the Host header, and returns the response status code and body. Headers are expressed in a
+// 'name: value' format. The request is abandoned once ctx is done.
+func (c *client) InvokeFunction(ctx context.Context, options InvokeFunctionOptions) (statusCode int, body []byte, err error) {
+	functionURL, err := c.FunctionURL(FunctionURLOptions{Namespaced: options.Namespaced, Name: options.Name})
+	if err != nil {
+		return 0, nil, err
+	}
+	u, err := url.Parse(functionURL)
+	if err != nil {
+		return 0, nil, err
+	}
+	ingress, err := c.ingressAddress()
+	if err != nil {
+		return 0, nil, err
+	}
+	if !strings.HasPrefix(ingress, "http://") && !strings.HasPrefix(ingress, "https://") {
+		ingress = "http://" + ingress
+	}
+
+	req, err := http.NewRequest(options.Method, ingress+options.Path, bytes.NewReader(options.Body))
+	if err != nil {
+		return 0, nil, err
+	}
+	req = req.WithContext(ctx)
+	req.Host = u.Host
+	for _, h := range options.Headers {
+		header := strings.SplitN(h, ":", 2)
+		if len(header) != 2 || strings.TrimSpace(header[0]) == "" {
+			return 0, nil, fmt.Errorf("unable to parse header '%s', headers must be provided as 'name: value'", h)
+		}
+		req.Header.Add(strings.TrimSpace(header[0]), strings.TrimSpace(header[1]))
+	}
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return 0, nil, err
+	}
+	defer resp.Body.Close()
+	body, err = ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return 0, nil, err
+	}
+	return resp.StatusCode, body, nil
+}
 
+type WaitForFunctionReadyOptions struct {
+	Namespaced
+	Name string
+	// PollInterval is how often the function is looked up when it can't be watched, 1s if zero
+	PollInterval time.Duration
+	// WaitForBuild makes the build of the latest revision of the function be waited for first, so that a failed build
+	// is reported as such rather than as the function not becoming ready
+	WaitForBuild bool
+	// BuildLogs receives the logs of the steps of the build as they run when waiting for it, followed by a line telling
+	// the build succeeded
+	BuildLogs io.Writer
+	// BuildTimeout is how long the build is waited for, if positive, before giving up with a BuildTimeoutError.
+	// Otherwise the build is waited for as long as ctx allows.
+	BuildTimeout time.Duration
+}
+
+// WaitForFunctionReady watches the service backing a function until its Ready condition becomes True, or ctx is done.
+// If the condition becomes False instead, the reason and message of the condition are returned as an error. When the
+// service can't be watched, or the watch ends early, it is polled instead. When waiting for the build of the function
+// too, a build that fails is reported as a BuildFailedError, without waiting for the function any further.
+func (c *client) WaitForFunctionReady(ctx context.Context, options WaitForFunctionReadyOptions) error {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	interval := options.PollInterval
+	if interval == 0 {
+		interval = functionReadyPollInterval
+	} else if interval < 0 {
+		return fmt.Errorf("poll interval must be positive, got %v", interval)
+	}
+	if options.BuildTimeout < 0 {
+		return fmt.Errorf("build timeout must be positive, got %v", options.BuildTimeout)
+	}
+	if options.WaitForBuild {
+		if err := c.waitForFunctionBuild(ctx, ns, options.Name, interval, options.BuildTimeout, options.BuildLogs); err != nil {
+			return err
+		}
+	}
+
+	w, err := c.serving.ServingV1alpha1().Services(ns).Watch(meta_v1.ListOptions{
+		FieldSelector: fields.OneTermEqualSelector("metadata.name", options.Name).String(),
+	})
+	if err == nil {
+		done, err := watchUntilReady(ctx, w, ns, options.Name)
+		if done {
+			return err
+		}
+	}
+	return c.pollUntilReady(ctx, ns, options.Name, interval)
+}
+
+// watchUntilReady waits for the function to become ready through a watch of its service. It returns false if the watch
+// ended before the outcome is known.
+func watchUntilReady(ctx context.Context, w watch.Interface, ns string, name string) (bool, error) {
+	defer w.Stop()
+
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return false, nil
+			}
+			switch event.Type {
+			case watch.Deleted:
+				return true, fmt.Errorf("function %q was deleted before it became ready", name)
+			case watch.Error:
+				return true, errors.FromObject(event.Object)
+			}
+			s, ok := event.Object.(*v1alpha1.Service)
+			if !ok {
+				continue
+			}
+			if ready, err := serviceReady(s); ready || err != nil {
+				return true, err
+			}
+		case <-ctx.Done():
+			return true, functionNotReadyError(ctx, ns, name)
+		}
+	}
+}
+
+// pollUntilReady waits for the function to become ready by looking up its service at the given interval.
+func (c *client) pollUntilReady(ctx context.Context, ns string, name string, interval time.Duration) error {
+	services := c.serving.ServingV1alpha1().Services(ns)
+	err := WaitForCondition(ctx, interval, func() (meta_v1.Object, []ConditionDescription, error) {
+		s, err := services.Get(name, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return nil, nil, &FunctionNotFoundError{Name: name, Namespace: ns}
+		} else if err != nil {
+			return nil, nil, err
+		}
+		return s, serviceConditions(s), nil
+	}, functionReady(name))
+	if err != nil && err == ctx.Err() {
+		return functionNotReadyError(ctx, ns, name)
+	}
+	return err
+}
+
+func functionNotReadyError(ctx context.Context, ns string, name string) error {
+	return &WaitError{Message: fmt.Sprintf("function %q in namespace %q did not become ready", name, ns), Cause: ctx.Err()}
+}
+
+// serviceReady returns true if the Ready condition of the service is True, and an error if it is False.
+func serviceReady(s *v1alpha1.Service) (bool, error) {
+	return functionReady(s.Name)(serviceConditions(s))
+}
+
+// functionReady returns a predicate holding once the Ready condition of the function is True.
+func functionReady(name string) ConditionsPredicate {
+	ready := ConditionIsTrue(string(v1alpha1.ServiceConditionReady))
+	return func(conditions []ConditionDescription) (bool, error) {
+		ok, err := ready(conditions)
+		if err != nil {
+			return false, fmt.Errorf("function %q failed to become ready: %v", name, err)
+		}
+		return ok, nil
+	}
 }
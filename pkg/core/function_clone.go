@@ -0,0 +1,87 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// lastAppliedAnnotation is maintained by kubectl apply, and would be stale on a copy
+const lastAppliedAnnotation = "kubectl.kubernetes.io/last-applied-configuration"
+
+type CloneFunctionOptions struct {
+	Namespaced
+	// Name is the name of the function to copy
+	Name string
+	// NewName is the name of the function to create, which must not exist yet
+	NewName string
+	// Mutate, if not nil, is called with the copy before it is created, e.g. to change its image or environment
+	Mutate func(*v1alpha1.Service)
+}
+
+// CloneFunction creates a copy of an existing function under a new name, as functions can't be renamed in place.
+// Fields managed by the server (resource version, uid, status, etc) are not copied. The source function is left
+// untouched, so renaming amounts to cloning and then deleting the source.
+func (c *client) CloneFunction(options CloneFunctionOptions) (*v1alpha1.Service, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	if msgs := validation.IsDNS1123Label(options.NewName); len(msgs) > 0 {
+		return nil, fmt.Errorf("invalid function name %q: %s", options.NewName, strings.Join(msgs, ", "))
+	}
+	source, err := c.function(options.Namespaced, options.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	clone := &v1alpha1.Service{
+		TypeMeta: source.TypeMeta,
+		ObjectMeta: meta_v1.ObjectMeta{
+			Name:      options.NewName,
+			Namespace: ns,
+			Labels:    map[string]string{},
+		},
+		Spec: *source.Spec.DeepCopy(),
+	}
+	for k, v := range source.Labels {
+		clone.Labels[k] = v
+	}
+	clone.Labels[functionLabel] = options.NewName
+	for k, v := range source.Annotations {
+		if k == lastAppliedAnnotation {
+			continue
+		}
+		if clone.Annotations == nil {
+			clone.Annotations = map[string]string{}
+		}
+		clone.Annotations[k] = v
+	}
+	if options.Mutate != nil {
+		options.Mutate(clone)
+	}
+
+	created, err := c.serving.ServingV1alpha1().Services(ns).Create(clone)
+	if errors.IsAlreadyExists(err) {
+		return nil, &FunctionAlreadyExistsError{Name: options.NewName, Namespace: ns}
+	}
+	return created, err
+}
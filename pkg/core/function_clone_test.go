@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Cloning functions", func() {
+
+	var (
+		server *httptest.Server
+		// created is the service posted to the API server, if any
+		created *v1alpha1.Service
+		// existing are the names of the functions that already exist, besides the source
+		existing map[string]bool
+		client   core.Client
+		options  core.CloneFunctionOptions
+	)
+
+	BeforeEach(func() {
+		created = nil
+		existing = map[string]bool{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service",`+
+					`"metadata":{"name":"square","namespace":"ns","resourceVersion":"42","uid":"c0ffee","generation":3,`+
+					`"labels":{"riff.projectriff.io/function":"square","team":"math"},`+
+					`"annotations":{"kubectl.kubernetes.io/last-applied-configuration":"{}","owner":"alice"}},`+
+					`"spec":{"runLatest":{"configuration":{"revisionTemplate":{"spec":{"container":{"image":"acme/square"}}}}}},`+
+					`"status":{"latestCreatedRevisionName":"square-00003","domain":"square.ns.example.com"}}`)
+			case r.Method == http.MethodPost && r.URL.Path == "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services":
+				service := &v1alpha1.Service{}
+				Expect(json.NewDecoder(r.Body).Decode(service)).To(Succeed())
+				if existing[service.Name] {
+					w.WriteHeader(http.StatusConflict)
+					fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"AlreadyExists","code":409,"message":"services.serving.knative.dev \"%s\" already exists"}`, service.Name)
+					return
+				}
+				created = service
+				w.WriteHeader(http.StatusCreated)
+				Expect(json.NewEncoder(w).Encode(service)).To(Succeed())
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.CloneFunctionOptions{Name: "square", NewName: "cube"}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create a copy under the new name, in the same namespace", func() {
+		_, err := client.CloneFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Name).To(Equal("cube"))
+		Expect(created.Namespace).To(Equal("ns"))
+		Expect(created.Labels).To(Equal(map[string]string{"riff.projectriff.io/function": "cube", "team": "math"}))
+		Expect(created.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square"))
+	})
+
+	It("should not copy the fields managed by the server", func() {
+		_, err := client.CloneFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.ResourceVersion).To(BeEmpty())
+		Expect(created.UID).To(BeEmpty())
+		Expect(created.Generation).To(BeZero())
+		Expect(created.Status).To(Equal(v1alpha1.ServiceStatus{}))
+		Expect(created.Annotations).To(Equal(map[string]string{"owner": "alice"}))
+	})
+
+	It("should apply the mutation to the copy", func() {
+		options.Mutate = func(s *v1alpha1.Service) {
+			s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image = "acme/cube"
+		}
+
+		_, err := client.CloneFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(created.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/cube"))
+	})
+
+	It("should fail when the new function already exists", func() {
+		existing["cube"] = true
+
+		_, err := client.CloneFunction(options)
+		Expect(err).To(Equal(&core.FunctionAlreadyExistsError{Name: "cube", Namespace: "ns"}))
+		Expect(created).To(BeNil())
+	})
+
+	It("should fail when the source function does not exist", func() {
+		options.Name = "half"
+
+		_, err := client.CloneFunction(options)
+		Expect(err).To(Equal(&core.FunctionNotFoundError{Name: "half", Namespace: "ns"}))
+		Expect(created).To(BeNil())
+	})
+
+	It("should reject invalid new names", func() {
+		options.NewName = "Cube"
+
+		_, err := client.CloneFunction(options)
+		Expect(err).To(MatchError(HavePrefix(`invalid function name "Cube": `)))
+	})
+})
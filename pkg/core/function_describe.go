@@ -0,0 +1,140 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"time"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type DescribeFunctionOptions struct {
+	Namespaced
+	Name string
+}
+
+// FunctionDescription gathers the state of a function and of the knative resources backing it.
+type FunctionDescription struct {
+	Name      string
+	Namespace string
+	Image     string
+	// URL is the address the function is reachable at, through the ingress. Empty until the route is ready.
+	URL        string
+	Conditions []ConditionDescription
+
+	// LatestCreatedRevision and LatestReadyRevision are nil if there is no such revision (yet)
+	LatestCreatedRevision *RevisionDescription
+	LatestReadyRevision   *RevisionDescription
+}
+
+type RevisionDescription struct {
+	Name       string
+	Image      string
+	Conditions []ConditionDescription
+}
+
+type ConditionDescription struct {
+	Type               string
+	Status             string
+	Reason             string
+	Message            string
+	LastTransitionTime time.Time
+}
+
+// DescribeFunction returns a summary of the function, its latest revisions and its route. Revisions or route that
+// don't exist (yet) are left out of the description rather than reported as errors.
+func (c *client) DescribeFunction(options DescribeFunctionOptions) (*FunctionDescription, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	s, err := c.function(options.Namespaced, options.Name)
+	if err != nil {
+		return nil, err
+	}
+	configuration, err := ServiceConfiguration(s)
+	if err != nil {
+		return nil, err
+	}
+
+	description := &FunctionDescription{
+		Name:      s.Name,
+		Namespace: ns,
+		Image:     configuration.RevisionTemplate.Spec.Container.Image,
+	}
+	description.Conditions = serviceConditions(s)
+
+	route, err := c.serving.ServingV1alpha1().Routes(ns).Get(s.Name, meta_v1.GetOptions{})
+	if err == nil {
+		if route.Status.Domain != "" {
+			description.URL = "http://" + route.Status.Domain
+		}
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	if description.LatestCreatedRevision, err = c.describeRevision(ns, s.Status.LatestCreatedRevisionName); err != nil {
+		return nil, err
+	}
+	if description.LatestReadyRevision, err = c.describeRevision(ns, s.Status.LatestReadyRevisionName); err != nil {
+		return nil, err
+	}
+
+	return description, nil
+}
+
+// serviceConditions returns the conditions of the service, in the order it reports them.
+func serviceConditions(s *v1alpha1.Service) []ConditionDescription {
+	var conditions []ConditionDescription
+	for _, cond := range s.Status.Conditions {
+		conditions = append(conditions, ConditionDescription{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: cond.LastTransitionTime.Inner.Time,
+		})
+	}
+	return conditions
+}
+
+func (c *client) describeRevision(ns string, name string) (*RevisionDescription, error) {
+	if name == "" {
+		return nil, nil
+	}
+	revision, err := c.serving.ServingV1alpha1().Revisions(ns).Get(name, meta_v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	description := &RevisionDescription{
+		Name:  revision.Name,
+		Image: revision.Spec.Container.Image,
+	}
+	for _, cond := range revision.Status.Conditions {
+		description.Conditions = append(description.Conditions, ConditionDescription{
+			Type:               string(cond.Type),
+			Status:             string(cond.Status),
+			Reason:             cond.Reason,
+			Message:            cond.Message,
+			LastTransitionTime: cond.LastTransitionTime.Inner.Time,
+		})
+	}
+	return description, nil
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Describing functions", func() {
+
+	var (
+		server *httptest.Server
+		// routeStatus and revisionStatus are the status codes the route and revisions are served with
+		routeStatus    int
+		revisionStatus int
+		client         core.Client
+		options        core.DescribeFunctionOptions
+	)
+
+	BeforeEach(func() {
+		routeStatus = http.StatusOK
+		revisionStatus = http.StatusOK
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"},`+
+					`"spec":{"runLatest":{"configuration":{"revisionTemplate":{"spec":{"container":{"image":"acme/square:2"}}}}}},`+
+					`"status":{"latestCreatedRevisionName":"square-00002","latestReadyRevisionName":"square-00001","conditions":[{"type":"Ready","status":"False","reason":"RevisionFailed"}]}}`)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/routes/square":
+				if routeStatus != http.StatusOK {
+					w.WriteHeader(routeStatus)
+					fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"%s","code":%d}`, statusReason(routeStatus), routeStatus)
+					return
+				}
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Route","metadata":{"name":"square"},"status":{"domain":"square.ns.example.com"}}`)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/revisions/square-00001":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Revision","metadata":{"name":"square-00001"},`+
+					`"spec":{"container":{"image":"acme/square:1"}},"status":{"conditions":[{"type":"Ready","status":"True"}]}}`)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/revisions/square-00002":
+				if revisionStatus != http.StatusOK {
+					w.WriteHeader(revisionStatus)
+					fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"%s","code":%d}`, statusReason(revisionStatus), revisionStatus)
+					return
+				}
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Revision","metadata":{"name":"square-00002"},`+
+					`"spec":{"container":{"image":"acme/square:2"}},"status":{"conditions":[{"type":"Ready","status":"False","reason":"ContainerMissing"}]}}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.DescribeFunctionOptions{Name: "square"}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should gather the function, its latest revisions and its route", func() {
+		d, err := client.DescribeFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.Name).To(Equal("square"))
+		Expect(d.Namespace).To(Equal("ns"))
+		Expect(d.Image).To(Equal("acme/square:2"))
+		Expect(d.URL).To(Equal("http://square.ns.example.com"))
+		Expect(d.Conditions).To(HaveLen(1))
+		Expect(d.Conditions[0].Reason).To(Equal("RevisionFailed"))
+
+		Expect(d.LatestCreatedRevision.Name).To(Equal("square-00002"))
+		Expect(d.LatestCreatedRevision.Image).To(Equal("acme/square:2"))
+		Expect(d.LatestCreatedRevision.Conditions[0].Reason).To(Equal("ContainerMissing"))
+		Expect(d.LatestReadyRevision.Name).To(Equal("square-00001"))
+		Expect(d.LatestReadyRevision.Image).To(Equal("acme/square:1"))
+		Expect(d.LatestReadyRevision.Conditions[0].Status).To(Equal("True"))
+	})
+
+	It("should leave out a route that doesn't exist yet", func() {
+		routeStatus = http.StatusNotFound
+
+		d, err := client.DescribeFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.URL).To(BeEmpty())
+		Expect(d.LatestReadyRevision).NotTo(BeNil())
+	})
+
+	It("should leave out a revision that doesn't exist (anymore)", func() {
+		revisionStatus = http.StatusNotFound
+
+		d, err := client.DescribeFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(d.LatestCreatedRevision).To(BeNil())
+		Expect(d.LatestReadyRevision.Name).To(Equal("square-00001"))
+	})
+
+	It("should propagate other errors looking up the route", func() {
+		routeStatus = http.StatusInternalServerError
+
+		_, err := client.DescribeFunction(options)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should propagate other errors looking up revisions", func() {
+		revisionStatus = http.StatusForbidden
+
+		_, err := client.DescribeFunction(options)
+		Expect(err).To(HaveOccurred())
+	})
+
+	It("should fail for a missing function", func() {
+		options.Name = "cube"
+
+		_, err := client.DescribeFunction(options)
+		Expect(err).To(MatchError(&core.FunctionNotFoundError{Name: "cube", Namespace: "ns"}))
+	})
+})
+
+// statusReason is the reason the API server gives along with an HTTP status code
+func statusReason(code int) string {
+	switch code {
+	case http.StatusNotFound:
+		return "NotFound"
+	case http.StatusForbidden:
+		return "Forbidden"
+	default:
+		return "InternalError"
+	}
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/equality"
+)
+
+// defaultEditor is used to edit functions when neither the options nor $EDITOR name an editor
+const defaultEditor = "vi"
+
+const editHeader = `# Please edit the function below. Lines beginning with a '#' are ignored,
+# and an empty file aborts the edit.
+#
+`
+
+type EditFunctionOptions struct {
+	Namespaced
+	Name string
+	// Editor is the command editing the function, run with the path of the file to edit as last argument. Defaults to
+	// $EDITOR, or vi.
+	Editor string
+}
+
+// EditFunction opens the function, as exported by ExportFunction, in an editor and replaces it with the result, the
+// way `kubectl edit` does. If the edited function can't be decoded, the editor is opened again with the error at the
+// top of the file, until the function is valid or left unchanged. The returned bool tells whether the function was
+// replaced, which it isn't if the edit didn't change it or the file was emptied.
+func (c *client) EditFunction(options EditFunctionOptions) (*v1alpha1.Service, bool, error) {
+	original, err := c.ExportFunction(ExportFunctionOptions{Namespaced: options.Namespaced, Name: options.Name})
+	if err != nil {
+		return nil, false, err
+	}
+
+	// the file is named after the function, with an extension telling editors it is YAML
+	dir, err := ioutil.TempDir("", "riff-edit-")
+	if err != nil {
+		return nil, false, err
+	}
+	defer os.RemoveAll(dir)
+	path := filepath.Join(dir, options.Name+".yaml")
+
+	if err := ioutil.WriteFile(path, original, 0600); err != nil {
+		return nil, false, err
+	}
+	unchanged, err := readServiceFile(path, nil)
+	if err != nil {
+		return nil, false, err
+	}
+
+	content := original
+	var problem error
+	for {
+		if err := ioutil.WriteFile(path, append(editPreamble(problem), content...), 0600); err != nil {
+			return nil, false, err
+		}
+		if err := runEditor(options.Editor, path); err != nil {
+			return nil, false, err
+		}
+		edited, err := ioutil.ReadFile(path)
+		if err != nil {
+			return nil, false, err
+		}
+		edited = withoutComments(edited)
+		if len(bytes.TrimSpace(edited)) == 0 {
+			return nil, false, nil
+		}
+		if problem != nil && bytes.Equal(edited, content) {
+			return nil, false, fmt.Errorf("edit cancelled, the function is still invalid: %v", problem)
+		}
+		content = edited
+
+		if err := ioutil.WriteFile(path, edited, 0600); err != nil {
+			return nil, false, err
+		}
+		s, err := readServiceFile(path, nil)
+		if err == nil {
+			err = checkEditedIdentity(unchanged, s)
+		}
+		if err != nil {
+			problem = err
+			continue
+		}
+		if equality.Semantic.DeepEqual(unchanged, s) {
+			return nil, false, nil
+		}
+		replaced, err := c.ReplaceFunction(ReplaceFunctionOptions{Namespaced: options.Namespaced, Function: s})
+		return replaced, err == nil, err
+	}
+}
+
+// checkEditedIdentity makes sure the edited function is still the one being edited.
+func checkEditedIdentity(original *v1alpha1.Service, edited *v1alpha1.Service) error {
+	if edited.Name != original.Name {
+		return fmt.Errorf("the name of the function can't be changed from %q", original.Name)
+	}
+	if edited.Namespace != original.Namespace {
+		return fmt.Errorf("the namespace of the function can't be changed from %q", original.Namespace)
+	}
+	return nil
+}
+
+// editPreamble returns the comment put at the top of the file to edit, describing the problem with the previous edit
+// if any.
+func editPreamble(problem error) []byte {
+	if problem == nil {
+		return []byte(editHeader)
+	}
+	preamble := editHeader
+	for _, line := range strings.Split(problem.Error(), "\n") {
+		preamble += "# " + line + "\n"
+	}
+	return []byte(preamble + "#\n")
+}
+
+// withoutComments removes the lines starting with a '#'.
+func withoutComments(content []byte) []byte {
+	var kept [][]byte
+	for _, line := range bytes.SplitAfter(content, []byte("\n")) {
+		if !bytes.HasPrefix(bytes.TrimSpace(line), []byte("#")) {
+			kept = append(kept, line)
+		}
+	}
+	return bytes.Join(kept, nil)
+}
+
+// runEditor opens the file at path in the editor, attached to the terminal. The editor command is run by the shell,
+// so that it may include arguments, e.g. "code --wait".
+func runEditor(editor string, path string) error {
+	if editor == "" {
+		editor = os.Getenv("EDITOR")
+	}
+	if editor == "" {
+		editor = defaultEditor
+	}
+	cmd := exec.Command("sh", "-c", editor+` "$1"`, "sh", path)
+	cmd.Stdin = os.Stdin
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("editor %q failed: %v", editor, err)
+	}
+	return nil
+}
@@ -0,0 +1,155 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Editing functions", func() {
+
+	var (
+		server   *httptest.Server
+		replaced []*v1alpha1.Service
+		client   core.Client
+		options  core.EditFunctionOptions
+	)
+
+	BeforeEach(func() {
+		replaced = []*v1alpha1.Service{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			if r.Method == http.MethodPut {
+				body, _ := ioutil.ReadAll(r.Body)
+				s := &v1alpha1.Service{}
+				Expect(json.Unmarshal(body, s)).To(Succeed())
+				replaced = append(replaced, s)
+				w.Write(body)
+				return
+			}
+			fmt.Fprint(w, exportedService)
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+
+		options = core.EditFunctionOptions{Name: "square"}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should replace the function with the edited one", func() {
+		options.Editor = `sed -i 's|acme/square:1.0|acme/square:2.0|'`
+
+		s, changed, err := client.EditFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square:2.0"))
+		Expect(replaced).To(HaveLen(1))
+		Expect(replaced[0].ResourceVersion).To(Equal("4242"))
+		Expect(replaced[0].Labels).To(HaveKeyWithValue("team", "numbers"))
+	})
+
+	It("should skip edits that don't change the function", func() {
+		options.Editor = "true"
+
+		s, changed, err := client.EditFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(s).To(BeNil())
+		Expect(replaced).To(BeEmpty())
+	})
+
+	It("should cancel the edit when the file is emptied", func() {
+		options.Editor = "truncate -s 0"
+
+		_, changed, err := client.EditFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeFalse())
+		Expect(replaced).To(BeEmpty())
+	})
+
+	It("should reopen the editor with the error when the function is invalid", func() {
+		// the first edit breaks the function, the second one fixes it once the error is shown
+		options.Editor = `f() {
+			if grep -q '^# unable to decode the function in' "$1"; then
+				sed -i 's|imag: acme/square:1.0|image: acme/square:2.0|' "$1"
+			else
+				sed -i 's|image: acme/square:1.0|imag: acme/square:1.0|' "$1"
+			fi
+		}; f`
+
+		s, changed, err := client.EditFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(changed).To(BeTrue())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square:2.0"))
+		Expect(replaced).To(HaveLen(1))
+	})
+
+	It("should give up when an invalid function is left unchanged", func() {
+		options.Editor = `sed -i 's|kind: Service|kind: Route|'`
+
+		_, changed, err := client.EditFunction(options)
+		Expect(err).To(MatchError(HavePrefix("edit cancelled, the function is still invalid: ")))
+		Expect(err.Error()).To(ContainSubstring(`expected apiVersion "serving.knative.dev/v1alpha1" and kind "Service", got "serving.knative.dev/v1alpha1" and "Route"`))
+		Expect(changed).To(BeFalse())
+		Expect(replaced).To(BeEmpty())
+	})
+
+	It("should not allow renaming the function", func() {
+		options.Editor = `f() {
+			grep -q '^# the name of the function' "$1" || sed -i 's|name: square|name: cube|' "$1"
+		}; f`
+
+		_, _, err := client.EditFunction(options)
+		Expect(err).To(MatchError(`edit cancelled, the function is still invalid: the name of the function can't be changed from "square"`))
+		Expect(replaced).To(BeEmpty())
+	})
+
+	It("should report editor failures", func() {
+		options.Editor = "false"
+
+		_, _, err := client.EditFunction(options)
+		Expect(err).To(MatchError(`editor "false" failed: exit status 1`))
+	})
+
+	It("should fail for a missing function", func() {
+		options.Name = "cube"
+
+		_, _, err := client.EditFunction(options)
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+})
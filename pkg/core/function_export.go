@@ -0,0 +1,137 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+
+	"github.com/ghodss/yaml"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type ExportFunctionOptions struct {
+	Namespaced
+	Name string
+}
+
+// ExportFunction returns the YAML description of a function, without the fields managed by the server (status, uid,
+// resource version, creation timestamp, generation, etc), so that it can be kept in source control and created again
+// with CreateFunctionFromFile.
+func (c *client) ExportFunction(options ExportFunctionOptions) ([]byte, error) {
+	s, err := c.function(options.Namespaced, options.Name)
+	if err != nil {
+		return nil, err
+	}
+	return exportService(s)
+}
+
+type ExportFunctionsOptions struct {
+	Namespaced
+}
+
+// ExportFunctions returns the YAML descriptions of all the functions of a namespace, as ExportFunction does, sorted
+// by name and separated by "---" so that the result is a single stream that can be applied again as a whole. There
+// are no documents at all if the namespace has no function.
+func (c *client) ExportFunctions(options ExportFunctionsOptions) ([]byte, error) {
+	list, err := c.ListFunctions(ListFunctionOptions{Namespaced: options.Namespaced})
+	if err != nil {
+		return nil, err
+	}
+
+	var stream []byte
+	for i := range list.Items {
+		manifest, err := exportService(&list.Items[i])
+		if err != nil {
+			return nil, err
+		}
+		if i > 0 {
+			stream = append(stream, "---\n"...)
+		}
+		stream = append(stream, manifest...)
+	}
+	return stream, nil
+}
+
+// exportService returns the YAML description of a service without the fields managed by the server.
+func exportService(s *v1alpha1.Service) ([]byte, error) {
+	exported := &v1alpha1.Service{
+		TypeMeta: meta_v1.TypeMeta{
+			APIVersion: v1alpha1.SchemeGroupVersion.String(),
+			Kind:       "Service",
+		},
+		ObjectMeta: userObjectMeta(s.ObjectMeta),
+		Spec:       *s.Spec.DeepCopy(),
+	}
+	exported.Spec.Generation = 0
+	if configuration, err := ServiceConfiguration(exported); err == nil {
+		configuration.Generation = 0
+	}
+
+	content, err := json.Marshal(exported)
+	if err != nil {
+		return nil, err
+	}
+	// zero timestamps, unset structs and the status would otherwise be serialized as nulls and empty objects
+	var fields map[string]interface{}
+	if err := json.Unmarshal(content, &fields); err != nil {
+		return nil, err
+	}
+	delete(fields, "status")
+	return yaml.Marshal(withoutEmpty(fields))
+}
+
+// userObjectMeta returns the parts of meta set by users, rather than by the server, dropping the annotation kubectl
+// apply maintains as it would be stale on a new object.
+func userObjectMeta(meta meta_v1.ObjectMeta) meta_v1.ObjectMeta {
+	user := meta_v1.ObjectMeta{
+		Name:      meta.Name,
+		Namespace: meta.Namespace,
+		Labels:    meta.Labels,
+	}
+	for k, v := range meta.Annotations {
+		if k == lastAppliedAnnotation {
+			continue
+		}
+		if user.Annotations == nil {
+			user.Annotations = map[string]string{}
+		}
+		user.Annotations[k] = v
+	}
+	return user
+}
+
+// withoutEmpty removes the null values and empty objects of a JSON object, recursively.
+func withoutEmpty(fields map[string]interface{}) map[string]interface{} {
+	for k, v := range fields {
+		switch value := v.(type) {
+		case nil:
+			delete(fields, k)
+		case map[string]interface{}:
+			if len(withoutEmpty(value)) == 0 {
+				delete(fields, k)
+			}
+		case []interface{}:
+			for _, item := range value {
+				if object, ok := item.(map[string]interface{}); ok {
+					withoutEmpty(object)
+				}
+			}
+		}
+	}
+	return fields
+}
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/rest"
+)
+
+const exportedService = `{
+  "apiVersion": "serving.knative.dev/v1alpha1",
+  "kind": "Service",
+  "metadata": {
+    "name": "square",
+    "namespace": "ns",
+    "uid": "b7a8f5e2-a7c1-11e8-98d0-529269fb1459",
+    "resourceVersion": "4242",
+    "generation": 3,
+    "creationTimestamp": "2018-08-24T10:00:00Z",
+    "selfLink": "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square",
+    "labels": {"riff.projectriff.io/function": "square", "team": "numbers"},
+    "annotations": {"kubectl.kubernetes.io/last-applied-configuration": "{}", "owner": "joseph"}
+  },
+  "spec": {
+    "generation": 3,
+    "runLatest": {
+      "configuration": {
+        "revisionTemplate": {
+          "metadata": {"creationTimestamp": null},
+          "spec": {"container": {"image": "acme/square:1.0", "env": [{"name": "A", "value": "1"}]}}
+        }
+      }
+    }
+  },
+  "status": {"latestReadyRevisionName": "square-00003", "domain": "square.ns.example.com"}
+}`
+
+var _ = Describe("Exporting functions", func() {
+
+	var (
+		server *httptest.Server
+		client core.Client
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if r.URL.Path == "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services" {
+				w.Header().Set("Content-Type", "application/json")
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"ServiceList","items":[%s,%s]}`,
+					exportedService, strings.Replace(exportedService, "square", "cube", -1))
+				return
+			}
+			if r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square" {
+				w.Header().Set("Content-Type", "application/json")
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			fmt.Fprint(w, exportedService)
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should strip the fields managed by the server", func() {
+		manifest, err := client.ExportFunction(core.ExportFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Name: "square"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(manifest)).To(Equal(`apiVersion: serving.knative.dev/v1alpha1
+kind: Service
+metadata:
+  annotations:
+    owner: joseph
+  labels:
+    riff.projectriff.io/function: square
+    team: numbers
+  name: square
+  namespace: ns
+spec:
+  runLatest:
+    configuration:
+      revisionTemplate:
+        spec:
+          container:
+            env:
+            - name: A
+              value: "1"
+            image: acme/square:1.0
+            name: ""
+`))
+	})
+
+	It("should round trip through create from file", func() {
+		manifest, err := client.ExportFunction(core.ExportFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Name: "square"})
+		Expect(err).NotTo(HaveOccurred())
+
+		s, err := client.CreateFunctionFromFile(core.CreateFunctionFromFileOptions{
+			Path:   "-",
+			Stdin:  strings.NewReader(string(manifest)),
+			DryRun: true,
+		})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Name).To(Equal("square"))
+		Expect(s.Namespace).To(Equal("ns"))
+		Expect(s.Labels).To(Equal(map[string]string{"riff.projectriff.io/function": "square", "team": "numbers"}))
+		Expect(s.Annotations).To(Equal(map[string]string{"owner": "joseph"}))
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square:1.0"))
+	})
+
+	It("should fail for missing functions", func() {
+		_, err := client.ExportFunction(core.ExportFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Name: "cube"})
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+
+	It("should export all the functions of a namespace, sorted by name", func() {
+		stream, err := client.ExportFunctions(core.ExportFunctionsOptions{Namespaced: core.Namespaced{Namespace: "ns"}})
+		Expect(err).NotTo(HaveOccurred())
+
+		documents := strings.Split(string(stream), "---\n")
+		Expect(documents).To(HaveLen(2))
+		for i, name := range []string{"cube", "square"} {
+			s, err := client.CreateFunctionFromFile(core.CreateFunctionFromFileOptions{
+				Path:   "-",
+				Stdin:  strings.NewReader(documents[i]),
+				DryRun: true,
+			})
+			Expect(err).NotTo(HaveOccurred())
+			Expect(s.Name).To(Equal(name))
+			Expect(s.Annotations).To(Equal(map[string]string{"owner": "joseph"}))
+		}
+	})
+})
@@ -0,0 +1,165 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/validation"
+)
+
+// stdinPath is the path designating the standard input, rather than an actual file
+const stdinPath = "-"
+
+type CreateFunctionFromFileOptions struct {
+	Namespaced
+	// Path is the YAML or JSON file describing the knative Service backing the function, or "-" to read it from Stdin
+	Path  string
+	Stdin io.Reader
+
+	// Name, Image and Env override the ones in the file, when set. Env entries are expressed in a 'key=value' format
+	// and are merged with the file environment variables.
+	Name  string
+	Image string
+	Env   []string
+
+	DryRun bool
+}
+
+// CreateFunctionFromFile creates a function from a full or partial knative Service read from a file, e.g. as
+// produced by ExportFunction. The description must have the serving.knative.dev/v1alpha1 Service kind.
+// Fields managed by the server (resource version, uid, status, etc) are ignored, and a description without spec is
+// completed as a function always running the latest revision.
+func (c *client) CreateFunctionFromFile(options CreateFunctionFromFileOptions) (*v1alpha1.Service, error) {
+	s, err := readServiceFile(options.Path, options.Stdin)
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Name != "" {
+		s.Name = options.Name
+	}
+	if s.Name == "" {
+		return nil, fmt.Errorf("%s doesn't name the function, set metadata.name or provide a name", describePath(options.Path))
+	}
+	if msgs := validation.IsDNS1123Label(s.Name); len(msgs) > 0 {
+		return nil, fmt.Errorf("invalid function name %q: %s", s.Name, strings.Join(msgs, ", "))
+	}
+	ns := options.Namespace
+	if ns == "" {
+		ns = c.explicitOrConfigNamespace(Namespaced{Namespace: s.Namespace})
+	}
+	s.ObjectMeta = userObjectMeta(s.ObjectMeta)
+	s.Namespace = ns
+	if s.Labels == nil {
+		s.Labels = map[string]string{}
+	}
+	s.Labels[functionLabel] = s.Name
+	s.Status = v1alpha1.ServiceStatus{}
+
+	if s.Spec.RunLatest == nil && s.Spec.Pinned == nil {
+		s.Spec.RunLatest = &v1alpha1.RunLatestType{}
+	}
+	configuration, err := ServiceConfiguration(s)
+	if err != nil {
+		return nil, err
+	}
+	container := &configuration.RevisionTemplate.Spec.Container
+	if options.Image != "" {
+		container.Image = options.Image
+	}
+	if err := ValidateImageReference(container.Image); err != nil {
+		return nil, err
+	}
+	env, err := ParseEnvVar(options.Env)
+	if err != nil {
+		return nil, err
+	}
+	if container.Env, err = MergeEnvVars(container.Env, env, nil); err != nil {
+		return nil, err
+	}
+
+	if options.DryRun {
+		return s, nil
+	}
+	if err := c.checkServingCompatible(); err != nil {
+		return nil, err
+	}
+	created, err := c.serving.ServingV1alpha1().Services(ns).Create(s)
+	if errors.IsAlreadyExists(err) {
+		return nil, &FunctionAlreadyExistsError{Name: s.Name, Namespace: ns}
+	}
+	return created, err
+}
+
+// readServiceFile decodes a knative Service from a YAML or JSON file, checking that it is of the expected kind.
+// Unknown fields are rejected, as they are most likely typos that would otherwise be silently dropped.
+func readServiceFile(path string, stdin io.Reader) (*v1alpha1.Service, error) {
+	var content []byte
+	var err error
+	if path == stdinPath {
+		if stdin == nil {
+			return nil, fmt.Errorf("no standard input to read the function from")
+		}
+		content, err = ioutil.ReadAll(stdin)
+	} else {
+		content, err = ioutil.ReadFile(path)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// YAML being a superset of JSON, both are handled by converting to JSON first
+	content, err = yaml.YAMLToJSON(content)
+	if err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", describePath(path), err)
+	}
+
+	typeMeta := meta_v1.TypeMeta{}
+	if err := json.Unmarshal(content, &typeMeta); err != nil {
+		return nil, fmt.Errorf("unable to parse %s: %v", describePath(path), err)
+	}
+	expected := v1alpha1.SchemeGroupVersion.WithKind("Service")
+	if typeMeta.GroupVersionKind() != expected {
+		return nil, fmt.Errorf("%s doesn't describe a function: expected apiVersion %q and kind %q, got %q and %q",
+			describePath(path), expected.GroupVersion().String(), expected.Kind, typeMeta.APIVersion, typeMeta.Kind)
+	}
+
+	s := &v1alpha1.Service{}
+	decoder := json.NewDecoder(bytes.NewReader(content))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(s); err != nil {
+		return nil, fmt.Errorf("unable to decode the function in %s: %v", describePath(path), err)
+	}
+	return s, nil
+}
+
+func describePath(path string) string {
+	if path == stdinPath {
+		return "the standard input"
+	}
+	return fmt.Sprintf("'%s'", path)
+}
@@ -0,0 +1,126 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"io/ioutil"
+	"os"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/api/core/v1"
+)
+
+var _ = Describe("Creating functions from a file", func() {
+
+	var (
+		client  core.Client
+		options core.CreateFunctionFromFileOptions
+		path    string
+	)
+
+	write := func(content string) {
+		f, err := ioutil.TempFile("", "function")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		_, err = f.WriteString(content)
+		Expect(err).NotTo(HaveOccurred())
+		path = f.Name()
+		options.Path = path
+	}
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		options = core.CreateFunctionFromFileOptions{DryRun: true}
+		path = ""
+	})
+
+	AfterEach(func() {
+		if path != "" {
+			os.Remove(path)
+		}
+	})
+
+	It("should complete a partial YAML description with the overrides", func() {
+		write(`apiVersion: serving.knative.dev/v1alpha1
+kind: Service
+metadata:
+  name: square
+  namespace: ns
+  resourceVersion: "42"
+spec:
+  runLatest:
+    configuration:
+      revisionTemplate:
+        spec:
+          container:
+            image: acme/square:1.0
+            env:
+            - name: A
+              value: "1"
+`)
+		options.Image = "acme/square:2.0"
+		options.Env = []string{"B=2"}
+
+		s, err := client.CreateFunctionFromFile(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Name).To(Equal("square"))
+		Expect(s.Namespace).To(Equal("ns"))
+		Expect(s.ResourceVersion).To(BeEmpty())
+		Expect(s.Labels).To(HaveKeyWithValue("riff.projectriff.io/function", "square"))
+		container := s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container
+		Expect(container.Image).To(Equal("acme/square:2.0"))
+		Expect(container.Env).To(Equal([]v1.EnvVar{{Name: "A", Value: "1"}, {Name: "B", Value: "2"}}))
+	})
+
+	It("should read a JSON description from the standard input", func() {
+		options.Path = "-"
+		options.Stdin = strings.NewReader(`{"apiVersion": "serving.knative.dev/v1alpha1", "kind": "Service"}`)
+		options.Name = "square"
+		options.Namespace = "ns"
+		options.Image = "acme/square"
+
+		s, err := client.CreateFunctionFromFile(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Name).To(Equal("square"))
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square"))
+	})
+
+	It("should reject descriptions of other kinds", func() {
+		write("apiVersion: apps/v1\nkind: Deployment\nmetadata:\n  name: square\n")
+
+		_, err := client.CreateFunctionFromFile(options)
+		Expect(err).To(MatchError("'" + path + `' doesn't describe a function: expected apiVersion "serving.knative.dev/v1alpha1" and kind "Service", got "apps/v1" and "Deployment"`))
+	})
+
+	It("should reject unknown fields", func() {
+		write("apiVersion: serving.knative.dev/v1alpha1\nkind: Service\nmetadata:\n  name: square\nspec:\n  runLatests: {}\n")
+
+		_, err := client.CreateFunctionFromFile(options)
+		Expect(err).To(MatchError(ContainSubstring(`unknown field "runLatests"`)))
+	})
+
+	It("should require a name", func() {
+		write("apiVersion: serving.knative.dev/v1alpha1\nkind: Service\n")
+		options.Image = "acme/square"
+
+		_, err := client.CreateFunctionFromFile(options)
+		Expect(err).To(MatchError("'" + path + "' doesn't name the function, set metadata.name or provide a name"))
+	})
+})
@@ -0,0 +1,256 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// userContainerName is the name knative gives to the container running the function, in revision pods
+	userContainerName = "user-container"
+
+	// functionLogsPodsPollInterval is how often pods are looked up while following logs, to account for pods
+	// that are started as the function scales
+	functionLogsPodsPollInterval = 2 * time.Second
+)
+
+type FunctionLogsOptions struct {
+	Namespaced
+	Name   string
+	Follow bool
+	// TailLines is the number of most recent lines to show for each pod, or all lines if negative
+	TailLines int64
+	// Revision is the revision of the function to show the logs of, defaults to the latest one
+	Revision string
+}
+
+// FunctionLogs streams the logs of the pods running the latest revision of a function, or the given one. Each line
+// is prefixed with the name of the pod it comes from. When following, pods started after the call (e.g. when scaling
+// from zero) are picked up as they become available and the returned reader only ends once closed. A revision given
+// explicitly must have pods running when the call is made though, as older revisions typically don't scale up again.
+func (c *client) FunctionLogs(options FunctionLogsOptions) (io.ReadCloser, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	s, err := c.function(options.Namespaced, options.Name)
+	if err != nil {
+		return nil, err
+	}
+	revision := options.Revision
+	if revision == "" {
+		revision = s.Status.LatestCreatedRevisionName
+		if revision == "" {
+			return nil, fmt.Errorf("function %q has no revision yet", options.Name)
+		}
+	}
+	selector := labels.Set{serving.RevisionLabelKey: revision}.String()
+	if options.Revision != "" {
+		if err := c.checkRevisionPods(ns, s.Name, revision, selector); err != nil {
+			return nil, err
+		}
+	}
+
+	logOptions := core_v1.PodLogOptions{Container: userContainerName, Follow: options.Follow}
+	if options.TailLines >= 0 {
+		logOptions.TailLines = &options.TailLines
+	}
+
+	r, w := io.Pipe()
+	m := &logMerger{
+		kubeClient: c.kubeClient,
+		namespace:  ns,
+		selector:   selector,
+		options:    logOptions,
+		out:        w,
+		streaming:  map[string]bool{},
+		streams:    map[string]io.ReadCloser{},
+		done:       make(chan struct{}),
+	}
+	go m.run()
+
+	return &mergedLogs{PipeReader: r, merger: m}, nil
+}
+
+// checkRevisionPods makes sure the revision belongs to the function and has pods to show the logs of.
+func (c *client) checkRevisionPods(ns string, function string, revision string, selector string) error {
+	r, err := c.serving.ServingV1alpha1().Revisions(ns).Get(revision, meta_v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("revision %q does not exist in namespace %q", revision, ns)
+	} else if err != nil {
+		return err
+	}
+	// revisions are labeled with the name of their configuration, which is the name of the service
+	if r.Labels[serving.ConfigurationLabelKey] != function {
+		return fmt.Errorf("revision %q is not a revision of function %q", revision, function)
+	}
+
+	pods, err := c.kubeClient.CoreV1().Pods(ns).List(meta_v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return err
+	}
+	if len(pods.Items) == 0 {
+		return fmt.Errorf("revision %q has no pods, it may have been scaled to zero", revision)
+	}
+	return nil
+}
+
+// logMerger copies the logs of all pods matching a selector to a single writer, one goroutine per pod.
+type logMerger struct {
+	kubeClient kubernetes.Interface
+	namespace  string
+	selector   string
+	options    core_v1.PodLogOptions
+	out        *io.PipeWriter
+
+	streaming map[string]bool
+	writeLock sync.Mutex
+	pods      sync.WaitGroup
+	done      chan struct{}
+	stopOnce  sync.Once
+
+	// streams are the log streams being copied, by pod. They are closed when stopping, so that copies following
+	// them end
+	streams     map[string]io.ReadCloser
+	streamsLock sync.Mutex
+	stopped     bool
+}
+
+func (m *logMerger) run() {
+	for {
+		if err := m.streamNewPods(); err != nil {
+			m.out.CloseWithError(err)
+			return
+		}
+		if !m.options.Follow {
+			m.pods.Wait()
+			m.out.Close()
+			return
+		}
+		select {
+		case <-m.done:
+			return
+		case <-time.After(functionLogsPodsPollInterval):
+		}
+	}
+}
+
+func (m *logMerger) streamNewPods() error {
+	pods, err := m.kubeClient.CoreV1().Pods(m.namespace).List(meta_v1.ListOptions{LabelSelector: m.selector})
+	if err != nil {
+		return err
+	}
+	sort.Slice(pods.Items, func(i, j int) bool {
+		return pods.Items[i].Name < pods.Items[j].Name
+	})
+
+	for _, pod := range pods.Items {
+		if m.streaming[pod.Name] || pod.Status.Phase == core_v1.PodPending {
+			continue
+		}
+		stream, err := m.kubeClient.CoreV1().Pods(m.namespace).GetLogs(pod.Name, &m.options).Stream()
+		if err != nil {
+			if m.options.Follow {
+				continue // the pod may be going away, or not ready yet. Try again next time
+			}
+			return err
+		}
+		if !m.track(pod.Name, stream) {
+			stream.Close()
+			return nil
+		}
+		m.streaming[pod.Name] = true
+		go m.copy(pod.Name, stream)
+	}
+	return nil
+}
+
+// track registers the stream of a pod to copy, unless stopped already.
+func (m *logMerger) track(pod string, stream io.ReadCloser) bool {
+	m.streamsLock.Lock()
+	defer m.streamsLock.Unlock()
+	if m.stopped {
+		return false
+	}
+	m.streams[pod] = stream
+	m.pods.Add(1)
+	return true
+}
+
+// untrack closes the stream of a pod once copied, unless stopping closed it already.
+func (m *logMerger) untrack(pod string) {
+	m.streamsLock.Lock()
+	defer m.streamsLock.Unlock()
+	if stream, ok := m.streams[pod]; ok {
+		stream.Close()
+		delete(m.streams, pod)
+	}
+}
+
+func (m *logMerger) copy(pod string, stream io.ReadCloser) {
+	defer m.pods.Done()
+	defer m.untrack(pod)
+
+	scanner := bufio.NewScanner(stream)
+	for scanner.Scan() {
+		m.writeLock.Lock()
+		_, err := fmt.Fprintf(m.out, "[%s] %s\n", pod, scanner.Text())
+		m.writeLock.Unlock()
+		if err != nil {
+			m.stop()
+			return
+		}
+	}
+}
+
+func (m *logMerger) stop() {
+	m.stopOnce.Do(func() {
+		close(m.done)
+
+		m.streamsLock.Lock()
+		defer m.streamsLock.Unlock()
+		m.stopped = true
+		for pod, stream := range m.streams {
+			stream.Close()
+			delete(m.streams, pod)
+		}
+	})
+}
+
+// mergedLogs stops looking for new pods and closes the log streams once closed, returning after all copies ended.
+type mergedLogs struct {
+	*io.PipeReader
+	merger *logMerger
+}
+
+func (l *mergedLogs) Close() error {
+	l.merger.stop()
+	err := l.PipeReader.Close()
+	l.merger.pods.Wait()
+	return err
+}
@@ -0,0 +1,146 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"bufio"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Showing the logs of a function revision", func() {
+
+	var (
+		server *httptest.Server
+		// pods are the running pods of each revision
+		pods map[string][]string
+		// disconnected is closed once the followed logs of the latest revision are no longer read
+		disconnected chan struct{}
+		client       core.Client
+		options      core.FunctionLogsOptions
+	)
+
+	BeforeEach(func() {
+		pods = map[string][]string{}
+		disconnected = make(chan struct{})
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"},"status":{"latestCreatedRevisionName":"square-00002"}}`)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/revisions/square-00001":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Revision","metadata":{"name":"square-00001","labels":{"serving.knative.dev/configuration":"square"}}}`)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/revisions/cube-00001":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Revision","metadata":{"name":"cube-00001","labels":{"serving.knative.dev/configuration":"cube"}}}`)
+			case "/api/v1/namespaces/ns/pods":
+				selector := r.URL.Query().Get("labelSelector")
+				items := ""
+				for i, pod := range pods[selector] {
+					if i > 0 {
+						items += ","
+					}
+					items += fmt.Sprintf(`{"metadata":{"name":"%s"},"status":{"phase":"Running"}}`, pod)
+				}
+				fmt.Fprintf(w, `{"apiVersion":"v1","kind":"PodList","items":[%s]}`, items)
+			case "/api/v1/namespaces/ns/pods/square-00001-deployment-abc/log":
+				w.Header().Set("Content-Type", "text/plain")
+				fmt.Fprint(w, "hello from the first revision\n")
+			case "/api/v1/namespaces/ns/pods/square-00002-deployment-def/log":
+				w.Header().Set("Content-Type", "text/plain")
+				fmt.Fprint(w, "hello from the second revision\n")
+				w.(http.Flusher).Flush()
+				<-r.Context().Done()
+				close(disconnected)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.FunctionLogsOptions{Name: "square", TailLines: -1, Revision: "square-00001"}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should show the logs of the pods of the revision", func() {
+		pods["serving.knative.dev/revision=square-00001"] = []string{"square-00001-deployment-abc"}
+
+		logs, err := client.FunctionLogs(options)
+		Expect(err).NotTo(HaveOccurred())
+		defer logs.Close()
+		content, err := ioutil.ReadAll(logs)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(string(content)).To(Equal("[square-00001-deployment-abc] hello from the first revision\n"))
+	})
+
+	It("should close the log streams it follows once closed", func() {
+		pods["serving.knative.dev/revision=square-00002"] = []string{"square-00002-deployment-def"}
+		options.Revision = ""
+		options.Follow = true
+
+		logs, err := client.FunctionLogs(options)
+		Expect(err).NotTo(HaveOccurred())
+		line, err := bufio.NewReader(logs).ReadString('\n')
+		Expect(err).NotTo(HaveOccurred())
+		Expect(line).To(Equal("[square-00002-deployment-def] hello from the second revision\n"))
+
+		closed := make(chan struct{})
+		go func() {
+			defer close(closed)
+			logs.Close()
+		}()
+		Eventually(closed).Should(BeClosed())
+		Eventually(disconnected).Should(BeClosed())
+	})
+
+	It("should fail when the revision has no pods", func() {
+		_, err := client.FunctionLogs(options)
+		Expect(err).To(MatchError(`revision "square-00001" has no pods, it may have been scaled to zero`))
+	})
+
+	It("should fail for a missing revision", func() {
+		options.Revision = "square-00009"
+
+		_, err := client.FunctionLogs(options)
+		Expect(err).To(MatchError(`revision "square-00009" does not exist in namespace "ns"`))
+	})
+
+	It("should fail for a revision of another function", func() {
+		options.Revision = "cube-00001"
+
+		_, err := client.FunctionLogs(options)
+		Expect(err).To(MatchError(`revision "cube-00001" is not a revision of function "square"`))
+	})
+})
@@ -0,0 +1,86 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	core_v1 "k8s.io/api/core/v1"
+)
+
+// FunctionState is the readiness of a function, as reported by the Ready condition of its service.
+type FunctionState string
+
+const (
+	FunctionReady    FunctionState = "Ready"
+	FunctionNotReady FunctionState = "NotReady"
+	// FunctionUnknown is the state of functions that knative hasn't reconciled yet, or is still reconciling
+	FunctionUnknown FunctionState = "Unknown"
+)
+
+// FunctionStatus is a compact summary of the status of a function.
+type FunctionStatus struct {
+	Namespace string
+	Name      string
+	State     FunctionState
+	// Reason and Message tell why the function is not ready, if known
+	Reason  string
+	Message string
+	// LatestRevision is the name of the latest revision created for the function, empty if there is none yet
+	LatestRevision string
+	// URL is where the function is available through the ingress gateway, empty until knative routes it
+	URL string
+}
+
+// FunctionStatuses lists functions as ListFunctions does, and summarizes the status of each of them. Everything is
+// computed from the listed services, so that a single API call is made however many functions there are.
+func (c *client) FunctionStatuses(options ListFunctionOptions) ([]FunctionStatus, error) {
+	functions, err := c.ListFunctions(options)
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := make([]FunctionStatus, 0, len(functions.Items))
+	for i := range functions.Items {
+		statuses = append(statuses, functionStatus(&functions.Items[i]))
+	}
+	return statuses, nil
+}
+
+func functionStatus(s *v1alpha1.Service) FunctionStatus {
+	status := FunctionStatus{
+		Namespace:      s.Namespace,
+		Name:           s.Name,
+		State:          FunctionUnknown,
+		LatestRevision: s.Status.LatestCreatedRevisionName,
+	}
+	if s.Status.Domain != "" {
+		status.URL = "http://" + s.Status.Domain
+	}
+	if cond := s.Status.GetCondition(v1alpha1.ServiceConditionReady); cond != nil {
+		switch cond.Status {
+		case core_v1.ConditionTrue:
+			status.State = FunctionReady
+		case core_v1.ConditionFalse:
+			status.State = FunctionNotReady
+		}
+		if status.State != FunctionReady {
+			status.Reason = cond.Reason
+			status.Message = cond.Message
+		}
+	}
+	return status
+}
@@ -0,0 +1,81 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/rest"
+)
+
+const functionStatusesList = `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"ServiceList","items":[
+	{"metadata":{"name":"square","namespace":"ns"},"status":{"domain":"square.ns.example.com","latestCreatedRevisionName":"square-00002",
+		"conditions":[{"type":"Ready","status":"True"}]}},
+	{"metadata":{"name":"cube","namespace":"ns"},"status":{"latestCreatedRevisionName":"cube-00001",
+		"conditions":[{"type":"Ready","status":"False","reason":"RevisionFailed","message":"image not found"}]}},
+	{"metadata":{"name":"half","namespace":"ns"}}
+]}`
+
+var _ = Describe("Summarizing the status of functions", func() {
+
+	var (
+		server   *httptest.Server
+		requests []string
+		client   core.Client
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			if r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			fmt.Fprint(w, functionStatusesList)
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should summarize all functions with a single call", func() {
+		options := core.ListFunctionOptions{}
+		options.Namespace = "ns"
+
+		statuses, err := client.FunctionStatuses(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(statuses).To(Equal([]core.FunctionStatus{
+			{Namespace: "ns", Name: "cube", State: core.FunctionNotReady, Reason: "RevisionFailed", Message: "image not found", LatestRevision: "cube-00001"},
+			{Namespace: "ns", Name: "half", State: core.FunctionUnknown},
+			{Namespace: "ns", Name: "square", State: core.FunctionReady, LatestRevision: "square-00002", URL: "http://square.ns.example.com"},
+		}))
+		Expect(requests).To(Equal([]string{"GET /apis/serving.knative.dev/v1alpha1/namespaces/ns/services"}))
+	})
+})
@@ -0,0 +1,1275 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"time"
+
+	build "github.com/knative/build/pkg/apis/build/v1alpha1"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	utilerrors "k8s.io/apimachinery/pkg/util/errors"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Listing functions", func() {
+
+	var client core.Client
+
+	BeforeEach(func() {
+		// malformed selectors are reported before reaching the API server
+		client = core.NewClient(nil, nil, nil, nil)
+	})
+
+	It("should reject malformed label selectors", func() {
+		_, err := client.ListFunctions(core.ListFunctionOptions{AllNamespaces: true, LabelSelector: "=payments"})
+		Expect(err).To(MatchError(HavePrefix(`invalid label selector "=payments": `)))
+	})
+
+	It("should reject malformed field selectors", func() {
+		_, err := client.ListFunctions(core.ListFunctionOptions{AllNamespaces: true, FieldSelector: "metadata.name"})
+		Expect(err).To(MatchError(HavePrefix(`invalid field selector "metadata.name": `)))
+	})
+
+	It("should reject malformed selectors when watching", func() {
+		_, err := client.WatchFunctions(core.WatchFunctionsOptions{AllNamespaces: true, LabelSelector: "team in (payments"})
+		Expect(err).To(MatchError(HavePrefix(`invalid label selector "team in (payments": `)))
+	})
+})
+
+var _ = Describe("Creating several functions", func() {
+
+	var (
+		client    core.Client
+		functions []core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		functions = nil
+		for _, name := range []string{"square", "cube", "Double", "half"} {
+			options := core.CreateFunctionOptions{}
+			options.Namespace = "ns"
+			options.Name = strings.ToLower(name)
+			options.Image = "acme/" + name
+			options.DryRun = true
+			functions = append(functions, options)
+		}
+	})
+
+	It("should create all functions and report failures in order", func() {
+		results, err := client.CreateFunctions(context.Background(), core.CreateFunctionsOptions{Functions: functions, Parallelism: 2})
+		Expect(err).To(MatchError(`unable to create function "double": invalid image reference 'acme/Double', the repository name must be lowercase`))
+
+		Expect(results).To(HaveLen(4))
+		for i, result := range results {
+			Expect(result.Name).To(Equal(functions[i].Name))
+			if i == 2 {
+				Expect(result.Function).To(BeNil())
+				Expect(result.Err).To(HaveOccurred())
+			} else {
+				Expect(result.Err).NotTo(HaveOccurred())
+				Expect(result.Function.Name).To(Equal(functions[i].Name))
+			}
+		}
+	})
+
+	It("should skip all functions once the context is done", func() {
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		results, err := client.CreateFunctions(ctx, core.CreateFunctionsOptions{Functions: functions})
+		Expect(err).To(HaveOccurred())
+		for _, result := range results {
+			Expect(result.Err).To(Equal(context.Canceled))
+		}
+	})
+})
+
+var _ = Describe("Deleting several functions", func() {
+
+	var (
+		server   *httptest.Server
+		requests []string
+		client   core.Client
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch r.URL.Path {
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square", "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/double":
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/cube":
+				w.WriteHeader(http.StatusInternalServerError)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"InternalError","code":500,"message":"etcd is unavailable"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should delete all functions it can and report the others at once", func() {
+		options := core.DeleteFunctionsOptions{Names: []string{"square", "half", "cube", "double"}}
+		options.Namespace = "ns"
+
+		err := client.DeleteFunctions(context.Background(), options)
+		Expect(requests).To(Equal([]string{
+			"DELETE /apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square",
+			"DELETE /apis/serving.knative.dev/v1alpha1/namespaces/ns/services/half",
+			"DELETE /apis/serving.knative.dev/v1alpha1/namespaces/ns/services/cube",
+			"DELETE /apis/serving.knative.dev/v1alpha1/namespaces/ns/services/double",
+		}))
+		aggregate, ok := err.(utilerrors.Aggregate)
+		Expect(ok).To(BeTrue(), "expected an aggregate error, got %v", err)
+		var messages []string
+		for _, e := range aggregate.Errors() {
+			messages = append(messages, e.Error())
+		}
+		Expect(messages).To(Equal([]string{
+			`unable to delete function "half": function "half" does not exist in namespace "ns"`,
+			`unable to delete function "cube": etcd is unavailable`,
+		}))
+	})
+
+	It("should succeed when all functions are deleted", func() {
+		options := core.DeleteFunctionsOptions{Names: []string{"square", "double"}}
+		options.Namespace = "ns"
+
+		Expect(client.DeleteFunctions(context.Background(), options)).To(Succeed())
+	})
+})
+
+var _ = Describe("Overriding the function entrypoint", func() {
+
+	var (
+		client  core.Client
+		options core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		options = core.CreateFunctionOptions{}
+		options.Namespace = "ns"
+		options.Name = "square"
+		options.Image = "acme/square"
+		options.DryRun = true
+	})
+
+	It("should set the command and arguments of the container", func() {
+		options.Command = []string{"/bin/square"}
+		options.Args = []string{"--port=8080"}
+
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		container := s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container
+		Expect(container.Command).To(Equal([]string{"/bin/square"}))
+		Expect(container.Args).To(Equal([]string{"--port=8080"}))
+	})
+
+	It("should reject arguments without a command", func() {
+		options.Args = []string{"--port=8080"}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError("arguments require a command, as the entrypoint of image 'acme/square' can't be determined"))
+	})
+
+	It("should reject a command without executable", func() {
+		options.Command = []string{""}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError("the command must start with the executable to run, got an empty string"))
+	})
+})
+
+var _ = Describe("Setting environment variables reserved by knative", func() {
+
+	var (
+		client  core.Client
+		options core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		options = core.CreateFunctionOptions{}
+		options.Namespace = "ns"
+		options.Name = "square"
+		options.Image = "acme/square"
+		options.DryRun = true
+		options.Env = []string{"K_SERVICE=other", "FOO=bar", "PORT=9090"}
+	})
+
+	It("should fail, naming the reserved variables", func() {
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError("environment variables reserved by knative can't be set: PORT, K_SERVICE; pass --allow-reserved-env to set them anyway"))
+		Expect(err.(*core.ReservedEnvVarError).Names).To(Equal([]string{"PORT", "K_SERVICE"}))
+	})
+
+	It("should detect reserved variables set from a source", func() {
+		options.Env = nil
+		options.EnvFrom = []string{"PORT=configMapKeyRef:config:port"}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(BeAssignableToTypeOf(&core.ReservedEnvVarError{}))
+	})
+
+	It("should set them when allowed to", func() {
+		options.AllowReservedEnv = true
+
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Env).To(HaveLen(3))
+	})
+})
+
+var _ = Describe("Setting pod labels and annotations", func() {
+
+	var (
+		client  core.Client
+		options core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		options = core.CreateFunctionOptions{}
+		options.Namespace = "ns"
+		options.Name = "square"
+		options.Image = "acme/square"
+		options.DryRun = true
+	})
+
+	It("should set them on the revision template only", func() {
+		options.Labels = map[string]string{"team": "math"}
+		options.PodLabels = map[string]string{"tier": "backend"}
+		options.PodAnnotations = map[string]string{"sidecar.istio.io/inject": "true"}
+		options.MinScale = 1
+
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		template := s.Spec.RunLatest.Configuration.RevisionTemplate
+		Expect(template.Labels).To(Equal(map[string]string{"tier": "backend"}))
+		Expect(template.Annotations).To(Equal(map[string]string{
+			"sidecar.istio.io/inject":          "true",
+			"autoscaling.knative.dev/minScale": "1",
+		}))
+		Expect(s.Labels).NotTo(HaveKey("tier"))
+		Expect(s.Annotations).To(BeEmpty())
+	})
+
+	It("should reject invalid keys", func() {
+		options.PodLabels = map[string]string{"tier!": "backend"}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(HavePrefix(`invalid label key "tier!": `)))
+	})
+
+	It("should not override the scale annotations", func() {
+		options.PodAnnotations = map[string]string{"autoscaling.knative.dev/maxScale": "10"}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(`pod annotation "autoscaling.knative.dev/maxScale" can't be set, use the min and max scale options instead`))
+	})
+
+	It("should not override the labels knative sets", func() {
+		options.PodLabels = map[string]string{"serving.knative.dev/configuration": "other"}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(`pod label "serving.knative.dev/configuration" can't be set, serving.knative.dev/ labels are managed by knative`))
+	})
+})
+
+var _ = Describe("Checking whether functions exist", func() {
+
+	var (
+		server *httptest.Server
+		client core.Client
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"}}`)
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/locked/services/square":
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","message":"services \"square\" is forbidden","code":403}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should find an existing function", func() {
+		exists, err := client.FunctionExists(core.FunctionExistsOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Name: "square"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeTrue())
+	})
+
+	It("should not report a missing function as an error", func() {
+		exists, err := client.FunctionExists(core.FunctionExistsOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Name: "cube"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(exists).To(BeFalse())
+	})
+
+	It("should propagate other errors", func() {
+		exists, err := client.FunctionExists(core.FunctionExistsOptions{Namespaced: core.Namespaced{Namespace: "locked"}, Name: "square"})
+		Expect(err).To(MatchError(`services "square" is forbidden`))
+		Expect(exists).To(BeFalse())
+	})
+})
+
+var _ = Describe("Creating functions in missing namespaces", func() {
+
+	var (
+		server     *httptest.Server
+		requests   []string
+		namespaces map[string]bool
+		existing   bool
+		// createErrors are the reasons the next creations of services fail with, in order
+		createErrors []string
+		client       core.Client
+		options      core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+		namespaces = map[string]bool{"default": true}
+		existing = false
+		createErrors = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch {
+			case r.URL.Path == "/api":
+				fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+			case r.URL.Path == "/apis":
+				fmt.Fprint(w, servingGroups("v1alpha1"))
+			case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces":
+				namespaces["numbers"] = true
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"numbers"}}`)
+			case strings.HasPrefix(r.URL.Path, "/api/v1/namespaces/"):
+				name := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+				if !namespaces[name] {
+					w.WriteHeader(http.StatusNotFound)
+					fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"namespaces \"%s\" not found"}`, name)
+					return
+				}
+				fmt.Fprintf(w, `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"%s"}}`, name)
+			case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/services") && len(createErrors) > 0:
+				reason := createErrors[0]
+				createErrors = createErrors[1:]
+				code := map[string]int{"Conflict": http.StatusConflict, "ServerTimeout": http.StatusInternalServerError}[reason]
+				w.WriteHeader(code)
+				fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"%s","code":%d}`, reason, code)
+			case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/services") && existing:
+				w.WriteHeader(http.StatusConflict)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"AlreadyExists","code":409,"message":"services.serving.knative.dev \"square\" already exists"}`)
+			case r.Method == http.MethodPost && strings.HasSuffix(r.URL.Path, "/services"):
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square"}}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.CreateFunctionOptions{}
+		options.Namespace = "numbers"
+		options.Name = "square"
+		options.Image = "acme/square"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should report the namespace to be missing", func() {
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(`namespace "numbers" does not exist; create it first or pass --create-namespace`))
+		Expect(requests).NotTo(ContainElement("POST /apis/serving.knative.dev/v1alpha1/namespaces/numbers/services"))
+	})
+
+	It("should create the namespace when asked to", func() {
+		options.CreateNamespace = true
+
+		_, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(ContainElement("POST /api/v1/namespaces"))
+		Expect(requests).To(ContainElement("POST /apis/serving.knative.dev/v1alpha1/namespaces/numbers/services"))
+	})
+
+	It("should check a custom build template is installed", func() {
+		options.Namespace = "default"
+		options.GitRepo = "https://github.com/acme/square"
+		options.BuildTemplate = "kaniko"
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(`build template "kaniko" is not installed in namespace "default"`))
+	})
+
+	It("should leave existing namespaces alone", func() {
+		options.Namespace = "default"
+		options.CreateNamespace = true
+
+		_, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).NotTo(ContainElement("POST /api/v1/namespaces"))
+	})
+
+	It("should retry creations failing with a transient error", func() {
+		options.Namespace = "default"
+		options.CreateBackoff = &wait.Backoff{Steps: 3, Duration: time.Millisecond}
+		createErrors = []string{"ServerTimeout"}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(countRequests(requests, "POST /apis/serving.knative.dev/v1alpha1/namespaces/default/services")).To(Equal(2))
+	})
+
+	It("should retry creations that conflict", func() {
+		options.Namespace = "default"
+		options.CreateBackoff = &wait.Backoff{Steps: 3, Duration: time.Millisecond}
+		createErrors = []string{"Conflict"}
+
+		_, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(countRequests(requests, "POST /apis/serving.knative.dev/v1alpha1/namespaces/default/services")).To(Equal(2))
+	})
+
+	It("should report a function that already exists", func() {
+		options.Namespace = "default"
+		existing = true
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(&core.FunctionAlreadyExistsError{Name: "square", Namespace: "default"}))
+		Expect(err).To(MatchError(`function "square" already exists in namespace "default"`))
+		Expect(core.IsAlreadyExists(err)).To(BeTrue())
+		Expect(countRequests(requests, "POST /apis/serving.knative.dev/v1alpha1/namespaces/default/services")).To(Equal(1))
+	})
+})
+
+var _ = Describe("Creating functions in server-side dry run mode", func() {
+
+	var (
+		server        *httptest.Server
+		serverVersion string
+		requests      []string
+		client        core.Client
+		options       core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		serverVersion = `{"major":"1","minor":"13","gitVersion":"v1.13.1"}`
+		requests = []string{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			requests = append(requests, r.Method+" "+r.URL.RequestURI())
+			switch r.URL.Path {
+			case "/version":
+				fmt.Fprint(w, serverVersion)
+			case "/api":
+				fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+			case "/apis":
+				fmt.Fprint(w, servingGroups("v1alpha1"))
+			case "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services":
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns","annotations":{"serving.knative.dev/creator":"joseph"}}}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.CreateFunctionOptions{ServerDryRun: true, CreateNamespace: true, PullSecrets: []string{"registry"}}
+		options.Namespace = "ns"
+		options.Name = "square"
+		options.Image = "acme/square"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should return the service as the server would have created it", func() {
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Annotations).To(HaveKeyWithValue("serving.knative.dev/creator", "joseph"))
+		Expect(requests).To(ContainElement("POST /apis/serving.knative.dev/v1alpha1/namespaces/ns/services?dryRun=All"))
+	})
+
+	It("should not change anything else in the cluster", func() {
+		_, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		for _, request := range requests {
+			if strings.HasPrefix(request, "GET ") {
+				continue
+			}
+			Expect(request).To(HaveSuffix("?dryRun=All"))
+		}
+	})
+
+	It("should refuse clusters not supporting server-side dry run", func() {
+		serverVersion = `{"major":"1","minor":"11+","gitVersion":"v1.11.5-gke.5"}`
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError("server-side dry run requires kubernetes 1.13 or later, the cluster runs v1.11.5-gke.5"))
+		Expect(requests).NotTo(ContainElement(HavePrefix("POST ")))
+	})
+
+	It("should not be supported when applying functions", func() {
+		_, _, err := client.ApplyFunction(options)
+		Expect(err).To(MatchError("server-side dry run is only supported when creating functions"))
+	})
+})
+
+var _ = Describe("Replacing functions", func() {
+
+	var (
+		server    *httptest.Server
+		conflicts int
+		replaced  []string
+		client    core.Client
+		function  *v1alpha1.Service
+	)
+
+	BeforeEach(func() {
+		conflicts = 0
+		replaced = []string{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns","resourceVersion":"%d"}}`, 42+len(replaced))
+			case http.MethodPut:
+				body, _ := ioutil.ReadAll(r.Body)
+				s := v1alpha1.Service{}
+				Expect(json.Unmarshal(body, &s)).To(Succeed())
+				replaced = append(replaced, s.ResourceVersion)
+				if conflicts > 0 {
+					conflicts--
+					w.WriteHeader(http.StatusConflict)
+					fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Conflict","code":409,"message":"the object has been modified"}`)
+					return
+				}
+				w.Write(body)
+			}
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+
+		function = &v1alpha1.Service{}
+		function.Name = "square"
+		function.Spec.RunLatest = &v1alpha1.RunLatestType{}
+		function.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image = "acme/square:2.0"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should replace the function with the resource version of the existing one", func() {
+		s, err := client.ReplaceFunction(core.ReplaceFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Function: function})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replaced).To(Equal([]string{"42"}))
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square:2.0"))
+		Expect(s.Labels).To(HaveKeyWithValue("riff.projectriff.io/function", "square"))
+		Expect(function.ResourceVersion).To(BeEmpty())
+	})
+
+	It("should keep the resource version of the given function", func() {
+		function.Namespace = "ns"
+		function.ResourceVersion = "40"
+
+		_, err := client.ReplaceFunction(core.ReplaceFunctionOptions{Function: function})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replaced).To(Equal([]string{"40"}))
+	})
+
+	It("should retry once against the latest version on conflict", func() {
+		conflicts = 1
+
+		_, err := client.ReplaceFunction(core.ReplaceFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Function: function})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(replaced).To(Equal([]string{"42", "43"}))
+	})
+
+	It("should not overwrite changes made since the given resource version", func() {
+		function.ResourceVersion = "40"
+		conflicts = 1
+
+		_, err := client.ReplaceFunction(core.ReplaceFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Function: function})
+		Expect(errors.IsConflict(err)).To(BeTrue())
+		Expect(replaced).To(Equal([]string{"40"}))
+	})
+
+	It("should give up after a second conflict", func() {
+		conflicts = 2
+
+		_, err := client.ReplaceFunction(core.ReplaceFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Function: function})
+		Expect(err).To(MatchError("the object has been modified"))
+		Expect(replaced).To(HaveLen(2))
+	})
+
+	It("should fail for a missing function", func() {
+		function.Name = "cube"
+
+		_, err := client.ReplaceFunction(core.ReplaceFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Function: function})
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+
+	It("should refuse a function from another namespace", func() {
+		function.Namespace = "other"
+
+		_, err := client.ReplaceFunction(core.ReplaceFunctionOptions{Namespaced: core.Namespaced{Namespace: "ns"}, Function: function})
+		Expect(err).To(MatchError(`function "square" is in namespace "other", not "ns"`))
+	})
+})
+
+var _ = Describe("Scaling functions", func() {
+
+	var (
+		server  *httptest.Server
+		updated *v1alpha1.Service
+		client  core.Client
+	)
+
+	BeforeEach(func() {
+		updated = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"},`+
+					`"spec":{"runLatest":{"configuration":{"revisionTemplate":{"metadata":{"annotations":{"autoscaling.knative.dev/maxScale":"3","team":"numbers"}},`+
+					`"spec":{"container":{"image":"acme/square"}}}}}}}`)
+			case http.MethodPut:
+				body, _ := ioutil.ReadAll(r.Body)
+				updated = &v1alpha1.Service{}
+				Expect(json.Unmarshal(body, updated)).To(Succeed())
+				w.Write(body)
+			}
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	scale := func(name string, minScale int, maxScale int) (*v1alpha1.Service, error) {
+		options := core.ScaleFunctionOptions{Name: name, MinScale: minScale, MaxScale: maxScale}
+		options.Namespace = "ns"
+		return client.ScaleFunction(options)
+	}
+
+	It("should set the scale annotations of the revision template", func() {
+		s, err := scale("square", 2, 5)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Annotations).To(Equal(map[string]string{
+			"autoscaling.knative.dev/minScale": "2",
+			"autoscaling.knative.dev/maxScale": "5",
+			"team":                             "numbers",
+		}))
+		Expect(updated.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square"))
+	})
+
+	It("should remove the bounds set to 0", func() {
+		s, err := scale("square", 1, 0)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Annotations).To(Equal(map[string]string{
+			"autoscaling.knative.dev/minScale": "1",
+			"team":                             "numbers",
+		}))
+	})
+
+	It("should reject negative bounds", func() {
+		_, err := scale("square", -1, 2)
+		Expect(err).To(MatchError("min and max scale must not be negative, got -1 and 2"))
+		Expect(updated).To(BeNil())
+	})
+
+	It("should reject a minimum above the maximum", func() {
+		_, err := scale("square", 5, 2)
+		Expect(err).To(MatchError("min scale (5) must not be greater than max scale (2)"))
+		Expect(updated).To(BeNil())
+	})
+
+	It("should fail for a missing function", func() {
+		_, err := scale("cube", 1, 2)
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+})
+
+var _ = Describe("Setting the image of functions", func() {
+
+	var (
+		server    *httptest.Server
+		conflicts int
+		puts      int
+		updated   *v1alpha1.Service
+		client    core.Client
+	)
+
+	BeforeEach(func() {
+		conflicts, puts, updated = 0, 0, nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			switch r.Method {
+			case http.MethodGet:
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"},`+
+					`"spec":{"runLatest":{"configuration":{`+
+					`"build":{"template":{"name":"riff","arguments":[{"name":"IMAGE","value":"acme/square:v1"}]}},`+
+					`"revisionTemplate":{"metadata":{"annotations":{"autoscaling.knative.dev/maxScale":"3"}},`+
+					`"spec":{"container":{"image":"acme/square:v1","env":[{"name":"A","value":"1"}]}}}}}}}`)
+			case http.MethodPut:
+				puts++
+				if conflicts > 0 {
+					conflicts--
+					w.WriteHeader(http.StatusConflict)
+					fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Conflict","code":409}`)
+					return
+				}
+				body, _ := ioutil.ReadAll(r.Body)
+				updated = &v1alpha1.Service{}
+				Expect(json.Unmarshal(body, updated)).To(Succeed())
+				w.Write(body)
+			}
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	setImage := func(name string, image string) (*v1alpha1.Service, error) {
+		options := core.SetFunctionImageOptions{Name: name, Image: image}
+		options.Namespace = "ns"
+		return client.SetFunctionImage(options)
+	}
+
+	It("should only change the image", func() {
+		_, err := setImage("square", "acme/square:v2")
+		Expect(err).NotTo(HaveOccurred())
+		configuration := updated.Spec.RunLatest.Configuration
+		Expect(configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square:v2"))
+		Expect(configuration.Build.Template.Arguments[0].Value).To(Equal("acme/square:v2"))
+		Expect(configuration.RevisionTemplate.Spec.Container.Env).To(HaveLen(1))
+		Expect(configuration.RevisionTemplate.Annotations).To(HaveKeyWithValue("autoscaling.knative.dev/maxScale", "3"))
+	})
+
+	It("should try again on conflicts", func() {
+		conflicts = 2
+		_, err := setImage("square", "acme/square:v2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(puts).To(Equal(3))
+	})
+
+	It("should give up after repeated conflicts", func() {
+		conflicts = 10
+		_, err := setImage("square", "acme/square:v2")
+		Expect(err).To(HaveOccurred())
+		Expect(puts).To(Equal(5))
+	})
+
+	It("should not update a function already running the image", func() {
+		s, err := setImage("square", "acme/square:v1")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square:v1"))
+		Expect(puts).To(BeZero())
+	})
+
+	It("should reject invalid image references", func() {
+		_, err := setImage("square", "acme/Square")
+		Expect(err).To(HaveOccurred())
+		Expect(puts).To(BeZero())
+	})
+
+	It("should fail for a missing function", func() {
+		_, err := setImage("cube", "acme/cube:v2")
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+})
+
+var _ = Describe("Patching functions", func() {
+
+	var (
+		server      *httptest.Server
+		contentType string
+		patch       []byte
+		client      core.Client
+	)
+
+	BeforeEach(func() {
+		contentType, patch = "", nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.Method != http.MethodPatch || r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			contentType = r.Header.Get("Content-Type")
+			patch, _ = ioutil.ReadAll(r.Body)
+			fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"},`+
+				`"spec":{"runLatest":{"configuration":{"revisionTemplate":{"spec":{"container":{"image":"acme/square:v2"}}}}}}}`)
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	runLatest := func(name string) *v1alpha1.Service {
+		return &v1alpha1.Service{
+			ObjectMeta: meta_v1.ObjectMeta{Name: name, Namespace: "ns"},
+			Spec:       v1alpha1.ServiceSpec{RunLatest: &v1alpha1.RunLatestType{}},
+		}
+	}
+
+	patchImage := func(name string, image string) (*v1alpha1.Service, error) {
+		p, err := core.FunctionImagePatch(runLatest(name), image)
+		Expect(err).NotTo(HaveOccurred())
+		options := core.PatchFunctionOptions{Name: name, Patch: p, PatchType: types.MergePatchType}
+		options.Namespace = "ns"
+		return client.PatchFunction(options)
+	}
+
+	It("should send the patch without reading the function first", func() {
+		s, err := patchImage("square", "acme/square:v2")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square:v2"))
+		Expect(contentType).To(Equal("application/merge-patch+json"))
+		Expect(patch).To(MatchJSON(`{"spec":{"runLatest":{"configuration":{"revisionTemplate":{"spec":{"container":{"image":"acme/square:v2"}}}}}}}`))
+	})
+
+	It("should fail for a missing function", func() {
+		_, err := patchImage("cube", "acme/cube:v2")
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+
+	It("should reject an empty image", func() {
+		_, err := core.FunctionImagePatch(runLatest("square"), "")
+		Expect(err).To(MatchError("image must not be empty"))
+	})
+
+	It("should refuse pinned functions, which would keep serving their pinned revision", func() {
+		pinned := &v1alpha1.Service{
+			ObjectMeta: meta_v1.ObjectMeta{Name: "square", Namespace: "ns"},
+			Spec:       v1alpha1.ServiceSpec{Pinned: &v1alpha1.PinnedType{RevisionName: "square-00001"}},
+		}
+		_, err := core.FunctionImagePatch(pinned, "acme/square:v2")
+		Expect(err).To(MatchError(`function "square" is pinned to revision "square-00001", only functions running the latest revision can have their image patched`))
+		Expect(patch).To(BeNil())
+	})
+})
+
+var _ = Describe("Describing created functions", func() {
+
+	var (
+		server       *httptest.Server
+		domainConfig string
+		client       core.Client
+		options      core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		domainConfig = ""
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/api":
+				fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+			case r.URL.Path == "/apis":
+				fmt.Fprint(w, servingGroups("v1alpha1"))
+			case r.URL.Path == "/api/v1/namespaces/knative-serving/configmaps/config-domain" && domainConfig != "":
+				fmt.Fprint(w, domainConfig)
+			case r.URL.Path == "/api/v1/namespaces/ns":
+				fmt.Fprint(w, `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"ns"}}`)
+			case r.Method == http.MethodPost && r.URL.Path == "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services":
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"}}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.CreateFunctionOptions{}
+		options.Namespace = "ns"
+		options.Name = "square"
+		options.Image = "acme/square"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should use the default domain configured in knative serving", func() {
+		domainConfig = `{"apiVersion":"v1","kind":"ConfigMap","metadata":{"name":"config-domain"},
+			"data":{"acme.io":"","prod.acme.io":"selector:\n  app: prod\n"}}`
+
+		result, err := client.CreateFunctionWithResult(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Function.Name).To(Equal("square"))
+		Expect(result.RevisionName).To(Equal("square-00001"))
+		Expect(result.URL).To(Equal("http://square.ns.acme.io"))
+	})
+
+	It("should fall back to the knative default domain", func() {
+		result, err := client.CreateFunctionWithResult(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.URL).To(Equal("http://square.ns.example.com"))
+	})
+
+	It("should use the cluster-local domain for cluster-local functions", func() {
+		options.ClusterLocal = true
+
+		result, err := client.CreateFunctionWithResult(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.URL).To(Equal("http://square.ns.svc.cluster.local"))
+	})
+
+	It("should label cluster-local functions", func() {
+		options.ClusterLocal = true
+		options.Labels = map[string]string{"serving.knative.dev/visibility": "public", "team": "numbers"}
+		options.DryRun = true
+
+		result, err := client.CreateFunctionWithResult(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.Function.Labels).To(Equal(map[string]string{
+			"riff.projectriff.io/function":   "square",
+			"serving.knative.dev/visibility": "cluster-local",
+			"team":                           "numbers",
+		}))
+	})
+
+	It("should not compute the URL of dry runs", func() {
+		options.DryRun = true
+
+		result, err := core.NewClient(nil, nil, nil, nil).CreateFunctionWithResult(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(result.RevisionName).To(Equal("square-00001"))
+		Expect(result.URL).To(BeEmpty())
+	})
+})
+
+var _ = Describe("Building functions with a build template", func() {
+
+	var (
+		client  core.Client
+		options core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		options = core.CreateFunctionOptions{
+			GitRepo:     "https://github.com/acme/square",
+			GitRevision: "v1",
+			InvokerURL:  "https://github.com/projectriff/node-function-invoker/raw/v0.0.8/node-invoker.yaml",
+		}
+		options.Namespace = "ns"
+		options.Name = "square"
+		options.Image = "acme/square"
+		options.DryRun = true
+	})
+
+	It("should build with the riff template by default", func() {
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		template := s.Spec.RunLatest.Configuration.Build.Template
+		Expect(template.Name).To(Equal("riff"))
+		Expect(template.Arguments).To(ContainElement(build.ArgumentSpec{Name: "INVOKER_PATH", Value: options.InvokerURL}))
+	})
+
+	It("should only give the image to other templates", func() {
+		options.BuildTemplate = "kaniko"
+
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		b := s.Spec.RunLatest.Configuration.Build
+		Expect(b.Source.Git).To(Equal(&build.GitSourceSpec{Url: "https://github.com/acme/square", Revision: "v1"}))
+		Expect(b.Template).To(Equal(&build.TemplateInstantiationSpec{
+			Name:      "kaniko",
+			Arguments: []build.ArgumentSpec{{Name: "IMAGE", Value: "acme/square"}},
+		}))
+	})
+
+	It("should require a git repository", func() {
+		options.GitRepo = ""
+		options.BuildTemplate = "kaniko"
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(`build template "kaniko" requires a git repository to build the function from`))
+	})
+
+	It("should reject invalid template names", func() {
+		options.BuildTemplate = "Kaniko"
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError(HavePrefix(`invalid build template name "Kaniko": `)))
+	})
+})
+
+var _ = Describe("Waiting for functions created asynchronously", func() {
+
+	var (
+		server *httptest.Server
+		client core.Client
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/api":
+				fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+			case r.URL.Path == "/apis":
+				fmt.Fprint(w, servingGroups("v1alpha1"))
+			case r.URL.Path == "/api/v1/namespaces/ns":
+				fmt.Fprint(w, `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"ns"}}`)
+			case r.Method == http.MethodPost && r.URL.Path == "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services":
+				w.WriteHeader(http.StatusCreated)
+				body, _ := ioutil.ReadAll(r.Body)
+				w.Write(body)
+			case r.URL.Query().Get("watch") == "true":
+				name := strings.TrimPrefix(r.URL.Query().Get("fieldSelector"), "metadata.name=")
+				status, reason := "True", ""
+				if name == "cube" {
+					status, reason = "False", "RevisionFailed"
+				}
+				fmt.Fprintf(w, `{"type":"ADDED","object":{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"%s","namespace":"ns"},`+
+					`"status":{"conditions":[{"type":"Ready","status":"%s","reason":"%s","message":"oops"}]}}}`+"\n", name, status, reason)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	function := func(name string) core.CreateFunctionOptions {
+		options := core.CreateFunctionOptions{}
+		options.Namespace = "ns"
+		options.Name = name
+		options.Image = "acme/" + name
+		return options
+	}
+
+	It("should return a handle to wait for the function", func() {
+		handle, err := client.CreateFunctionAsync(function("square"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handle.Name).To(Equal("square"))
+		Expect(handle.Namespace).To(Equal("ns"))
+		Expect(handle.Function.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square"))
+
+		Expect(handle.Wait(context.Background())).To(Succeed())
+	})
+
+	It("should not wait for functions created in dry run mode", func() {
+		options := function("square")
+		options.Namespace = "elsewhere"
+		options.DryRun = true
+
+		handle, err := client.CreateFunctionAsync(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(handle.Wait(context.Background())).To(Succeed())
+	})
+
+	It("should wait for all the functions created at once", func() {
+		results, err := client.CreateFunctions(context.Background(), core.CreateFunctionsOptions{
+			Functions:   []core.CreateFunctionOptions{function("square"), function("cube"), function("double")},
+			Parallelism: 3,
+		})
+		Expect(err).NotTo(HaveOccurred())
+
+		handles := []*core.FunctionHandle{}
+		for _, result := range results {
+			handles = append(handles, result.Handle)
+		}
+		err = core.WaitForFunctions(context.Background(), handles...)
+		Expect(err).To(MatchError(`function "cube" failed to become ready: RevisionFailed: oops`))
+	})
+})
+
+var _ = Describe("Waiting for functions to be ready", func() {
+
+	var (
+		server  *httptest.Server
+		lookups int
+		client  core.Client
+		options core.WaitForFunctionReadyOptions
+	)
+
+	BeforeEach(func() {
+		lookups = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Query().Get("watch") == "true":
+				// clusters where functions can't be watched
+				w.WriteHeader(http.StatusForbidden)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Forbidden","code":403}`)
+			case r.URL.Path == "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square":
+				lookups++
+				status := "Unknown"
+				if lookups >= 3 {
+					status = "True"
+				}
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square"},
+					"status":{"conditions":[{"type":"Ready","status":"%s"}]}}`, status)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+
+		options = core.WaitForFunctionReadyOptions{Name: "square", PollInterval: 10 * time.Millisecond}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should poll the function when it can't be watched", func() {
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lookups).To(Equal(3))
+	})
+
+	It("should give up polling once the context is done", func() {
+		options.PollInterval = time.Hour
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := client.WaitForFunctionReady(ctx, options)
+		Expect(err).To(MatchError(`function "square" in namespace "ns" did not become ready: context deadline exceeded`))
+		Expect(err.(*core.WaitError).Cause).To(Equal(context.DeadlineExceeded))
+		Expect(lookups).To(Equal(1))
+	})
+
+	It("should report missing functions", func() {
+		options.Name = "cube"
+
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+
+	It("should reject negative poll intervals", func() {
+		options.PollInterval = -time.Second
+
+		err := client.WaitForFunctionReady(context.Background(), options)
+		Expect(err).To(MatchError("poll interval must be positive, got -1s"))
+	})
+})
+
+func countRequests(requests []string, request string) int {
+	count := 0
+	for _, r := range requests {
+		if r == request {
+			count++
+		}
+	}
+	return count
+}
@@ -0,0 +1,88 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+type FunctionURLOptions struct {
+	Namespaced
+	Name string
+	// ClusterLocal asks for the URL the function is reachable at from within the cluster, rather than through the
+	// ingress gateway
+	ClusterLocal bool
+}
+
+// addressableStatus holds the fields of a service status telling where it is reachable. Newer knative serving
+// versions report URLs, older ones (including the one riff is built against) only report domains, so the status is
+// decoded by hand rather than with the typed client.
+type addressableStatus struct {
+	Status struct {
+		URL     string `json:"url"`
+		Address struct {
+			URL      string `json:"url"`
+			Hostname string `json:"hostname"`
+		} `json:"address"`
+		Domain         string `json:"domain"`
+		DomainInternal string `json:"domainInternal"`
+	} `json:"status"`
+}
+
+// FunctionURL returns the URL a function is reachable at, as reported by knative once its route is ready. A
+// FunctionNotReadyError is returned until then.
+func (c *client) FunctionURL(options FunctionURLOptions) (string, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	raw, err := c.serving.ServingV1alpha1().RESTClient().Get().
+		Namespace(ns).Resource("services").Name(options.Name).DoRaw()
+	if errors.IsNotFound(err) {
+		return "", &FunctionNotFoundError{Name: options.Name, Namespace: ns}
+	} else if err != nil {
+		return "", err
+	}
+	s := addressableStatus{}
+	if err := json.Unmarshal(raw, &s); err != nil {
+		return "", err
+	}
+
+	var url string
+	if options.ClusterLocal {
+		url = urlOrDomain(s.Status.Address.URL, s.Status.Address.Hostname, s.Status.DomainInternal)
+	} else {
+		url = urlOrDomain(s.Status.URL, s.Status.Domain)
+	}
+	if url == "" {
+		return "", &FunctionNotReadyError{Name: options.Name, Namespace: ns}
+	}
+	return url, nil
+}
+
+// urlOrDomain returns the url, or an http URL for the first domain set if there is none.
+func urlOrDomain(url string, domains ...string) string {
+	if url != "" {
+		return url
+	}
+	for _, domain := range domains {
+		if domain != "" {
+			return "http://" + domain
+		}
+	}
+	return ""
+}
@@ -0,0 +1,117 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Resolving function URLs", func() {
+
+	var (
+		server   *httptest.Server
+		statuses map[string]string
+		host     string
+		client   core.Client
+	)
+
+	BeforeEach(func() {
+		statuses = map[string]string{}
+		host = ""
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			const services = "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/"
+			switch {
+			case r.URL.Path == "/api/v1/namespaces/istio-system/services/knative-ingressgateway":
+				fmt.Fprintf(w, `{"apiVersion":"v1","kind":"Service","metadata":{"name":"knative-ingressgateway"},`+
+					`"spec":{"type":"LoadBalancer"},"status":{"loadBalancer":{"ingress":[{"ip":%q}]}}}`, strings.TrimPrefix(server.URL, "http://"))
+			case r.URL.Path == "/invoked":
+				host = r.Host
+				fmt.Fprint(w, "64")
+			case strings.HasPrefix(r.URL.Path, services) && statuses[strings.TrimPrefix(r.URL.Path, services)] != "":
+				name := strings.TrimPrefix(r.URL.Path, services)
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":%q,"namespace":"ns"},"status":%s}`,
+					name, statuses[name])
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	functionURL := func(name string, clusterLocal bool) (string, error) {
+		options := core.FunctionURLOptions{Name: name, ClusterLocal: clusterLocal}
+		options.Namespace = "ns"
+		return client.FunctionURL(options)
+	}
+
+	It("should use the domains of older knative versions", func() {
+		statuses["square"] = `{"domain":"square.ns.example.com","domainInternal":"square.ns.svc.cluster.local"}`
+		Expect(functionURL("square", false)).To(Equal("http://square.ns.example.com"))
+		Expect(functionURL("square", true)).To(Equal("http://square.ns.svc.cluster.local"))
+	})
+
+	It("should prefer the URLs of newer knative versions", func() {
+		statuses["square"] = `{"url":"https://square.ns.example.com","domain":"square.ns.example.com",` +
+			`"address":{"url":"http://square.ns.svc.cluster.local"}}`
+		Expect(functionURL("square", false)).To(Equal("https://square.ns.example.com"))
+		Expect(functionURL("square", true)).To(Equal("http://square.ns.svc.cluster.local"))
+	})
+
+	It("should fail for a function that is not addressable yet", func() {
+		statuses["square"] = `{"conditions":[{"type":"Ready","status":"Unknown"}]}`
+		_, err := functionURL("square", false)
+		Expect(err).To(MatchError(`function "square" in namespace "ns" is not ready yet, it has no URL`))
+		Expect(err).To(BeAssignableToTypeOf(&core.FunctionNotReadyError{}))
+	})
+
+	It("should fail for a missing function", func() {
+		_, err := functionURL("cube", false)
+		Expect(err).To(MatchError(`function "cube" does not exist in namespace "ns"`))
+	})
+
+	It("should invoke functions with the host of their URL", func() {
+		statuses["square"] = `{"url":"http://square.ns.example.com"}`
+		options := core.InvokeFunctionOptions{Name: "square", Method: http.MethodPost, Path: "/invoked", Body: []byte("8")}
+		options.Namespace = "ns"
+		status, body, err := client.InvokeFunction(context.Background(), options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(status).To(Equal(http.StatusOK))
+		Expect(string(body)).To(Equal("64"))
+		Expect(host).To(Equal("square.ns.example.com"))
+	})
+})
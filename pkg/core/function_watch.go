@@ -0,0 +1,208 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"sync"
+
+	"k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/meta"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/watch"
+)
+
+type WatchFunctionsOptions struct {
+	Namespaced
+	AllNamespaces bool
+	LabelSelector string
+	FieldSelector string
+	// ResourceVersion is the version the watch starts from, typically the resource version of the list of functions
+	// just shown so that they are not sent again. When empty, all existing functions are first sent as Added events.
+	ResourceVersion string
+}
+
+// WatchFunctions returns a stream of the changes to functions, further restricted by the optional label and field
+// selectors.
+// When the API server closes the underlying watch (e.g. when restarting), it is re-established from the last seen
+// resource version so that no event is missed. If that version is too old for the server to resume from, functions
+// are listed again and sent as Modified events, before watching from the version of that list. If any of this fails,
+// an Error event is sent and the stream is closed.
+func (c *client) WatchFunctions(options WatchFunctionsOptions) (watch.Interface, error) {
+	ns := meta_v1.NamespaceAll
+	if !options.AllNamespaces {
+		ns = c.explicitOrConfigNamespace(options.Namespaced)
+	}
+
+	listOptions, err := functionListOptions(options.LabelSelector, options.FieldSelector)
+	if err != nil {
+		return nil, err
+	}
+
+	services := c.serving.ServingV1alpha1().Services(ns)
+	open := func(resourceVersion string) (watch.Interface, error) {
+		watchOptions := listOptions
+		watchOptions.ResourceVersion = resourceVersion
+		return services.Watch(watchOptions)
+	}
+	relist := func() ([]watch.Event, string, error) {
+		list, err := services.List(listOptions)
+		if err != nil {
+			return nil, "", err
+		}
+		events := make([]watch.Event, len(list.Items))
+		for i := range list.Items {
+			events[i] = watch.Event{Type: watch.Modified, Object: &list.Items[i]}
+		}
+		return events, list.ResourceVersion, nil
+	}
+
+	w, err := open(options.ResourceVersion)
+	if err != nil {
+		return nil, err
+	}
+	rw := &resumingWatch{
+		open:            open,
+		relist:          relist,
+		resourceVersion: options.ResourceVersion,
+		result:          make(chan watch.Event),
+		done:            make(chan struct{}),
+	}
+	go rw.run(w)
+	return rw, nil
+}
+
+// resumingWatch forwards the events of a watch, re-opening it when it gets closed by the server.
+type resumingWatch struct {
+	open func(resourceVersion string) (watch.Interface, error)
+	// relist returns the current resources, as events, along with the version to watch them from
+	relist          func() ([]watch.Event, string, error)
+	resourceVersion string
+	result          chan watch.Event
+	done            chan struct{}
+	stopOnce        sync.Once
+}
+
+func (rw *resumingWatch) run(w watch.Interface) {
+	defer close(rw.result)
+	for {
+		forwarding, expired := rw.forward(w)
+		if !forwarding {
+			return
+		}
+		var err error
+		if expired {
+			err = rw.resync()
+		}
+		if err == nil {
+			if rw.stopped() {
+				return
+			}
+			w, err = rw.open(rw.resourceVersion)
+			if isExpired(err) {
+				if err = rw.resync(); err == nil {
+					if rw.stopped() {
+						return
+					}
+					w, err = rw.open(rw.resourceVersion)
+				}
+			}
+		}
+		if err != nil {
+			status := meta_v1.Status{Status: meta_v1.StatusFailure, Message: err.Error()}
+			select {
+			case rw.result <- watch.Event{Type: watch.Error, Object: &status}:
+			case <-rw.done:
+			}
+			return
+		}
+	}
+}
+
+// forward sends the events of w until it is closed, returning false if the resuming watch itself got stopped. The
+// second value tells whether w ended because the resource version it watches from is too old, which is not forwarded.
+func (rw *resumingWatch) forward(w watch.Interface) (bool, bool) {
+	defer w.Stop()
+	for {
+		select {
+		case event, ok := <-w.ResultChan():
+			if !ok {
+				return true, false
+			}
+			if event.Type == watch.Error {
+				if isExpired(errors.FromObject(event.Object)) {
+					return true, true
+				}
+			} else if accessor, err := meta.Accessor(event.Object); err == nil {
+				rw.resourceVersion = accessor.GetResourceVersion()
+			}
+			if !rw.send(event) {
+				return false, false
+			}
+		case <-rw.done:
+			return false, false
+		}
+	}
+}
+
+// resync lists the resources again, sending them all before watching from the version of the list. It also returns
+// nil when the resuming watch got stopped while sending them, check stopped before watching again.
+func (rw *resumingWatch) resync() error {
+	events, resourceVersion, err := rw.relist()
+	if err != nil {
+		return err
+	}
+	for _, event := range events {
+		if !rw.send(event) {
+			return nil
+		}
+	}
+	rw.resourceVersion = resourceVersion
+	return nil
+}
+
+// send forwards an event, returning false if the resuming watch got stopped in the meantime.
+func (rw *resumingWatch) send(event watch.Event) bool {
+	select {
+	case rw.result <- event:
+		return true
+	case <-rw.done:
+		return false
+	}
+}
+
+// isExpired tells whether err means the resource version to watch from is too old for the server to resume from.
+func isExpired(err error) bool {
+	return err != nil && (errors.IsGone(err) || errors.IsResourceExpired(err))
+}
+
+// stopped tells whether the resuming watch got stopped.
+func (rw *resumingWatch) stopped() bool {
+	select {
+	case <-rw.done:
+		return true
+	default:
+		return false
+	}
+}
+
+func (rw *resumingWatch) Stop() {
+	rw.stopOnce.Do(func() { close(rw.done) })
+}
+
+func (rw *resumingWatch) ResultChan() <-chan watch.Event {
+	return rw.result
+}
@@ -0,0 +1,156 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/apimachinery/pkg/watch"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Watching functions", func() {
+
+	var (
+		server *httptest.Server
+		lock   sync.Mutex
+		// watches are the resource versions watches were opened from, in order
+		watches []string
+		// lists counts the times functions were listed
+		lists int
+		// events are the watch events sent, keyed by the resource version watched from. Watches from other versions
+		// stay open without sending anything
+		events  map[string]string
+		client  core.Client
+		options core.WatchFunctionsOptions
+	)
+
+	service := func(name string, resourceVersion string) string {
+		return fmt.Sprintf(`{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"%s","namespace":"ns","resourceVersion":"%s"}}`,
+			name, resourceVersion)
+	}
+
+	BeforeEach(func() {
+		watches, lists = nil, 0
+		events = map[string]string{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			if r.URL.Path != "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services" {
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+				return
+			}
+			if r.URL.Query().Get("watch") != "true" {
+				lock.Lock()
+				lists++
+				lock.Unlock()
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"ServiceList","metadata":{"resourceVersion":"10"},"items":[%s]}`,
+					service("square", "9"))
+				return
+			}
+			resourceVersion := r.URL.Query().Get("resourceVersion")
+			lock.Lock()
+			watches = append(watches, resourceVersion)
+			lock.Unlock()
+			if sent, ok := events[resourceVersion]; ok {
+				fmt.Fprint(w, sent)
+				return
+			}
+			w.(http.Flusher).Flush()
+			select {
+			case <-r.Context().Done():
+			case <-time.After(5 * time.Second):
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		kubeClient, err := kubernetes.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, servingClient)
+
+		options = core.WatchFunctionsOptions{ResourceVersion: "5"}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	watched := func() []string {
+		lock.Lock()
+		defer lock.Unlock()
+		return append([]string(nil), watches...)
+	}
+
+	listed := func() int {
+		lock.Lock()
+		defer lock.Unlock()
+		return lists
+	}
+
+	It("should watch from the given resource version, resuming from the last one seen", func() {
+		events["5"] = fmt.Sprintf(`{"type":"MODIFIED","object":%s}`, service("square", "6"))
+
+		w, err := client.WatchFunctions(options)
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Modified))
+		Expect(event.Object.(*v1alpha1.Service).ResourceVersion).To(Equal("6"))
+		Eventually(watched).Should(Equal([]string{"5", "6"}))
+	})
+
+	It("should list functions again when the resource version is too old", func() {
+		events["5"] = `{"type":"ERROR","object":{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Expired","code":410}}`
+
+		w, err := client.WatchFunctions(options)
+		Expect(err).NotTo(HaveOccurred())
+		defer w.Stop()
+
+		var event watch.Event
+		Eventually(w.ResultChan()).Should(Receive(&event))
+		Expect(event.Type).To(Equal(watch.Modified))
+		Expect(event.Object.(*v1alpha1.Service).Name).To(Equal("square"))
+		Eventually(watched).Should(Equal([]string{"5", "10"}))
+	})
+
+	It("should not watch again when stopped while sending the functions listed again", func() {
+		events["5"] = `{"type":"ERROR","object":{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"Expired","code":410}}`
+
+		w, err := client.WatchFunctions(options)
+		Expect(err).NotTo(HaveOccurred())
+
+		Eventually(listed).Should(Equal(1))
+		w.Stop()
+
+		Eventually(w.ResultChan()).Should(BeClosed())
+		Consistently(watched, 200*time.Millisecond).Should(Equal([]string{"5"}))
+	})
+})
@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// The grammar below follows the one of docker image references (see github.com/docker/distribution/reference),
+// which is not vendored.
+const (
+	imageAlphaNumeric       = `[a-z0-9]+`
+	imageSeparator          = `(?:[._]|__|[-]*)`
+	imageNameComponent      = imageAlphaNumeric + `(?:` + imageSeparator + imageAlphaNumeric + `)*`
+	imageDomainComponent    = `(?:[a-zA-Z0-9]|[a-zA-Z0-9][a-zA-Z0-9-]*[a-zA-Z0-9])`
+	imageDomain             = imageDomainComponent + `(?:\.` + imageDomainComponent + `)*(?::[0-9]+)?`
+	imageTag                = `[\w][\w.-]{0,127}`
+	imageDigest             = `[A-Za-z][A-Za-z0-9]*(?:[-_+.][A-Za-z][A-Za-z0-9]*)*:[0-9a-fA-F]{32,}`
+	imageName               = `(?:` + imageDomain + `/)?` + imageNameComponent + `(?:/` + imageNameComponent + `)*`
+	imageNameTotalLengthMax = 255
+)
+
+var imageReferenceRegexp = regexp.MustCompile(`^(` + imageName + `)(?::` + imageTag + `)?(?:@` + imageDigest + `)?$`)
+
+// ValidateImageReference checks that image is a well formed docker image reference, of the form
+// [domain[:port]/]path[:tag][@digest].
+func ValidateImageReference(image string) error {
+	if image == "" {
+		return fmt.Errorf("invalid image reference '', the image name is missing")
+	}
+	matches := imageReferenceRegexp.FindStringSubmatch(image)
+	if matches == nil {
+		if lower := strings.ToLower(image); lower != image && imageReferenceRegexp.MatchString(lower) {
+			return fmt.Errorf("invalid image reference '%s', the repository name must be lowercase", image)
+		}
+		return fmt.Errorf("invalid image reference '%s'", image)
+	}
+	if len(matches[1]) > imageNameTotalLengthMax {
+		return fmt.Errorf("invalid image reference '%s', the repository name must not be more than %d characters", image, imageNameTotalLengthMax)
+	}
+	return nil
+}
@@ -0,0 +1,308 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// dockerHubRegistry is where images without a registry domain, e.g. "acme/square", are pulled from
+	dockerHubRegistry = "registry-1.docker.io"
+
+	// registryTimeout bounds each request made to a registry while resolving an image digest
+	registryTimeout = 10 * time.Second
+)
+
+// manifestMediaTypes are the kinds of manifests accepted when resolving a digest, so that the registry returns the
+// digest of the manifest (or manifest list) a container runtime would actually pull.
+var manifestMediaTypes = []string{
+	"application/vnd.docker.distribution.manifest.list.v2+json",
+	"application/vnd.docker.distribution.manifest.v2+json",
+	"application/vnd.oci.image.index.v1+json",
+	"application/vnd.oci.image.manifest.v1+json",
+}
+
+var registryClient = &http.Client{Timeout: registryTimeout}
+
+// RegistryOptions tell how to access the registry of an image.
+type RegistryOptions struct {
+	// Keychain provides the credentials of registries asking for authentication, AnonymousKeychain when nil
+	Keychain Keychain
+	// Insecure skips the verification of the registry certificate, and allows falling back to plain HTTP. Anyone
+	// able to intercept the traffic to the registry could then make it resolve to the digest of another image.
+	Insecure bool
+	// CAFile is the path to a PEM bundle of certificate authorities trusted in addition to the system ones, e.g. for
+	// registries with a certificate signed by a private authority
+	CAFile string
+}
+
+// ResolveDigest returns imageRef in its digest form, "name[:tag]@digest", by asking the registry the image lives in
+// for the digest of its manifest. Images that already reference a digest are returned unchanged. Registries asking
+// for authentication are given the credentials provided by the keychain, or accessed anonymously if it has none.
+// Registries on the local host, and insecure ones, are reached over plain HTTP if they don't serve HTTPS, as docker
+// allows. Certificates are verified unless the registry is insecure though, even on the local host.
+func ResolveDigest(imageRef string, options RegistryOptions) (string, error) {
+	if err := ValidateImageReference(imageRef); err != nil {
+		return "", err
+	}
+	if strings.Contains(imageRef, "@") {
+		return imageRef, nil
+	}
+	keychain := options.Keychain
+	if keychain == nil {
+		keychain = AnonymousKeychain
+	}
+	client, err := newRegistryClient(options)
+	if err != nil {
+		return "", err
+	}
+
+	registry, repository, tag := splitImageReference(imageRef)
+	manifestPath := fmt.Sprintf("%s/v2/%s/manifests/%s", registry, repository, tag)
+	manifestURL := "https://" + manifestPath
+
+	resp, err := headManifest(client, manifestURL, "")
+	if err != nil && !isCertificateError(err) && (options.Insecure || isLocalRegistry(registry)) {
+		// the registry may not serve HTTPS at all
+		manifestURL = "http://" + manifestPath
+		resp, err = headManifest(client, manifestURL, "")
+	}
+	if err != nil {
+		return "", manifestError(imageRef, err)
+	}
+	if resp.StatusCode == http.StatusUnauthorized {
+		credentials, err := keychain.Resolve(registry)
+		if err != nil {
+			return "", &RegistryError{Registry: registry, Call: "get credentials for", Cause: err}
+		}
+		authorization, err := authorize(client, resp.Header.Get("WWW-Authenticate"), credentials)
+		if err != nil {
+			return "", &RegistryError{Registry: registry, Call: "authenticate to", Cause: err}
+		}
+		if resp, err = headManifest(client, manifestURL, authorization); err != nil {
+			return "", manifestError(imageRef, err)
+		}
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("unable to resolve the digest of image '%s', registry %s answered %s", imageRef, registry, resp.Status)
+	}
+	digest := resp.Header.Get("Docker-Content-Digest")
+	if digest == "" {
+		return "", fmt.Errorf("unable to resolve the digest of image '%s', registry %s didn't provide one", imageRef, registry)
+	}
+	return imageRef + "@" + digest, nil
+}
+
+// isLocalRegistry tells whether the registry is on the local host, which docker considers insecure by default.
+func isLocalRegistry(registry string) bool {
+	host := registry
+	if h, _, err := net.SplitHostPort(registry); err == nil {
+		host = h
+	}
+	ip := net.ParseIP(host)
+	return host == "localhost" || (ip != nil && ip.IsLoopback())
+}
+
+// newRegistryClient returns the HTTP client to reach registries with, trusting the additional certificate authorities
+// of the options or skipping verification altogether for insecure registries.
+func newRegistryClient(options RegistryOptions) (*http.Client, error) {
+	if !options.Insecure && options.CAFile == "" {
+		return registryClient, nil
+	}
+	config := &tls.Config{InsecureSkipVerify: options.Insecure}
+	if options.CAFile != "" {
+		bundle, err := ioutil.ReadFile(options.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("unable to read registry certificate authorities: %v", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(bundle) {
+			return nil, fmt.Errorf("no certificate found in %s", options.CAFile)
+		}
+		config.RootCAs = pool
+	}
+	// the same settings as http.DefaultTransport, but for TLS
+	transport := &http.Transport{
+		Proxy: http.ProxyFromEnvironment,
+		DialContext: (&net.Dialer{
+			Timeout:   30 * time.Second,
+			KeepAlive: 30 * time.Second,
+		}).DialContext,
+		MaxIdleConns:          100,
+		IdleConnTimeout:       90 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ExpectContinueTimeout: 1 * time.Second,
+		TLSClientConfig:       config,
+	}
+	return &http.Client{Timeout: registryTimeout, Transport: transport}, nil
+}
+
+// isCertificateError tells whether err, as returned by an HTTP client, is caused by the certificate of the server
+// failing verification.
+func isCertificateError(err error) bool {
+	for err != nil {
+		switch e := err.(type) {
+		case *url.Error:
+			err = e.Err
+		case x509.UnknownAuthorityError, x509.CertificateInvalidError, x509.HostnameError:
+			return true
+		case interface{ Unwrap() error }:
+			// recent versions of the crypto/tls package report verification failures in their own error type
+			err = e.Unwrap()
+		default:
+			return false
+		}
+	}
+	return false
+}
+
+// manifestError describes a failure to fetch the manifest of an image, explaining how to trust the registry when its
+// certificate can't be verified.
+func manifestError(imageRef string, err error) error {
+	if isCertificateError(err) {
+		return fmt.Errorf("unable to fetch the manifest of image '%s', the certificate of its registry can't be verified: %v; "+
+			"trust the certificate authority of the registry, or access it as an insecure registry at the risk of pinning a "+
+			"tampered image if the traffic is intercepted", imageRef, err)
+	}
+	return fmt.Errorf("unable to fetch the manifest of image '%s': %v", imageRef, err)
+}
+
+// splitImageReference returns the registry, repository and tag of a reference without digest, applying the same
+// defaults as docker: images without registry domain come from Docker Hub, official images are in the "library"
+// namespace and the tag defaults to "latest".
+func splitImageReference(image string) (registry string, repository string, tag string) {
+	registry, repository = dockerHubRegistry, image
+	if i := strings.Index(image, "/"); i >= 0 {
+		if domain := image[:i]; strings.ContainsAny(domain, ".:") || domain == "localhost" {
+			registry, repository = domain, image[i+1:]
+		}
+	}
+	if registry == dockerHubRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+
+	tag = "latest"
+	if i := strings.LastIndex(repository, ":"); i >= 0 {
+		repository, tag = repository[:i], repository[i+1:]
+	}
+	return registry, repository, tag
+}
+
+func headManifest(client *http.Client, manifestURL string, authorization string) (*http.Response, error) {
+	req, err := http.NewRequest(http.MethodHead, manifestURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", strings.Join(manifestMediaTypes, ", "))
+	if authorization != "" {
+		req.Header.Set("Authorization", authorization)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	resp.Body.Close()
+	return resp, nil
+}
+
+// authorize returns the Authorization header answering a registry authentication challenge. Basic challenges are
+// answered with the credentials directly, bearer ones with a token obtained from the token service.
+func authorize(client *http.Client, challenge string, credentials *RegistryCredentials) (string, error) {
+	if strings.HasPrefix(challenge, "Basic ") {
+		if credentials == nil {
+			return "", fmt.Errorf("the registry requires credentials, none are configured")
+		}
+		return "Basic " + basicAuth(credentials), nil
+	}
+	token, err := bearerToken(client, challenge, credentials)
+	if err != nil {
+		return "", err
+	}
+	return "Bearer " + token, nil
+}
+
+func basicAuth(credentials *RegistryCredentials) string {
+	return base64.StdEncoding.EncodeToString([]byte(credentials.Username + ":" + credentials.Password))
+}
+
+// bearerToken obtains a bearer token from the token service described by a registry authentication challenge,
+// e.g. `Bearer realm="https://auth.docker.io/token",service="registry.docker.io",scope="repository:acme/square:pull"`.
+// The token is requested anonymously if there are no credentials.
+func bearerToken(client *http.Client, challenge string, credentials *RegistryCredentials) (string, error) {
+	if !strings.HasPrefix(challenge, "Bearer ") {
+		return "", fmt.Errorf("unsupported authentication challenge %q", challenge)
+	}
+	params := map[string]string{}
+	for _, param := range strings.Split(strings.TrimPrefix(challenge, "Bearer "), ",") {
+		kv := strings.SplitN(strings.TrimSpace(param), "=", 2)
+		if len(kv) == 2 {
+			params[kv[0]] = strings.Trim(kv[1], `"`)
+		}
+	}
+	realm, err := url.Parse(params["realm"])
+	if err != nil || params["realm"] == "" {
+		return "", fmt.Errorf("invalid authentication realm %q", params["realm"])
+	}
+	query := realm.Query()
+	for _, key := range []string{"service", "scope"} {
+		if params[key] != "" {
+			query.Set(key, params[key])
+		}
+	}
+	realm.RawQuery = query.Encode()
+
+	req, err := http.NewRequest(http.MethodGet, realm.String(), nil)
+	if err != nil {
+		return "", err
+	}
+	if credentials != nil {
+		req.Header.Set("Authorization", "Basic "+basicAuth(credentials))
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("unable to reach token service %s: %v", realm.Host, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("token service %s answered %s", realm.Host, resp.Status)
+	}
+	body := struct {
+		Token       string `json:"token"`
+		AccessToken string `json:"access_token"`
+	}{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return "", fmt.Errorf("invalid answer from token service %s: %v", realm.Host, err)
+	}
+	if body.Token != "" {
+		return body.Token, nil
+	}
+	return body.AccessToken, nil
+}
@@ -0,0 +1,276 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+)
+
+var _ = Describe("Image digest pinning", func() {
+
+	var (
+		client  core.Client
+		options core.CreateFunctionOptions
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		options = core.CreateFunctionOptions{PinDigest: true}
+		options.Namespace = "default"
+		options.Name = "square"
+		// nothing listens on port 1, so asking this registry for a digest fails straight away
+		options.Image = "127.0.0.1:1/acme/square:1.0"
+		options.DryRun = true
+	})
+
+	It("should refuse to pin images built from a git repository", func() {
+		options.GitRepo = "https://github.com/acme/square"
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(MatchError("the digest of image '127.0.0.1:1/acme/square:1.0' can't be pinned, as the image is only built after the function is created"))
+	})
+
+	It("should keep images already referencing a digest", func() {
+		options.Image = "acme/square@sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal(options.Image))
+	})
+
+	It("should fall back to the tag with a warning when the registry can't be reached", func() {
+		warnings := &strings.Builder{}
+		options.Warnings = warnings
+
+		s, err := client.CreateFunction(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("127.0.0.1:1/acme/square:1.0"))
+		Expect(warnings.String()).To(HavePrefix("Warning: not pinning the digest of image '127.0.0.1:1/acme/square:1.0': "))
+	})
+
+	It("should fail when the digest is required and the registry can't be reached", func() {
+		options.RequireDigest = true
+
+		_, err := client.CreateFunction(options)
+		Expect(err).To(HaveOccurred())
+	})
+})
+
+// stubKeychain provides the same credentials, or error, for all registries.
+type stubKeychain struct {
+	credentials *core.RegistryCredentials
+	err         error
+}
+
+func (k *stubKeychain) Resolve(registry string) (*core.RegistryCredentials, error) {
+	return k.credentials, k.err
+}
+
+var _ = Describe("Image digest resolution", func() {
+
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	var (
+		challenge string
+		server    *httptest.Server
+		registry  string
+	)
+
+	BeforeEach(func() {
+		challenge = ""
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			switch r.URL.Path {
+			case "/token":
+				if user, password, ok := r.BasicAuth(); !ok || user != "joseph" || password != "s3cr3t" {
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				fmt.Fprint(w, `{"token":"t0k3n"}`)
+			case "/v2/acme/square/manifests/1.0":
+				authorized := challenge == ""
+				if strings.HasPrefix(challenge, "Basic ") {
+					user, password, ok := r.BasicAuth()
+					authorized = ok && user == "joseph" && password == "s3cr3t"
+				} else if strings.HasPrefix(challenge, "Bearer ") {
+					authorized = r.Header.Get("Authorization") == "Bearer t0k3n"
+				}
+				if !authorized {
+					w.Header().Set("WWW-Authenticate", challenge)
+					w.WriteHeader(http.StatusUnauthorized)
+					return
+				}
+				w.Header().Set("Docker-Content-Digest", digest)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		registry = strings.TrimPrefix(server.URL, "http://")
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should resolve the digest from a public registry", func() {
+		image, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(registry + "/acme/square:1.0@" + digest))
+	})
+
+	It("should authenticate with a token obtained with the keychain credentials", func() {
+		challenge = fmt.Sprintf(`Bearer realm="%s/token",service="registry",scope="repository:acme/square:pull"`, server.URL)
+		keychain := &stubKeychain{credentials: &core.RegistryCredentials{Username: "joseph", Password: "s3cr3t"}}
+
+		image, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{Keychain: keychain})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(registry + "/acme/square:1.0@" + digest))
+	})
+
+	It("should authenticate with the keychain credentials directly", func() {
+		challenge = `Basic realm="registry"`
+		keychain := &stubKeychain{credentials: &core.RegistryCredentials{Username: "joseph", Password: "s3cr3t"}}
+
+		image, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{Keychain: keychain})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(registry + "/acme/square:1.0@" + digest))
+	})
+
+	It("should identify the token service as failing", func() {
+		challenge = fmt.Sprintf(`Bearer realm="%s/token",service="registry"`, server.URL)
+
+		_, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{})
+		Expect(err).To(MatchError(fmt.Sprintf("unable to authenticate to registry %s: token service %s answered 401 Unauthorized", registry, registry)))
+	})
+
+	It("should wrap keychain errors", func() {
+		challenge = `Basic realm="registry"`
+		keychainErr := errors.New("keychain locked")
+
+		_, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{Keychain: &stubKeychain{err: keychainErr}})
+		Expect(err).To(MatchError(fmt.Sprintf("unable to get credentials for registry %s: keychain locked", registry)))
+		Expect(err).To(BeAssignableToTypeOf(&core.RegistryError{}))
+		Expect(err.(*core.RegistryError).Cause).To(BeIdenticalTo(keychainErr))
+	})
+
+	It("should report images missing from the registry", func() {
+		_, err := core.ResolveDigest(registry+"/acme/cube:1.0", core.RegistryOptions{})
+		Expect(err).To(MatchError(fmt.Sprintf("unable to resolve the digest of image '%s/acme/cube:1.0', registry %s answered 404 Not Found", registry, registry)))
+	})
+})
+
+var _ = Describe("Image digest resolution from registries using TLS", func() {
+
+	const digest = "sha256:0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	var (
+		server   *httptest.Server
+		registry string
+		caFile   string
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Docker-Content-Digest", digest)
+		}))
+		registry = strings.TrimPrefix(server.URL, "https://")
+
+		f, err := ioutil.TempFile("", "ca")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		Expect(pem.Encode(f, &pem.Block{Type: "CERTIFICATE", Bytes: server.Certificate().Raw})).To(Succeed())
+		caFile = f.Name()
+	})
+
+	AfterEach(func() {
+		server.Close()
+		os.Remove(caFile)
+	})
+
+	It("should explain how to trust a registry whose certificate can't be verified", func() {
+		_, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{})
+		Expect(err).To(MatchError(ContainSubstring("the certificate of its registry can't be verified")))
+		Expect(err).To(MatchError(ContainSubstring("at the risk of pinning a tampered image")))
+	})
+
+	It("should trust additional certificate authorities", func() {
+		image, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{CAFile: caFile})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(registry + "/acme/square:1.0@" + digest))
+	})
+
+	It("should skip verification for insecure registries", func() {
+		image, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{Insecure: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(image).To(Equal(registry + "/acme/square:1.0@" + digest))
+	})
+
+	It("should fail for a CA file without certificates", func() {
+		Expect(ioutil.WriteFile(caFile, []byte("not a certificate"), 0600)).To(Succeed())
+
+		_, err := core.ResolveDigest(registry+"/acme/square:1.0", core.RegistryOptions{CAFile: caFile})
+		Expect(err).To(MatchError(fmt.Sprintf("no certificate found in %s", caFile)))
+	})
+})
+
+var _ = Describe("Docker configuration keychain", func() {
+
+	var path string
+
+	BeforeEach(func() {
+		dir, err := ioutil.TempDir("", "docker-config")
+		Expect(err).NotTo(HaveOccurred())
+		path = filepath.Join(dir, "config.json")
+		Expect(ioutil.WriteFile(path, []byte(`{"auths": {
+			"https://index.docker.io/v1/": {"auth": "am9zZXBoOnMzY3IzdA=="},
+			"gcr.io": {"username": "_json_key", "password": "{}"}
+		}}`), 0600)).To(Succeed())
+	})
+
+	AfterEach(func() {
+		os.RemoveAll(filepath.Dir(path))
+	})
+
+	It("should decode docker hub credentials", func() {
+		credentials, err := (&core.DockerConfigKeychain{Path: path}).Resolve("registry-1.docker.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials).To(Equal(&core.RegistryCredentials{Username: "joseph", Password: "s3cr3t"}))
+	})
+
+	It("should read plain credentials", func() {
+		credentials, err := (&core.DockerConfigKeychain{Path: path}).Resolve("gcr.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials).To(Equal(&core.RegistryCredentials{Username: "_json_key", Password: "{}"}))
+	})
+
+	It("should have no credentials for other registries", func() {
+		credentials, err := (&core.DockerConfigKeychain{Path: path}).Resolve("quay.io")
+		Expect(err).NotTo(HaveOccurred())
+		Expect(credentials).To(BeNil())
+	})
+})
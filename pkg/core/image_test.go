@@ -0,0 +1,58 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"strings"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+)
+
+var _ = Describe("Image reference validation", func() {
+
+	It("should accept well formed references", func() {
+		for _, image := range []string{
+			"square",
+			"acme/square",
+			"acme/square:0.0.1",
+			"registry.example.com:5000/acme/square:latest",
+			"gcr.io/acme/square@sha256:" + strings.Repeat("a", 64),
+		} {
+			Expect(core.ValidateImageReference(image)).To(Succeed(), image)
+		}
+	})
+
+	It("should fail when the image is missing", func() {
+		Expect(core.ValidateImageReference("")).To(MatchError("invalid image reference '', the image name is missing"))
+	})
+
+	It("should fail for uppercase repository names", func() {
+		Expect(core.ValidateImageReference("acme/Square")).To(MatchError("invalid image reference 'acme/Square', the repository name must be lowercase"))
+	})
+
+	It("should fail for malformed references", func() {
+		Expect(core.ValidateImageReference("acme/square:")).To(MatchError("invalid image reference 'acme/square:'"))
+		Expect(core.ValidateImageReference("acme/my square")).To(MatchError("invalid image reference 'acme/my square'"))
+	})
+
+	It("should fail for names that are too long", func() {
+		image := strings.Repeat("a", 256)
+		Expect(core.ValidateImageReference(image)).To(MatchError("invalid image reference '" + image + "', the repository name must not be more than 255 characters"))
+	})
+})
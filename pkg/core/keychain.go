@@ -0,0 +1,141 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"os/user"
+	"path/filepath"
+	"strings"
+)
+
+// RegistryCredentials authenticate to a registry with a username and password (or access token).
+type RegistryCredentials struct {
+	Username string
+	Password string
+}
+
+// Keychain provides the credentials to use with a registry, identified by its domain (e.g. "gcr.io"). Nil credentials
+// mean the registry is accessed anonymously.
+type Keychain interface {
+	Resolve(registry string) (*RegistryCredentials, error)
+}
+
+type anonymousKeychain struct{}
+
+func (anonymousKeychain) Resolve(string) (*RegistryCredentials, error) {
+	return nil, nil
+}
+
+// AnonymousKeychain accesses all registries anonymously.
+var AnonymousKeychain Keychain = anonymousKeychain{}
+
+// DefaultKeychain reads credentials from the docker configuration file, as written by `docker login`: config.json in
+// the directory named by $DOCKER_CONFIG, or ~/.docker. Registries without credentials, or any registry if there is no
+// configuration file, are accessed anonymously.
+var DefaultKeychain Keychain = &DockerConfigKeychain{}
+
+// DockerConfigKeychain reads credentials from a docker configuration file. Only credentials stored in the file itself
+// are supported, not the ones kept by credential helpers.
+type DockerConfigKeychain struct {
+	// Path is the configuration file to read, found the same way as docker does when empty
+	Path string
+}
+
+func (k *DockerConfigKeychain) Resolve(registry string) (*RegistryCredentials, error) {
+	path := k.Path
+	if path == "" {
+		dir := os.Getenv("DOCKER_CONFIG")
+		if dir == "" {
+			home := homeDir()
+			if home == "" {
+				return nil, nil
+			}
+			dir = filepath.Join(home, ".docker")
+		}
+		path = filepath.Join(dir, "config.json")
+	}
+
+	file, err := os.Open(path)
+	if os.IsNotExist(err) && k.Path == "" {
+		return nil, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("unable to read docker configuration: %v", err)
+	}
+	defer file.Close()
+
+	config := struct {
+		Auths map[string]struct {
+			Auth     string `json:"auth"`
+			Username string `json:"username"`
+			Password string `json:"password"`
+		} `json:"auths"`
+	}{}
+	if err := json.NewDecoder(file).Decode(&config); err != nil {
+		return nil, fmt.Errorf("unable to read docker configuration %s: %v", path, err)
+	}
+
+	for key, entry := range config.Auths {
+		if configKeyRegistry(key) != registry {
+			continue
+		}
+		if entry.Auth == "" {
+			return &RegistryCredentials{Username: entry.Username, Password: entry.Password}, nil
+		}
+		decoded, err := base64.StdEncoding.DecodeString(entry.Auth)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credentials for registry %s in %s: %v", registry, path, err)
+		}
+		parts := strings.SplitN(string(decoded), ":", 2)
+		if len(parts) != 2 {
+			return nil, fmt.Errorf("invalid credentials for registry %s in %s: expected 'username:password'", registry, path)
+		}
+		return &RegistryCredentials{Username: parts[0], Password: parts[1]}, nil
+	}
+	return nil, nil
+}
+
+// homeDir returns the home directory of the user, as $HOME or from the user database, or "" if it can't be determined.
+func homeDir() string {
+	if home := os.Getenv("HOME"); home != "" {
+		return home
+	}
+	if u, err := user.Current(); err == nil {
+		return u.HomeDir
+	}
+	return ""
+}
+
+// configKeyRegistry returns the registry domain a docker configuration key refers to. Keys may be full URLs, as in
+// "https://index.docker.io/v1/" which docker uses for Docker Hub, or plain domains.
+func configKeyRegistry(key string) string {
+	host := strings.SplitN(key, "/", 2)[0]
+	if strings.Contains(key, "://") {
+		if u, err := url.Parse(key); err == nil {
+			host = u.Host
+		}
+	}
+	switch host {
+	case "index.docker.io", "docker.io":
+		return dockerHubRegistry
+	}
+	return host
+}
@@ -0,0 +1,145 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"github.com/projectriff/riff/pkg/osutils"
+)
+
+const (
+	// invokerBuildEnv tells the riff buildpacks which invoker to use, instead of detecting it from the sources
+	invokerBuildEnv = "RIFF_INVOKER"
+
+	// dockerProbeTimeout bounds the check that the docker daemon is reachable, before building
+	dockerProbeTimeout = 10 * time.Second
+)
+
+// The command line tools local builds run, through the osutils seam so that tests can stub them
+var (
+	execTool   = osutils.Exec
+	streamTool = osutils.ExecStream
+)
+
+type LocalBuildOptions struct {
+	// CreateFunctionOptions describe the function to create once its image is built. Its Image is replaced by the
+	// digest of the pushed image, and its GitRepo, if any, is ignored.
+	CreateFunctionOptions
+
+	// SourcePath is the local directory holding the function sources
+	SourcePath string
+	// Builder is the Cloud Native Buildpacks builder image used to build the sources
+	Builder string
+	// Invoker is the riff invoker the buildpacks should use, detected from the sources if empty
+	Invoker string
+
+	// Output receives the output of the build and push steps, discarded if nil
+	Output io.Writer
+}
+
+// BuildFromLocal builds an image from local sources with Cloud Native Buildpacks, using the pack CLI and the local
+// docker daemon, pushes it and then creates the function, referencing the image by digest so that revisions run
+// exactly what was built.
+func (c *client) BuildFromLocal(options LocalBuildOptions) (*v1alpha1.Service, error) {
+	if err := ValidateImageReference(options.Image); err != nil {
+		return nil, err
+	}
+	if options.Builder == "" {
+		return nil, fmt.Errorf("a builder image is required to build local sources")
+	}
+	if info, err := os.Stat(options.SourcePath); err != nil {
+		return nil, fmt.Errorf("unable to read the function sources: %v", err)
+	} else if !info.IsDir() {
+		return nil, fmt.Errorf("function sources %q must be a directory", options.SourcePath)
+	}
+
+	if _, err := execTool("pack", []string{"version"}, dockerProbeTimeout); err != nil {
+		return nil, fmt.Errorf("the pack CLI is required to build local sources: %v", err)
+	}
+	if _, err := execTool("docker", []string{"info", "--format", "{{.ServerVersion}}"}, dockerProbeTimeout); err != nil {
+		return nil, fmt.Errorf("unable to reach the docker daemon, which is required to build local sources: %v", err)
+	}
+
+	out := options.Output
+	if out == nil {
+		out = ioutil.Discard
+	}
+	packArgs := []string{"build", options.Image, "--path", options.SourcePath, "--builder", options.Builder}
+	if options.Invoker != "" {
+		packArgs = append(packArgs, "--env", fmt.Sprintf("%s=%s", invokerBuildEnv, options.Invoker))
+	}
+	if err := streamTool("pack", packArgs, out); err != nil {
+		return nil, fmt.Errorf("unable to build image %q: %v", options.Image, err)
+	}
+	if err := streamTool("docker", []string{"push", options.Image}, out); err != nil {
+		return nil, fmt.Errorf("unable to push image %q: %v", options.Image, err)
+	}
+	inspected, err := execTool("docker", []string{"inspect", "--format", "{{json .RepoDigests}}", options.Image}, dockerProbeTimeout)
+	if err != nil {
+		return nil, fmt.Errorf("unable to resolve the digest of image %q: %v", options.Image, err)
+	}
+	digest, err := pushedDigest(options.Image, inspected)
+	if err != nil {
+		return nil, err
+	}
+
+	createOptions := options.CreateFunctionOptions
+	createOptions.Image = digest
+	createOptions.GitRepo = ""
+	createOptions.GitRevision = ""
+	return c.CreateFunction(createOptions)
+}
+
+// pushedDigest picks, among the repository digests reported by docker inspect for image, the one of the repository
+// image was pushed to. An image may have been pushed to several repositories, with a digest for each.
+func pushedDigest(image string, inspected []byte) (string, error) {
+	var repoDigests []string
+	if err := json.Unmarshal(inspected, &repoDigests); err != nil {
+		return "", fmt.Errorf("unable to read the digests of image %q from %q: %v", image, strings.TrimSpace(string(inspected)), err)
+	}
+	if len(repoDigests) == 0 {
+		return "", fmt.Errorf("image %q has no digest, as it was never pushed to a registry", image)
+	}
+
+	repository := canonicalRepository(strings.SplitN(image, "@", 2)[0])
+	for _, repoDigest := range repoDigests {
+		parts := strings.SplitN(repoDigest, "@", 2)
+		if len(parts) == 2 && canonicalRepository(parts[0]) == repository {
+			return repoDigest, nil
+		}
+	}
+	return "", fmt.Errorf("image %q has no digest in repository %s, only %s", image, repository, strings.Join(repoDigests, ", "))
+}
+
+// canonicalRepository returns the registry and repository of an image reference without digest, so that the
+// different ways to name a Docker Hub repository, e.g. "square", "library/square" or "docker.io/square", compare equal.
+func canonicalRepository(image string) string {
+	registry, repository, _ := splitImageReference(image)
+	registry = configKeyRegistry(registry)
+	if registry == dockerHubRegistry && !strings.Contains(repository, "/") {
+		repository = "library/" + repository
+	}
+	return registry + "/" + repository
+}
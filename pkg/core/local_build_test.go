@@ -0,0 +1,168 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+	"io"
+	"io/ioutil"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+)
+
+var _ = Describe("Building functions from local sources", func() {
+
+	const digest = "sha256:4b825dc642cb6eb9a060e54bf8d69288fbee4904aeb27c3e3ba3e0e6e3c1e3f6"
+
+	var (
+		client  core.Client
+		options core.LocalBuildOptions
+		output  *strings.Builder
+		// commands are the command lines run, in order
+		commands []string
+		// failing maps the command lines to fail to the error they fail with
+		failing map[string]error
+		// repoDigests is what docker inspect reports as the RepoDigests of the built image
+		repoDigests string
+		restore     func()
+	)
+
+	BeforeEach(func() {
+		client = core.NewClient(nil, nil, nil, nil)
+		commands = nil
+		failing = map[string]error{}
+		repoDigests = fmt.Sprintf(`["acme/square@%s"]`, digest)
+		output = &strings.Builder{}
+
+		sources, err := ioutil.TempDir("", "square")
+		Expect(err).NotTo(HaveOccurred())
+		options = core.LocalBuildOptions{SourcePath: sources, Builder: "projectriff/builder", Output: output}
+		options.Namespace = "ns"
+		options.Name = "square"
+		options.Image = "acme/square:v1"
+		options.DryRun = true
+
+		run := func(name string, args []string) error {
+			command := strings.Join(append([]string{name}, args...), " ")
+			commands = append(commands, command)
+			return failing[command]
+		}
+		restore = core.StubLocalBuildTools(
+			func(name string, args []string, timeout time.Duration) ([]byte, error) {
+				if err := run(name, args); err != nil {
+					return nil, err
+				}
+				if name == "docker" && args[0] == "inspect" {
+					return []byte(repoDigests + "\n"), nil
+				}
+				return []byte("ok\n"), nil
+			},
+			func(name string, args []string, out io.Writer) error {
+				fmt.Fprintf(out, "running %s\n", name)
+				return run(name, args)
+			})
+	})
+
+	AfterEach(func() {
+		restore()
+		os.RemoveAll(options.SourcePath)
+	})
+
+	It("should build, push and create the function from the pushed digest", func() {
+		options.Invoker = "node"
+
+		s, err := client.BuildFromLocal(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("acme/square@" + digest))
+		Expect(commands).To(Equal([]string{
+			"pack version",
+			"docker info --format {{.ServerVersion}}",
+			"pack build acme/square:v1 --path " + options.SourcePath + " --builder projectriff/builder --env RIFF_INVOKER=node",
+			"docker push acme/square:v1",
+			"docker inspect --format {{json .RepoDigests}} acme/square:v1",
+		}))
+		Expect(output.String()).To(Equal("running pack\nrunning docker\n"))
+	})
+
+	It("should pick the digest of the repository the image was pushed to", func() {
+		options.Image = "registry.example.com:5000/acme/square"
+		repoDigests = fmt.Sprintf(`["acme/square@sha256:0000000000000000000000000000000000000000000000000000000000000000","registry.example.com:5000/acme/square@%s"]`, digest)
+
+		s, err := client.BuildFromLocal(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("registry.example.com:5000/acme/square@" + digest))
+	})
+
+	It("should match Docker Hub repositories however they are named", func() {
+		options.Image = "docker.io/library/square"
+		repoDigests = fmt.Sprintf(`["square@%s"]`, digest)
+
+		s, err := client.BuildFromLocal(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(s.Spec.RunLatest.Configuration.RevisionTemplate.Spec.Container.Image).To(Equal("square@" + digest))
+	})
+
+	It("should fail when the image has no digest in its repository", func() {
+		repoDigests = fmt.Sprintf(`["registry.example.com/acme/square@%s"]`, digest)
+
+		_, err := client.BuildFromLocal(options)
+		Expect(err).To(MatchError(fmt.Sprintf(`image "acme/square:v1" has no digest in repository registry-1.docker.io/acme/square, only registry.example.com/acme/square@%s`, digest)))
+	})
+
+	It("should fail when the image was never pushed", func() {
+		repoDigests = "[]"
+
+		_, err := client.BuildFromLocal(options)
+		Expect(err).To(MatchError(`image "acme/square:v1" has no digest, as it was never pushed to a registry`))
+	})
+
+	It("should fail when the digests can't be read", func() {
+		repoDigests = "<no value>"
+
+		_, err := client.BuildFromLocal(options)
+		Expect(err).To(MatchError(HavePrefix(`unable to read the digests of image "acme/square:v1" from "<no value>": `)))
+	})
+
+	It("should fail fast without the pack CLI", func() {
+		failing["pack version"] = fmt.Errorf(`exec: "pack": executable file not found in $PATH`)
+
+		_, err := client.BuildFromLocal(options)
+		Expect(err).To(MatchError(`the pack CLI is required to build local sources: exec: "pack": executable file not found in $PATH`))
+		Expect(commands).To(HaveLen(1))
+	})
+
+	It("should fail fast when the docker daemon is unreachable", func() {
+		failing["docker info --format {{.ServerVersion}}"] = fmt.Errorf("exit status 1")
+
+		_, err := client.BuildFromLocal(options)
+		Expect(err).To(MatchError("unable to reach the docker daemon, which is required to build local sources: exit status 1"))
+		Expect(commands).To(HaveLen(2))
+	})
+
+	It("should not push images that failed to build", func() {
+		failing["pack build acme/square:v1 --path "+options.SourcePath+" --builder projectriff/builder"] = fmt.Errorf("exit status 1")
+
+		_, err := client.BuildFromLocal(options)
+		Expect(err).To(MatchError(`unable to build image "acme/square:v1": exit status 1`))
+		Expect(commands).NotTo(ContainElement("docker push acme/square:v1"))
+	})
+})
@@ -1,16 +1,120 @@
 // Code generated by mockery v1.0.0. DO NOT EDIT.
 package mocks
 
+import buildv1alpha1 "github.com/knative/build/pkg/apis/build/v1alpha1"
+import context "context"
 import core "github.com/projectriff/riff/pkg/core"
+import io "io"
 import mock "github.com/stretchr/testify/mock"
 import servingv1alpha1 "github.com/knative/serving/pkg/apis/serving/v1alpha1"
+import v1 "k8s.io/api/core/v1"
 import v1alpha1 "github.com/knative/eventing/pkg/apis/channels/v1alpha1"
+import watch "k8s.io/apimachinery/pkg/watch"
 
 // Client is an autogenerated mock type for the Client type
 type Client struct {
 	mock.Mock
 }
 
+// ApplyFunction provides a mock function with given fields: options
+func (_m *Client) ApplyFunction(options core.CreateFunctionOptions) (*servingv1alpha1.Service, bool, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.CreateFunctionOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(core.CreateFunctionOptions) bool); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Get(1).(bool)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(core.CreateFunctionOptions) error); ok {
+		r2 = rf(options)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// BuildFromLocal provides a mock function with given fields: options
+func (_m *Client) BuildFromLocal(options core.LocalBuildOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.LocalBuildOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.LocalBuildOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// BuildFunction provides a mock function with given fields: options
+func (_m *Client) BuildFunction(options core.BuildFunctionOptions) (*buildv1alpha1.Build, error) {
+	ret := _m.Called(options)
+
+	var r0 *buildv1alpha1.Build
+	if rf, ok := ret.Get(0).(func(core.BuildFunctionOptions) *buildv1alpha1.Build); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*buildv1alpha1.Build)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.BuildFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CloneFunction provides a mock function with given fields: options
+func (_m *Client) CloneFunction(options core.CloneFunctionOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.CloneFunctionOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.CloneFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateChannel provides a mock function with given fields: options
 func (_m *Client) CreateChannel(options core.CreateChannelOptions) (*v1alpha1.Channel, error) {
 	ret := _m.Called(options)
@@ -57,6 +161,121 @@ func (_m *Client) CreateFunction(options core.CreateFunctionOptions) (*servingv1
 	return r0, r1
 }
 
+// CreateFunctionAsync provides a mock function with given fields: options
+func (_m *Client) CreateFunctionAsync(options core.CreateFunctionOptions) (*core.FunctionHandle, error) {
+	ret := _m.Called(options)
+
+	var r0 *core.FunctionHandle
+	if rf, ok := ret.Get(0).(func(core.CreateFunctionOptions) *core.FunctionHandle); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.FunctionHandle)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.CreateFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFunctionFromFile provides a mock function with given fields: options
+func (_m *Client) CreateFunctionFromFile(options core.CreateFunctionFromFileOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.CreateFunctionFromFileOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.CreateFunctionFromFileOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFunctionWithResult provides a mock function with given fields: options
+func (_m *Client) CreateFunctionWithResult(options core.CreateFunctionOptions) (*core.CreateResult, error) {
+	ret := _m.Called(options)
+
+	var r0 *core.CreateResult
+	if rf, ok := ret.Get(0).(func(core.CreateFunctionOptions) *core.CreateResult); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*core.CreateResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.CreateFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateFunctions provides a mock function with given fields: ctx, options
+func (_m *Client) CreateFunctions(ctx context.Context, options core.CreateFunctionsOptions) ([]core.CreateFunctionResult, error) {
+	ret := _m.Called(ctx, options)
+
+	var r0 []core.CreateFunctionResult
+	if rf, ok := ret.Get(0).(func(context.Context, core.CreateFunctionsOptions) []core.CreateFunctionResult); ok {
+		r0 = rf(ctx, options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.CreateFunctionResult)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context, core.CreateFunctionsOptions) error); ok {
+		r1 = rf(ctx, options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// CreateNamespace provides a mock function with given fields: options
+func (_m *Client) CreateNamespace(options core.CreateNamespaceOptions) (*v1.Namespace, error) {
+	ret := _m.Called(options)
+
+	var r0 *v1.Namespace
+	if rf, ok := ret.Get(0).(func(core.CreateNamespaceOptions) *v1.Namespace); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1.Namespace)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.CreateNamespaceOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
 // CreateService provides a mock function with given fields: options
 func (_m *Client) CreateService(options core.CreateServiceOptions) (*servingv1alpha1.Service, error) {
 	ret := _m.Called(options)
@@ -117,6 +336,62 @@ func (_m *Client) DeleteChannel(options core.DeleteChannelOptions) error {
 	return r0
 }
 
+// DeleteFunction provides a mock function with given fields: ctx, options
+func (_m *Client) DeleteFunction(ctx context.Context, options core.DeleteFunctionOptions) error {
+	ret := _m.Called(ctx, options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.DeleteFunctionOptions) error); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteFunctions provides a mock function with given fields: ctx, options
+func (_m *Client) DeleteFunctions(ctx context.Context, options core.DeleteFunctionsOptions) error {
+	ret := _m.Called(ctx, options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.DeleteFunctionsOptions) error); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteNamespace provides a mock function with given fields: ctx, options
+func (_m *Client) DeleteNamespace(ctx context.Context, options core.DeleteNamespaceOptions) error {
+	ret := _m.Called(ctx, options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.DeleteNamespaceOptions) error); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// DeleteRevision provides a mock function with given fields: options
+func (_m *Client) DeleteRevision(options core.DeleteRevisionOptions) error {
+	ret := _m.Called(options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(core.DeleteRevisionOptions) error); ok {
+		r0 = rf(options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
 // DeleteService provides a mock function with given fields: options
 func (_m *Client) DeleteService(options core.DeleteServiceOptions) error {
 	ret := _m.Called(options)
@@ -131,21 +406,21 @@ func (_m *Client) DeleteService(options core.DeleteServiceOptions) error {
 	return r0
 }
 
-// ListChannels provides a mock function with given fields: options
-func (_m *Client) ListChannels(options core.ListChannelOptions) (*v1alpha1.ChannelList, error) {
+// DescribeFunction provides a mock function with given fields: options
+func (_m *Client) DescribeFunction(options core.DescribeFunctionOptions) (*core.FunctionDescription, error) {
 	ret := _m.Called(options)
 
-	var r0 *v1alpha1.ChannelList
-	if rf, ok := ret.Get(0).(func(core.ListChannelOptions) *v1alpha1.ChannelList); ok {
+	var r0 *core.FunctionDescription
+	if rf, ok := ret.Get(0).(func(core.DescribeFunctionOptions) *core.FunctionDescription); ok {
 		r0 = rf(options)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*v1alpha1.ChannelList)
+			r0 = ret.Get(0).(*core.FunctionDescription)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(core.ListChannelOptions) error); ok {
+	if rf, ok := ret.Get(1).(func(core.DescribeFunctionOptions) error); ok {
 		r1 = rf(options)
 	} else {
 		r1 = ret.Error(1)
@@ -154,72 +429,589 @@ func (_m *Client) ListChannels(options core.ListChannelOptions) (*v1alpha1.Chann
 	return r0, r1
 }
 
-// ListServices provides a mock function with given fields: options
-func (_m *Client) ListServices(options core.ListServiceOptions) (*servingv1alpha1.ServiceList, error) {
+// EditFunction provides a mock function with given fields: options
+func (_m *Client) EditFunction(options core.EditFunctionOptions) (*servingv1alpha1.Service, bool, error) {
 	ret := _m.Called(options)
 
-	var r0 *servingv1alpha1.ServiceList
-	if rf, ok := ret.Get(0).(func(core.ListServiceOptions) *servingv1alpha1.ServiceList); ok {
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.EditFunctionOptions) *servingv1alpha1.Service); ok {
 		r0 = rf(options)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*servingv1alpha1.ServiceList)
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
 		}
 	}
 
-	var r1 error
-	if rf, ok := ret.Get(1).(func(core.ListServiceOptions) error); ok {
+	var r1 bool
+	if rf, ok := ret.Get(1).(func(core.EditFunctionOptions) bool); ok {
 		r1 = rf(options)
 	} else {
-		r1 = ret.Error(1)
+		r1 = ret.Get(1).(bool)
 	}
 
-	return r0, r1
+	var r2 error
+	if rf, ok := ret.Get(2).(func(core.EditFunctionOptions) error); ok {
+		r2 = rf(options)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
 }
 
-// ServiceCoordinates provides a mock function with given fields: options
-func (_m *Client) ServiceCoordinates(options core.ServiceInvokeOptions) (string, string, error) {
+// ExportFunction provides a mock function with given fields: options
+func (_m *Client) ExportFunction(options core.ExportFunctionOptions) ([]byte, error) {
 	ret := _m.Called(options)
 
-	var r0 string
-	if rf, ok := ret.Get(0).(func(core.ServiceInvokeOptions) string); ok {
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(core.ExportFunctionOptions) []byte); ok {
 		r0 = rf(options)
 	} else {
-		r0 = ret.Get(0).(string)
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]byte)
+		}
 	}
 
-	var r1 string
-	if rf, ok := ret.Get(1).(func(core.ServiceInvokeOptions) string); ok {
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ExportFunctionOptions) error); ok {
 		r1 = rf(options)
 	} else {
-		r1 = ret.Get(1).(string)
-	}
-
-	var r2 error
-	if rf, ok := ret.Get(2).(func(core.ServiceInvokeOptions) error); ok {
-		r2 = rf(options)
-	} else {
-		r2 = ret.Error(2)
+		r1 = ret.Error(1)
 	}
 
-	return r0, r1, r2
+	return r0, r1
 }
 
-// ServiceStatus provides a mock function with given fields: options
-func (_m *Client) ServiceStatus(options core.ServiceStatusOptions) (*servingv1alpha1.ServiceCondition, error) {
+// ExportFunctions provides a mock function with given fields: options
+func (_m *Client) ExportFunctions(options core.ExportFunctionsOptions) ([]byte, error) {
 	ret := _m.Called(options)
 
-	var r0 *servingv1alpha1.ServiceCondition
-	if rf, ok := ret.Get(0).(func(core.ServiceStatusOptions) *servingv1alpha1.ServiceCondition); ok {
+	var r0 []byte
+	if rf, ok := ret.Get(0).(func(core.ExportFunctionsOptions) []byte); ok {
 		r0 = rf(options)
 	} else {
 		if ret.Get(0) != nil {
-			r0 = ret.Get(0).(*servingv1alpha1.ServiceCondition)
+			r0 = ret.Get(0).([]byte)
 		}
 	}
 
 	var r1 error
-	if rf, ok := ret.Get(1).(func(core.ServiceStatusOptions) error); ok {
+	if rf, ok := ret.Get(1).(func(core.ExportFunctionsOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FunctionExists provides a mock function with given fields: options
+func (_m *Client) FunctionExists(options core.FunctionExistsOptions) (bool, error) {
+	ret := _m.Called(options)
+
+	var r0 bool
+	if rf, ok := ret.Get(0).(func(core.FunctionExistsOptions) bool); ok {
+		r0 = rf(options)
+	} else {
+		r0 = ret.Get(0).(bool)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.FunctionExistsOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FunctionLogs provides a mock function with given fields: options
+func (_m *Client) FunctionLogs(options core.FunctionLogsOptions) (io.ReadCloser, error) {
+	ret := _m.Called(options)
+
+	var r0 io.ReadCloser
+	if rf, ok := ret.Get(0).(func(core.FunctionLogsOptions) io.ReadCloser); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(io.ReadCloser)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.FunctionLogsOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FunctionStatuses provides a mock function with given fields: options
+func (_m *Client) FunctionStatuses(options core.ListFunctionOptions) ([]core.FunctionStatus, error) {
+	ret := _m.Called(options)
+
+	var r0 []core.FunctionStatus
+	if rf, ok := ret.Get(0).(func(core.ListFunctionOptions) []core.FunctionStatus); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.FunctionStatus)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ListFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// FunctionURL provides a mock function with given fields: options
+func (_m *Client) FunctionURL(options core.FunctionURLOptions) (string, error) {
+	ret := _m.Called(options)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(core.FunctionURLOptions) string); ok {
+		r0 = rf(options)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.FunctionURLOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// GetFunction provides a mock function with given fields: options
+func (_m *Client) GetFunction(options core.GetFunctionOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.GetFunctionOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.GetFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// InvokeFunction provides a mock function with given fields: ctx, options
+func (_m *Client) InvokeFunction(ctx context.Context, options core.InvokeFunctionOptions) (int, []byte, error) {
+	ret := _m.Called(ctx, options)
+
+	var r0 int
+	if rf, ok := ret.Get(0).(func(context.Context, core.InvokeFunctionOptions) int); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Get(0).(int)
+	}
+
+	var r1 []byte
+	if rf, ok := ret.Get(1).(func(context.Context, core.InvokeFunctionOptions) []byte); ok {
+		r1 = rf(ctx, options)
+	} else {
+		if ret.Get(1) != nil {
+			r1 = ret.Get(1).([]byte)
+		}
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(context.Context, core.InvokeFunctionOptions) error); ok {
+		r2 = rf(ctx, options)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ListChannels provides a mock function with given fields: options
+func (_m *Client) ListChannels(options core.ListChannelOptions) (*v1alpha1.ChannelList, error) {
+	ret := _m.Called(options)
+
+	var r0 *v1alpha1.ChannelList
+	if rf, ok := ret.Get(0).(func(core.ListChannelOptions) *v1alpha1.ChannelList); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha1.ChannelList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ListChannelOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListFunctions provides a mock function with given fields: options
+func (_m *Client) ListFunctions(options core.ListFunctionOptions) (*servingv1alpha1.ServiceList, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.ServiceList
+	if rf, ok := ret.Get(0).(func(core.ListFunctionOptions) *servingv1alpha1.ServiceList); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.ServiceList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ListFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListRevisions provides a mock function with given fields: options
+func (_m *Client) ListRevisions(options core.ListRevisionsOptions) ([]core.RevisionSummary, error) {
+	ret := _m.Called(options)
+
+	var r0 []core.RevisionSummary
+	if rf, ok := ret.Get(0).(func(core.ListRevisionsOptions) []core.RevisionSummary); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).([]core.RevisionSummary)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ListRevisionsOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ListServices provides a mock function with given fields: options
+func (_m *Client) ListServices(options core.ListServiceOptions) (*servingv1alpha1.ServiceList, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.ServiceList
+	if rf, ok := ret.Get(0).(func(core.ListServiceOptions) *servingv1alpha1.ServiceList); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.ServiceList)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ListServiceOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// PatchFunction provides a mock function with given fields: options
+func (_m *Client) PatchFunction(options core.PatchFunctionOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.PatchFunctionOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.PatchFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Ping provides a mock function with given fields: ctx
+func (_m *Client) Ping(ctx context.Context) error {
+	ret := _m.Called(ctx)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context) error); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// ReplaceFunction provides a mock function with given fields: options
+func (_m *Client) ReplaceFunction(options core.ReplaceFunctionOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.ReplaceFunctionOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ReplaceFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ScaleFunction provides a mock function with given fields: options
+func (_m *Client) ScaleFunction(options core.ScaleFunctionOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.ScaleFunctionOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ScaleFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ServiceCoordinates provides a mock function with given fields: options
+func (_m *Client) ServiceCoordinates(options core.ServiceInvokeOptions) (string, string, error) {
+	ret := _m.Called(options)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(core.ServiceInvokeOptions) string); ok {
+		r0 = rf(options)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 string
+	if rf, ok := ret.Get(1).(func(core.ServiceInvokeOptions) string); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Get(1).(string)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(core.ServiceInvokeOptions) error); ok {
+		r2 = rf(options)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// ServiceStatus provides a mock function with given fields: options
+func (_m *Client) ServiceStatus(options core.ServiceStatusOptions) (*servingv1alpha1.ServiceCondition, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.ServiceCondition
+	if rf, ok := ret.Get(0).(func(core.ServiceStatusOptions) *servingv1alpha1.ServiceCondition); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.ServiceCondition)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.ServiceStatusOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// ServingVersion provides a mock function with given fields: ctx
+func (_m *Client) ServingVersion(ctx context.Context) (string, error) {
+	ret := _m.Called(ctx)
+
+	var r0 string
+	if rf, ok := ret.Get(0).(func(context.Context) string); ok {
+		r0 = rf(ctx)
+	} else {
+		r0 = ret.Get(0).(string)
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(context.Context) error); ok {
+		r1 = rf(ctx)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// SetFunctionImage provides a mock function with given fields: options
+func (_m *Client) SetFunctionImage(options core.SetFunctionImageOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.SetFunctionImageOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.SetFunctionImageOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// Subscribe provides a mock function with given fields: options
+func (_m *Client) Subscribe(options core.SubscribeOptions) (*v1alpha1.Subscription, core.SubscriptionChange, error) {
+	ret := _m.Called(options)
+
+	var r0 *v1alpha1.Subscription
+	if rf, ok := ret.Get(0).(func(core.SubscribeOptions) *v1alpha1.Subscription); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*v1alpha1.Subscription)
+		}
+	}
+
+	var r1 core.SubscriptionChange
+	if rf, ok := ret.Get(1).(func(core.SubscribeOptions) core.SubscriptionChange); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Get(1).(core.SubscriptionChange)
+	}
+
+	var r2 error
+	if rf, ok := ret.Get(2).(func(core.SubscribeOptions) error); ok {
+		r2 = rf(options)
+	} else {
+		r2 = ret.Error(2)
+	}
+
+	return r0, r1, r2
+}
+
+// UpdateFunction provides a mock function with given fields: options
+func (_m *Client) UpdateFunction(options core.UpdateFunctionOptions) (*servingv1alpha1.Service, error) {
+	ret := _m.Called(options)
+
+	var r0 *servingv1alpha1.Service
+	if rf, ok := ret.Get(0).(func(core.UpdateFunctionOptions) *servingv1alpha1.Service); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(*servingv1alpha1.Service)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.UpdateFunctionOptions) error); ok {
+		r1 = rf(options)
+	} else {
+		r1 = ret.Error(1)
+	}
+
+	return r0, r1
+}
+
+// WaitForBuild provides a mock function with given fields: ctx, options
+func (_m *Client) WaitForBuild(ctx context.Context, options core.WaitForBuildOptions) error {
+	ret := _m.Called(ctx, options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.WaitForBuildOptions) error); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WaitForFunctionReady provides a mock function with given fields: ctx, options
+func (_m *Client) WaitForFunctionReady(ctx context.Context, options core.WaitForFunctionReadyOptions) error {
+	ret := _m.Called(ctx, options)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func(context.Context, core.WaitForFunctionReadyOptions) error); ok {
+		r0 = rf(ctx, options)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
+
+// WatchFunctions provides a mock function with given fields: options
+func (_m *Client) WatchFunctions(options core.WatchFunctionsOptions) (watch.Interface, error) {
+	ret := _m.Called(options)
+
+	var r0 watch.Interface
+	if rf, ok := ret.Get(0).(func(core.WatchFunctionsOptions) watch.Interface); ok {
+		r0 = rf(options)
+	} else {
+		if ret.Get(0) != nil {
+			r0 = ret.Get(0).(watch.Interface)
+		}
+	}
+
+	var r1 error
+	if rf, ok := ret.Get(1).(func(core.WatchFunctionsOptions) error); ok {
 		r1 = rf(options)
 	} else {
 		r1 = ret.Error(1)
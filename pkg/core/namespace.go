@@ -17,7 +17,24 @@
 
 package core
 
-import "fmt"
+import (
+	"context"
+	"fmt"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const (
+	// defaultNamespace is used when neither the options nor the kubeconfig name a namespace
+	defaultNamespace = "default"
+
+	namespaceDeletionPollInterval = 1 * time.Second
+)
 
 type Namespaced struct {
 	Namespace string
@@ -28,13 +45,69 @@ type NamespaceInitOptions struct {
 	SecretName    string
 }
 
+// DefaultNamespace returns the namespace of the current context of clientConfig, which honors the kubeconfig and
+// context it was loaded with, falling back to "default" when there is no config, the context has no namespace or the
+// kubeconfig can't be loaded.
+func DefaultNamespace(clientConfig clientcmd.ClientConfig) string {
+	if clientConfig == nil {
+		return defaultNamespace
+	}
+	namespace, _, err := clientConfig.Namespace()
+	if err != nil || namespace == "" {
+		return defaultNamespace
+	}
+	return namespace
+}
+
 func (c *client) explicitOrConfigNamespace(namespaced Namespaced) string {
 	if namespaced.Namespace != "" {
 		return namespaced.Namespace
-	} else {
-		namespace, _, _ := c.clientConfig.Namespace()
-		return namespace
 	}
+	return DefaultNamespace(c.clientConfig)
+}
+
+type CreateNamespaceOptions struct {
+	Name string
+}
+
+// CreateNamespace creates a namespace, or returns the existing one if there is already a namespace with that name.
+func (c *client) CreateNamespace(options CreateNamespaceOptions) (*core_v1.Namespace, error) {
+	namespaces := c.kubeClient.CoreV1().Namespaces()
+	created, err := namespaces.Create(&core_v1.Namespace{ObjectMeta: meta_v1.ObjectMeta{Name: options.Name}})
+	if errors.IsAlreadyExists(err) {
+		return namespaces.Get(options.Name, meta_v1.GetOptions{})
+	}
+	return created, err
+}
+
+type DeleteNamespaceOptions struct {
+	Name string
+	Wait bool
+}
+
+// DeleteNamespace deletes a namespace, along with everything in it. When Wait is set, this blocks until the namespace
+// is fully terminated, or ctx is done.
+func (c *client) DeleteNamespace(ctx context.Context, options DeleteNamespaceOptions) error {
+	namespaces := c.kubeClient.CoreV1().Namespaces()
+	err := namespaces.Delete(options.Name, nil)
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("namespace %q does not exist", options.Name)
+	}
+	if err != nil || !options.Wait {
+		return err
+	}
+
+	err = wait.PollImmediateUntil(namespaceDeletionPollInterval, func() (bool, error) {
+		_, err := namespaces.Get(options.Name, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			return true, nil
+		}
+		return false, err
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return &WaitError{Message: fmt.Sprintf("namespace %q was not terminated", options.Name), Cause: ctx.Err()}
+	}
+	return err
 }
 
 func (kc *kubectlClient) NamespaceInit(options NamespaceInitOptions) error {
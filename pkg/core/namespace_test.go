@@ -0,0 +1,223 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+const namespaceKubeconfig = `apiVersion: v1
+kind: Config
+clusters:
+- name: local
+  cluster: {server: "https://127.0.0.1:6443"}
+contexts:
+- name: dev
+  context: {cluster: local, user: me, namespace: team-a}
+- name: prod
+  context: {cluster: local, user: me}
+current-context: %s
+users:
+- name: me
+  user: {token: secret}
+`
+
+var _ = Describe("The default namespace", func() {
+
+	var kubeconfig string
+
+	writeKubeconfig := func(currentContext string) {
+		f, err := ioutil.TempFile("", "kubeconfig")
+		Expect(err).NotTo(HaveOccurred())
+		defer f.Close()
+		_, err = f.WriteString(fmt.Sprintf(namespaceKubeconfig, currentContext))
+		Expect(err).NotTo(HaveOccurred())
+		kubeconfig = f.Name()
+	}
+
+	clientConfig := func(kubeContext string) clientcmd.ClientConfig {
+		return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
+			&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfig},
+			&clientcmd.ConfigOverrides{CurrentContext: kubeContext})
+	}
+
+	AfterEach(func() {
+		os.Remove(kubeconfig)
+	})
+
+	It("should be the namespace of the current context", func() {
+		writeKubeconfig("dev")
+		Expect(core.DefaultNamespace(clientConfig(""))).To(Equal("team-a"))
+	})
+
+	It("should be the namespace of the context overriding the current one", func() {
+		writeKubeconfig("prod")
+		Expect(core.DefaultNamespace(clientConfig("dev"))).To(Equal("team-a"))
+	})
+
+	It("should fall back to 'default' when the current context has no namespace", func() {
+		writeKubeconfig("prod")
+		Expect(core.DefaultNamespace(clientConfig(""))).To(Equal("default"))
+	})
+
+	It("should fall back to 'default' when the kubeconfig can't be loaded", func() {
+		kubeconfig = "/does/not/exist"
+		Expect(core.DefaultNamespace(clientConfig(""))).To(Equal("default"))
+	})
+
+	It("should fall back to 'default' without a kubeconfig", func() {
+		Expect(core.DefaultNamespace(nil)).To(Equal("default"))
+	})
+})
+
+var _ = Describe("Managing namespaces", func() {
+
+	var (
+		server   *httptest.Server
+		requests []string
+		// namespaces holds the phase of the existing namespaces, by name
+		namespaces map[string]string
+		// terminatingGets is the number of times a deleted namespace is still found, before it is gone
+		terminatingGets int
+		client          core.Client
+	)
+
+	notFound := func(w http.ResponseWriter, name string) {
+		w.WriteHeader(http.StatusNotFound)
+		fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404,"message":"namespaces \"%s\" not found"}`, name)
+	}
+
+	BeforeEach(func() {
+		requests = []string{}
+		namespaces = map[string]string{"default": "Active"}
+		terminatingGets = 0
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			name := strings.TrimPrefix(r.URL.Path, "/api/v1/namespaces/")
+			switch {
+			case r.Method == http.MethodPost && r.URL.Path == "/api/v1/namespaces":
+				if _, ok := namespaces["numbers"]; ok {
+					w.WriteHeader(http.StatusConflict)
+					fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"AlreadyExists","code":409,"message":"namespaces \"numbers\" already exists"}`)
+					return
+				}
+				namespaces["numbers"] = "Active"
+				w.WriteHeader(http.StatusCreated)
+				fmt.Fprint(w, `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"numbers"},"status":{"phase":"Active"}}`)
+			case r.Method == http.MethodGet:
+				phase, ok := namespaces[name]
+				if ok && phase == "Terminating" {
+					if terminatingGets == 0 {
+						delete(namespaces, name)
+						ok = false
+					}
+					terminatingGets--
+				}
+				if !ok {
+					notFound(w, name)
+					return
+				}
+				fmt.Fprintf(w, `{"apiVersion":"v1","kind":"Namespace","metadata":{"name":"%s","uid":"existing"},"status":{"phase":"%s"}}`, name, phase)
+			case r.Method == http.MethodDelete:
+				if _, ok := namespaces[name]; !ok {
+					notFound(w, name)
+					return
+				}
+				namespaces[name] = "Terminating"
+				fmt.Fprintf(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, nil)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should create a namespace", func() {
+		ns, err := client.CreateNamespace(core.CreateNamespaceOptions{Name: "numbers"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns.Name).To(Equal("numbers"))
+		Expect(requests).To(Equal([]string{"POST /api/v1/namespaces"}))
+	})
+
+	It("should return the namespace when it already exists", func() {
+		namespaces["numbers"] = "Active"
+
+		ns, err := client.CreateNamespace(core.CreateNamespaceOptions{Name: "numbers"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(ns.Name).To(Equal("numbers"))
+		Expect(ns.UID).To(BeEquivalentTo("existing"))
+		Expect(requests).To(Equal([]string{"POST /api/v1/namespaces", "GET /api/v1/namespaces/numbers"}))
+	})
+
+	It("should delete a namespace without waiting for it to terminate", func() {
+		namespaces["numbers"] = "Active"
+
+		err := client.DeleteNamespace(context.Background(), core.DeleteNamespaceOptions{Name: "numbers"})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{"DELETE /api/v1/namespaces/numbers"}))
+	})
+
+	It("should report a missing namespace when deleting", func() {
+		err := client.DeleteNamespace(context.Background(), core.DeleteNamespaceOptions{Name: "numbers"})
+		Expect(err).To(MatchError(`namespace "numbers" does not exist`))
+	})
+
+	It("should wait for the namespace to be terminated", func() {
+		namespaces["numbers"] = "Active"
+		terminatingGets = 1
+
+		err := client.DeleteNamespace(context.Background(), core.DeleteNamespaceOptions{Name: "numbers", Wait: true})
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{
+			"DELETE /api/v1/namespaces/numbers",
+			"GET /api/v1/namespaces/numbers",
+			"GET /api/v1/namespaces/numbers",
+		}))
+	})
+
+	It("should give up waiting when the context is done", func() {
+		namespaces["numbers"] = "Active"
+		terminatingGets = 1000
+
+		ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+		defer cancel()
+		err := client.DeleteNamespace(ctx, core.DeleteNamespaceOptions{Name: "numbers", Wait: true})
+		Expect(err).To(MatchError(`namespace "numbers" was not terminated: context deadline exceeded`))
+		Expect(err.(*core.WaitError).Cause).To(Equal(context.DeadlineExceeded))
+	})
+})
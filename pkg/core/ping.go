@@ -0,0 +1,71 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+)
+
+// Ping checks that the cluster is reachable and that the version of knative serving riff supports is installed,
+// returning a ClusterUnreachableError, a ServingNotInstalledError or an IncompatibleServingError otherwise. It gives up
+// once ctx is done.
+func (c *client) Ping(ctx context.Context) error {
+	return untilDone(ctx, c.ping)
+}
+
+// untilDone runs f, returning a ClusterUnreachableError if ctx is done before f returns. f keeps running in the
+// background in that case, as discovery calls can't be cancelled.
+func untilDone(ctx context.Context, f func() error) error {
+	result := make(chan error, 1)
+	go func() {
+		result <- f()
+	}()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return &ClusterUnreachableError{Cause: ctx.Err()}
+	}
+}
+
+func (c *client) ping() error {
+	discovery := c.kubeClient.Discovery()
+	if _, err := discovery.ServerVersion(); err != nil {
+		return &ClusterUnreachableError{Cause: err}
+	}
+	if err := c.checkServingCompatible(); err != nil {
+		return err
+	}
+
+	groupVersion := v1alpha1.SchemeGroupVersion.String()
+	resources, err := discovery.ServerResourcesForGroupVersion(groupVersion)
+	if errors.IsNotFound(err) {
+		return &ServingNotInstalledError{GroupVersion: groupVersion}
+	} else if err != nil {
+		return err
+	}
+	for _, resource := range resources.APIResources {
+		if resource.Name == "services" {
+			return nil
+		}
+	}
+	return &ServingNotInstalledError{GroupVersion: groupVersion}
+}
@@ -0,0 +1,127 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Pinging the cluster", func() {
+
+	var (
+		server *httptest.Server
+		// groups is the API group list served for discovery
+		groups string
+		// resources is the resource list served for the v1alpha1 knative serving API, which is not found when empty
+		resources string
+		// hang makes the API server never answer, until the test ends
+		hang   chan struct{}
+		client core.Client
+	)
+
+	BeforeEach(func() {
+		groups = servingGroups("v1alpha1")
+		resources = `{"kind":"APIResourceList","groupVersion":"serving.knative.dev/v1alpha1","resources":[{"name":"services","namespaced":true,"kind":"Service","verbs":["get"]}]}`
+		hang = nil
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if hang != nil {
+				<-hang
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/version":
+				fmt.Fprint(w, `{"major":"1","minor":"11","gitVersion":"v1.11.3"}`)
+			case "/api":
+				fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+			case "/apis":
+				fmt.Fprint(w, groups)
+			case "/apis/serving.knative.dev/v1alpha1":
+				if resources == "" {
+					http.NotFound(w, r)
+					return
+				}
+				fmt.Fprint(w, resources)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, nil)
+	})
+
+	AfterEach(func() {
+		if hang != nil {
+			close(hang)
+		}
+		server.Close()
+	})
+
+	It("should succeed when the cluster serves knative serving", func() {
+		Expect(client.Ping(context.Background())).To(Succeed())
+	})
+
+	It("should report a cluster that can't be reached", func() {
+		server.Close()
+
+		err := client.Ping(context.Background())
+		Expect(err).To(BeAssignableToTypeOf(&core.ClusterUnreachableError{}))
+	})
+
+	It("should report a cluster that doesn't answer in time", func() {
+		hang = make(chan struct{})
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+
+		err := client.Ping(ctx)
+		Expect(err).To(Equal(&core.ClusterUnreachableError{Cause: context.DeadlineExceeded}))
+	})
+
+	It("should report knative serving not being installed", func() {
+		groups = `{"kind":"APIGroupList","groups":[]}`
+		resources = ""
+
+		err := client.Ping(context.Background())
+		Expect(err).To(Equal(&core.ServingNotInstalledError{GroupVersion: "serving.knative.dev/v1alpha1"}))
+	})
+
+	It("should report knative serving not serving functions", func() {
+		resources = `{"kind":"APIResourceList","groupVersion":"serving.knative.dev/v1alpha1","resources":[{"name":"routes","namespaced":true,"kind":"Route","verbs":["get"]}]}`
+
+		err := client.Ping(context.Background())
+		Expect(err).To(Equal(&core.ServingNotInstalledError{GroupVersion: "serving.knative.dev/v1alpha1"}))
+	})
+
+	It("should report an incompatible version of knative serving", func() {
+		groups = servingGroups("v1beta1", "v1")
+		resources = ""
+
+		err := client.Ping(context.Background())
+		Expect(err).To(Equal(&core.IncompatibleServingError{Supported: "v1alpha1", Served: []string{"v1beta1", "v1"}}))
+	})
+})
@@ -0,0 +1,159 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/knative/serving/pkg/apis/serving"
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+)
+
+type ListRevisionsOptions struct {
+	Namespaced
+	Function string
+}
+
+// RevisionSummary is the state of one of the revisions of a function.
+type RevisionSummary struct {
+	Name    string
+	Image   string
+	Created time.Time
+	// Ready is nil if the revision doesn't report a Ready condition (yet)
+	Ready *ConditionDescription
+	// TrafficPercent is the share of the function traffic routed to the revision
+	TrafficPercent int
+}
+
+// ListRevisions returns the revisions of a function, most recent first, along with their readiness and the share
+// of traffic they receive. A function that is not routed (yet) has no traffic assigned to any of its revisions.
+func (c *client) ListRevisions(options ListRevisionsOptions) ([]RevisionSummary, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	s, err := c.function(options.Namespaced, options.Function)
+	if err != nil {
+		return nil, err
+	}
+
+	// revisions are labeled with the name of their configuration, which is the name of the service
+	selector := labels.Set{serving.ConfigurationLabelKey: s.Name}.String()
+	revisions, err := c.serving.ServingV1alpha1().Revisions(ns).List(meta_v1.ListOptions{LabelSelector: selector})
+	if err != nil {
+		return nil, err
+	}
+
+	traffic := map[string]int{}
+	route, err := c.serving.ServingV1alpha1().Routes(ns).Get(s.Name, meta_v1.GetOptions{})
+	if err == nil {
+		for _, target := range route.Status.Traffic {
+			traffic[target.RevisionName] += target.Percent
+		}
+	} else if !errors.IsNotFound(err) {
+		return nil, err
+	}
+
+	summaries := make([]RevisionSummary, 0, len(revisions.Items))
+	for _, revision := range revisions.Items {
+		summary := RevisionSummary{
+			Name:           revision.Name,
+			Image:          revision.Spec.Container.Image,
+			Created:        revision.CreationTimestamp.Time,
+			TrafficPercent: traffic[revision.Name],
+		}
+		if cond := revision.Status.GetCondition(v1alpha1.RevisionConditionReady); cond != nil {
+			summary.Ready = &ConditionDescription{
+				Type:               string(cond.Type),
+				Status:             string(cond.Status),
+				Reason:             cond.Reason,
+				Message:            cond.Message,
+				LastTransitionTime: cond.LastTransitionTime.Inner.Time,
+			}
+		}
+		summaries = append(summaries, summary)
+	}
+	sort.SliceStable(summaries, func(i, j int) bool {
+		return summaries[i].Created.After(summaries[j].Created)
+	})
+	return summaries, nil
+}
+
+type DeleteRevisionOptions struct {
+	Namespaced
+	Name string
+	// Force deletes the revision even if the function still uses it
+	Force bool
+}
+
+// DeleteRevision deletes a revision of a function. Unless forced, this is refused if the function still uses the
+// revision, as deleting it would break the function: when the route of the function sends traffic to the revision,
+// when the function is pinned to it, or when it is the latest ready revision of a function running the latest one.
+func (c *client) DeleteRevision(options DeleteRevisionOptions) error {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	revisions := c.serving.ServingV1alpha1().Revisions(ns)
+	revision, err := revisions.Get(options.Name, meta_v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return fmt.Errorf("revision %q does not exist in namespace %q", options.Name, ns)
+	} else if err != nil {
+		return err
+	}
+
+	if !options.Force {
+		// revisions are labeled with the name of their configuration, itself named after the service
+		if err := c.checkRevisionUnused(ns, revision.Labels[serving.ConfigurationLabelKey], options.Name); err != nil {
+			return err
+		}
+	}
+
+	return revisions.Delete(options.Name, nil)
+}
+
+// checkRevisionUnused makes sure a function doesn't use one of its revisions, which is then safe to delete.
+func (c *client) checkRevisionUnused(ns string, function string, revision string) error {
+	s, err := c.serving.ServingV1alpha1().Services(ns).Get(function, meta_v1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		if s.Spec.Pinned != nil && s.Spec.Pinned.RevisionName == revision {
+			return fmt.Errorf("refusing to delete revision %q, which function %q is pinned to", revision, function)
+		}
+		if s.Spec.RunLatest != nil && s.Status.LatestReadyRevisionName == revision {
+			return fmt.Errorf("refusing to delete revision %q, which is the latest ready revision of function %q", revision, function)
+		}
+	}
+
+	// the route of a function is named after the service
+	route, err := c.serving.ServingV1alpha1().Routes(ns).Get(function, meta_v1.GetOptions{})
+	if err != nil && !errors.IsNotFound(err) {
+		return err
+	}
+	if err == nil {
+		for _, target := range route.Status.Traffic {
+			if target.RevisionName == revision {
+				return fmt.Errorf("refusing to delete revision %q, which still receives %d%% of the traffic of function %q",
+					revision, target.Percent, function)
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,149 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Deleting revisions", func() {
+
+	const servingPath = "/apis/serving.knative.dev/v1alpha1/namespaces/ns/"
+
+	var (
+		server   *httptest.Server
+		requests []string
+		// spec is the spec of the function the revisions belong to
+		spec string
+		// latestReady is the latest ready revision of the function
+		latestReady string
+		// traffic is the traffic of the route of the function, which has no route when empty
+		traffic string
+		client  core.Client
+		options core.DeleteRevisionOptions
+	)
+
+	BeforeEach(func() {
+		requests = []string{}
+		spec = `{"runLatest":{"configuration":{}}}`
+		latestReady = "square-00003"
+		traffic = `[{"revisionName":"square-00003","percent":100}]`
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == servingPath+"revisions/square-00001":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Revision","metadata":{"name":"square-00001","labels":{"serving.knative.dev/configuration":"square"}}}`)
+			case r.Method == http.MethodGet && r.URL.Path == servingPath+"services/square":
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square"},"spec":%s,"status":{"latestReadyRevisionName":"%s"}}`, spec, latestReady)
+			case r.Method == http.MethodGet && r.URL.Path == servingPath+"routes/square" && traffic != "":
+				fmt.Fprintf(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Route","metadata":{"name":"square"},"status":{"traffic":%s}}`, traffic)
+			case r.Method == http.MethodDelete && r.URL.Path == servingPath+"revisions/square-00001":
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Success"}`)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		servingClient, err := serving.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, nil, servingClient)
+
+		options = core.DeleteRevisionOptions{Name: "square-00001"}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should delete a revision the function doesn't use", func() {
+		err := client.DeleteRevision(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(ContainElement("DELETE " + servingPath + "revisions/square-00001"))
+	})
+
+	It("should delete a revision of a function that is not routed yet", func() {
+		traffic = ""
+
+		err := client.DeleteRevision(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(ContainElement("DELETE " + servingPath + "revisions/square-00001"))
+	})
+
+	It("should refuse to delete a revision serving traffic", func() {
+		traffic = `[{"revisionName":"square-00001","percent":20},{"revisionName":"square-00003","percent":80}]`
+
+		err := client.DeleteRevision(options)
+		Expect(err).To(MatchError(`refusing to delete revision "square-00001", which still receives 20% of the traffic of function "square"`))
+		Expect(requests).NotTo(ContainElement("DELETE " + servingPath + "revisions/square-00001"))
+	})
+
+	It("should refuse to delete the revision a function is pinned to", func() {
+		spec = `{"pinned":{"revisionName":"square-00001","configuration":{}}}`
+
+		err := client.DeleteRevision(options)
+		Expect(err).To(MatchError(`refusing to delete revision "square-00001", which function "square" is pinned to`))
+		Expect(requests).NotTo(ContainElement("DELETE " + servingPath + "revisions/square-00001"))
+	})
+
+	It("should refuse to delete the latest ready revision of a function running the latest one", func() {
+		latestReady = "square-00001"
+		traffic = ""
+
+		err := client.DeleteRevision(options)
+		Expect(err).To(MatchError(`refusing to delete revision "square-00001", which is the latest ready revision of function "square"`))
+		Expect(requests).NotTo(ContainElement("DELETE " + servingPath + "revisions/square-00001"))
+	})
+
+	It("should delete the latest ready revision of a pinned function", func() {
+		spec = `{"pinned":{"revisionName":"square-00003","configuration":{}}}`
+		latestReady = "square-00001"
+
+		err := client.DeleteRevision(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(ContainElement("DELETE " + servingPath + "revisions/square-00001"))
+	})
+
+	It("should delete a revision in use when forced", func() {
+		spec = `{"pinned":{"revisionName":"square-00001","configuration":{}}}`
+		traffic = `[{"revisionName":"square-00001","percent":100}]`
+		options.Force = true
+
+		err := client.DeleteRevision(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(requests).To(Equal([]string{
+			"GET " + servingPath + "revisions/square-00001",
+			"DELETE " + servingPath + "revisions/square-00001",
+		}))
+	})
+
+	It("should fail for a missing revision", func() {
+		options.Name = "square-00009"
+
+		err := client.DeleteRevision(options)
+		Expect(err).To(MatchError(`revision "square-00009" does not exist in namespace "ns"`))
+	})
+})
@@ -0,0 +1,56 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+)
+
+// serverDryRunMinorVersion is the first kubernetes 1.x release with server-side dry run enabled by default, custom
+// resources included. Older API servers either reject the dryRun parameter or, worse, ignore it and persist the
+// object.
+const serverDryRunMinorVersion = 13
+
+// createFunctionServerDryRun submits the service backing a function for creation in server-side dry run mode, so that
+// it goes through validation and admission webhooks without being persisted. The service as the server would have
+// stored it, defaults and mutations included, is returned.
+func (c *client) createFunctionServerDryRun(ns string, s *v1alpha1.Service) (*v1alpha1.Service, error) {
+	if err := c.checkServerDryRunSupported(); err != nil {
+		return nil, err
+	}
+	// the vendored client-go predates CreateOptions, hence the raw dryRun parameter
+	result := &v1alpha1.Service{}
+	err := c.serving.ServingV1alpha1().RESTClient().Post().
+		Namespace(ns).Resource("services").Param("dryRun", "All").Body(s).Do().Into(result)
+	return result, err
+}
+
+func (c *client) checkServerDryRunSupported() error {
+	version, err := c.kubeClient.Discovery().ServerVersion()
+	if err != nil {
+		return err
+	}
+	minor, err := strconv.Atoi(strings.TrimSuffix(version.Minor, "+"))
+	if err != nil || version.Major != "1" || minor < serverDryRunMinorVersion {
+		return fmt.Errorf("server-side dry run requires kubernetes 1.%d or later, the cluster runs %s", serverDryRunMinorVersion, version.GitVersion)
+	}
+	return nil
+}
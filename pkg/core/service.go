@@ -46,6 +46,13 @@ type CreateServiceOptions struct {
 	Env     []string
 	EnvFrom []string
 	DryRun  bool
+
+	// AllowReservedEnv allows setting environment variables knative reserves, such as PORT
+	AllowReservedEnv bool
+
+	// PinnedRevision is the name of the revision to route all traffic to. When empty, traffic is routed to the
+	// latest ready revision.
+	PinnedRevision string
 }
 
 func (c *client) CreateService(options CreateServiceOptions) (*v1alpha1.Service, error) {
@@ -75,6 +82,20 @@ func newService(options CreateServiceOptions) (*v1alpha1.Service, error) {
 		return nil, err
 	}
 	envVars = append(envVars, envVarsFrom...)
+	if err := checkReservedEnvVars(envVars, options.AllowReservedEnv); err != nil {
+		return nil, err
+	}
+
+	configuration := v1alpha1.ConfigurationSpec{
+		RevisionTemplate: v1alpha1.RevisionTemplateSpec{
+			Spec: v1alpha1.RevisionSpec{
+				Container: core_v1.Container{
+					Env:   envVars,
+					Image: options.Image,
+				},
+			},
+		},
+	}
 
 	s := v1alpha1.Service{
 		TypeMeta: meta_v1.TypeMeta{
@@ -84,20 +105,16 @@ func newService(options CreateServiceOptions) (*v1alpha1.Service, error) {
 		ObjectMeta: meta_v1.ObjectMeta{
 			Name: options.Name,
 		},
-		Spec: v1alpha1.ServiceSpec{
-			RunLatest: &v1alpha1.RunLatestType{
-				Configuration: v1alpha1.ConfigurationSpec{
-					RevisionTemplate: v1alpha1.RevisionTemplateSpec{
-						Spec: v1alpha1.RevisionSpec{
-							Container: core_v1.Container{
-								Env:   envVars,
-								Image: options.Image,
-							},
-						},
-					},
-				},
-			},
-		},
+	}
+	if options.PinnedRevision != "" {
+		s.Spec.Pinned = &v1alpha1.PinnedType{
+			RevisionName:  options.PinnedRevision,
+			Configuration: configuration,
+		}
+	} else {
+		s.Spec.RunLatest = &v1alpha1.RunLatestType{
+			Configuration: configuration,
+		}
 	}
 
 	return &s, nil
@@ -131,10 +148,26 @@ type ServiceInvokeOptions struct {
 
 func (c *client) ServiceCoordinates(options ServiceInvokeOptions) (string, string, error) {
 
-	ksvc, err := c.kubeClient.CoreV1().Services(istioNamespace).Get(ingressServiceName, meta_v1.GetOptions{})
+	ingress, err := c.ingressAddress()
 	if err != nil {
 		return "", "", err
 	}
+
+	s, err := c.service(options.Namespaced, options.Name)
+	if err != nil {
+		return "", "", err
+	}
+
+	return ingress, s.Status.Domain, nil
+}
+
+// ingressAddress returns the address of the ingress gateway routing requests to services, its load balancer if it
+// has one, or its node port otherwise.
+func (c *client) ingressAddress() (string, error) {
+	ksvc, err := c.kubeClient.CoreV1().Services(istioNamespace).Get(ingressServiceName, meta_v1.GetOptions{})
+	if err != nil {
+		return "", err
+	}
 	var ingress string
 	if ksvc.Spec.Type == "LoadBalancer" {
 		ingresses := ksvc.Status.LoadBalancer.Ingress
@@ -150,7 +183,7 @@ func (c *client) ServiceCoordinates(options ServiceInvokeOptions) (string, strin
 			if port.Name == "http" {
 				config, err := c.clientConfig.ClientConfig()
 				if err != nil {
-					return "", "", err
+					return "", err
 				}
 				host := config.Host[0:strings.LastIndex(config.Host, ":")]
 				host = strings.Replace(host, "https", "http", 1)
@@ -158,16 +191,22 @@ func (c *client) ServiceCoordinates(options ServiceInvokeOptions) (string, strin
 			}
 		}
 		if ingress == "" {
-			return "", "", errors.New("Ingress not available")
+			return "", errors.New("Ingress not available")
 		}
 	}
+	return ingress, nil
+}
 
-	s, err := c.service(options.Namespaced, options.Name)
-	if err != nil {
-		return "", "", err
+// ServiceConfiguration returns the configuration held by the service, whichever the kind of service it is.
+func ServiceConfiguration(s *v1alpha1.Service) (*v1alpha1.ConfigurationSpec, error) {
+	switch {
+	case s.Spec.RunLatest != nil:
+		return &s.Spec.RunLatest.Configuration, nil
+	case s.Spec.Pinned != nil:
+		return &s.Spec.Pinned.Configuration, nil
+	default:
+		return nil, fmt.Errorf("service %q has no configuration", s.Name)
 	}
-
-	return ingress, s.Status.Domain, nil
 }
 
 func (c *client) service(namespace Namespaced, name string) (*v1alpha1.Service, error) {
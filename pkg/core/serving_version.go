@@ -0,0 +1,70 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+
+	"github.com/knative/serving/pkg/apis/serving/v1alpha1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ServingVersion returns the version of the knative serving API preferred by the cluster, e.g. "v1alpha1", or a
+// ServingNotInstalledError if the cluster doesn't serve knative serving at all. It gives up once ctx is done.
+func (c *client) ServingVersion(ctx context.Context) (string, error) {
+	var version string
+	err := untilDone(ctx, func() error {
+		group, err := c.servingGroup()
+		if err != nil {
+			return err
+		}
+		version = group.PreferredVersion.Version
+		return nil
+	})
+	return version, err
+}
+
+// servingGroup looks up the knative serving API group as advertised by the cluster.
+func (c *client) servingGroup() (*meta_v1.APIGroup, error) {
+	groups, err := c.kubeClient.Discovery().ServerGroups()
+	if err != nil {
+		return nil, err
+	}
+	for i := range groups.Groups {
+		if groups.Groups[i].Name == v1alpha1.SchemeGroupVersion.Group {
+			return &groups.Groups[i], nil
+		}
+	}
+	return nil, &ServingNotInstalledError{GroupVersion: v1alpha1.SchemeGroupVersion.String()}
+}
+
+// checkServingCompatible returns an IncompatibleServingError if the cluster doesn't serve the version of the knative
+// serving API riff is built against, which would otherwise surface as obscure decoding errors.
+func (c *client) checkServingCompatible() error {
+	group, err := c.servingGroup()
+	if err != nil {
+		return err
+	}
+	var served []string
+	for _, version := range group.Versions {
+		if version.Version == v1alpha1.SchemeGroupVersion.Version {
+			return nil
+		}
+		served = append(served, version.Version)
+	}
+	return &IncompatibleServingError{Supported: v1alpha1.SchemeGroupVersion.Version, Served: served}
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Serving version detection", func() {
+
+	var (
+		groups string
+		server *httptest.Server
+		client core.Client
+	)
+
+	BeforeEach(func() {
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch r.URL.Path {
+			case "/api":
+				fmt.Fprint(w, `{"kind":"APIVersions","versions":["v1"]}`)
+			case "/apis":
+				fmt.Fprint(w, groups)
+			default:
+				http.NotFound(w, r)
+			}
+		}))
+		kubeClient, err := kubernetes.NewForConfig(&rest.Config{Host: server.URL})
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, kubeClient, nil, nil)
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	It("should return the preferred serving version", func() {
+		groups = servingGroups("v1alpha1")
+
+		version, err := client.ServingVersion(context.Background())
+		Expect(err).NotTo(HaveOccurred())
+		Expect(version).To(Equal("v1alpha1"))
+	})
+
+	It("should report serving not being installed", func() {
+		groups = `{"kind":"APIGroupList","groups":[]}`
+
+		_, err := client.ServingVersion(context.Background())
+		Expect(err).To(MatchError("knative serving not installed, the cluster doesn't serve serving.knative.dev/v1alpha1 services"))
+	})
+
+	It("should refuse to create functions on an incompatible serving version", func() {
+		groups = servingGroups("v1beta1", "v1")
+
+		_, err := client.CreateFunction(core.CreateFunctionOptions{
+			Namespaced: core.Namespaced{Namespace: "default"},
+			Name:       "square",
+			Image:      "projectriff/square",
+		})
+		Expect(err).To(MatchError("knative serving v1beta1, v1 is not supported by this version of riff, which requires v1alpha1"))
+	})
+})
+
+// servingGroups describes a cluster serving the given versions of knative serving, the first one being preferred.
+func servingGroups(versions ...string) string {
+	served := ""
+	for i, version := range versions {
+		if i > 0 {
+			served += ","
+		}
+		served += fmt.Sprintf(`{"groupVersion":"serving.knative.dev/%s","version":"%s"}`, version, version)
+	}
+	return fmt.Sprintf(`{"kind":"APIGroupList","groups":[{"name":"serving.knative.dev","versions":[%s],"preferredVersion":{"groupVersion":"serving.knative.dev/%s","version":"%s"}}]}`,
+		served, versions[0], versions[0])
+}
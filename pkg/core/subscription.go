@@ -17,7 +17,10 @@
 package core
 
 import (
+	"fmt"
+
 	"github.com/knative/eventing/pkg/apis/channels/v1alpha1"
+	"k8s.io/apimachinery/pkg/api/errors"
 	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
@@ -55,3 +58,81 @@ func (c *client) CreateSubscription(options CreateSubscriptionOptions) (*v1alpha
 	}
 
 }
+
+type SubscribeOptions struct {
+	Namespaced
+	// Name of the subscription, defaults to the name of the function
+	Name     string
+	Function string
+	Channel  string
+	DryRun   bool
+}
+
+// subscribeAttempts is how many times Subscribe tries, in case of conflicting concurrent changes
+const subscribeAttempts = 5
+
+// SubscriptionChange tells what Subscribe did to the subscription.
+type SubscriptionChange string
+
+const (
+	SubscriptionCreated   SubscriptionChange = "created"
+	SubscriptionUpdated   SubscriptionChange = "updated"
+	SubscriptionUnchanged SubscriptionChange = "unchanged"
+)
+
+// Subscribe wires a channel to a function, by creating a subscription having the function as its subscriber. Both
+// the function and the channel must exist. If the subscription already exists, it is updated to wire the given
+// channel and function instead, or left untouched if it already does, so that subscribing can be repeated safely. The
+// resulting subscription is returned, so that its readiness can be watched.
+func (c *client) Subscribe(options SubscribeOptions) (*v1alpha1.Subscription, SubscriptionChange, error) {
+	ns := c.explicitOrConfigNamespace(options.Namespaced)
+
+	if _, err := c.function(options.Namespaced, options.Function); err != nil {
+		return nil, "", err
+	}
+	_, err := c.eventing.ChannelsV1alpha1().Channels(ns).Get(options.Channel, meta_v1.GetOptions{})
+	if errors.IsNotFound(err) {
+		return nil, "", fmt.Errorf("channel %q does not exist in namespace %q", options.Channel, ns)
+	} else if err != nil {
+		return nil, "", err
+	}
+
+	name := options.Name
+	if name == "" {
+		name = options.Function
+	}
+	subscriptions := c.eventing.ChannelsV1alpha1().Subscriptions(ns)
+	for attempt := 1; ; attempt++ {
+		existing, err := subscriptions.Get(name, meta_v1.GetOptions{})
+		if errors.IsNotFound(err) {
+			s, err := c.CreateSubscription(CreateSubscriptionOptions{
+				Namespaced: options.Namespaced,
+				Name:       name,
+				Channel:    options.Channel,
+				Subscriber: options.Function,
+				DryRun:     options.DryRun,
+			})
+			if errors.IsAlreadyExists(err) && attempt < subscribeAttempts {
+				continue
+			}
+			return s, SubscriptionCreated, err
+		} else if err != nil {
+			return nil, "", err
+		}
+
+		if existing.Spec.Channel == options.Channel && existing.Spec.Subscriber == options.Function {
+			return existing, SubscriptionUnchanged, nil
+		}
+		updated := existing.DeepCopy()
+		updated.Spec.Channel = options.Channel
+		updated.Spec.Subscriber = options.Function
+		if options.DryRun {
+			return updated, SubscriptionUpdated, nil
+		}
+		updated, err = subscriptions.Update(updated)
+		if errors.IsConflict(err) && attempt < subscribeAttempts {
+			continue
+		}
+		return updated, SubscriptionUpdated, err
+	}
+}
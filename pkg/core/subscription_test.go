@@ -0,0 +1,143 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+
+	"github.com/knative/eventing/pkg/apis/channels/v1alpha1"
+	eventing "github.com/knative/eventing/pkg/client/clientset/versioned"
+	serving "github.com/knative/serving/pkg/client/clientset/versioned"
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	"k8s.io/client-go/rest"
+)
+
+var _ = Describe("Subscribing functions to channels", func() {
+
+	const subscriptionPath = "/apis/channels.knative.dev/v1alpha1/namespaces/ns/subscriptions"
+
+	var (
+		server *httptest.Server
+		// existing is the subscription already in the namespace, if any
+		existing *v1alpha1.Subscription
+		requests []string
+		client   core.Client
+		options  core.SubscribeOptions
+	)
+
+	BeforeEach(func() {
+		existing = nil
+		requests = []string{}
+		server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			switch {
+			case r.URL.Path == "/apis/serving.knative.dev/v1alpha1/namespaces/ns/services/square":
+				fmt.Fprint(w, `{"apiVersion":"serving.knative.dev/v1alpha1","kind":"Service","metadata":{"name":"square","namespace":"ns"}}`)
+				return
+			case strings.HasPrefix(r.URL.Path, "/apis/channels.knative.dev/v1alpha1/namespaces/ns/channels/"):
+				fmt.Fprint(w, `{"apiVersion":"channels.knative.dev/v1alpha1","kind":"Channel","metadata":{"name":"numbers","namespace":"ns"}}`)
+				return
+			}
+
+			requests = append(requests, r.Method+" "+r.URL.Path)
+			switch {
+			case r.Method == http.MethodGet && r.URL.Path == subscriptionPath+"/square" && existing != nil:
+				Expect(json.NewEncoder(w).Encode(existing)).To(Succeed())
+			case r.Method == http.MethodPost && r.URL.Path == subscriptionPath:
+				w.WriteHeader(http.StatusCreated)
+				body, _ := ioutil.ReadAll(r.Body)
+				w.Write(body)
+			case r.Method == http.MethodPut && r.URL.Path == subscriptionPath+"/square":
+				body, _ := ioutil.ReadAll(r.Body)
+				w.Write(body)
+			default:
+				w.WriteHeader(http.StatusNotFound)
+				fmt.Fprint(w, `{"kind":"Status","apiVersion":"v1","status":"Failure","reason":"NotFound","code":404}`)
+			}
+		}))
+		config := &rest.Config{Host: server.URL}
+		eventingClient, err := eventing.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		servingClient, err := serving.NewForConfig(config)
+		Expect(err).NotTo(HaveOccurred())
+		client = core.NewClient(nil, nil, eventingClient, servingClient)
+
+		options = core.SubscribeOptions{Function: "square", Channel: "numbers"}
+		options.Namespace = "ns"
+	})
+
+	AfterEach(func() {
+		server.Close()
+	})
+
+	subscription := func(channel string) *v1alpha1.Subscription {
+		s := &v1alpha1.Subscription{}
+		s.Name = "square"
+		s.Namespace = "ns"
+		s.ResourceVersion = "42"
+		s.Spec.Channel = channel
+		s.Spec.Subscriber = "square"
+		return s
+	}
+
+	It("should create a missing subscription", func() {
+		s, change, err := client.Subscribe(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(change).To(Equal(core.SubscriptionCreated))
+		Expect(s.Spec.Channel).To(Equal("numbers"))
+		Expect(requests).To(Equal([]string{"GET " + subscriptionPath + "/square", "POST " + subscriptionPath}))
+	})
+
+	It("should update a subscription to another channel", func() {
+		existing = subscription("letters")
+
+		s, change, err := client.Subscribe(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(change).To(Equal(core.SubscriptionUpdated))
+		Expect(s.Spec.Channel).To(Equal("numbers"))
+		Expect(s.ResourceVersion).To(Equal("42"))
+		Expect(requests).To(Equal([]string{"GET " + subscriptionPath + "/square", "PUT " + subscriptionPath + "/square"}))
+	})
+
+	It("should leave a subscription wiring the same channel and function untouched", func() {
+		existing = subscription("numbers")
+
+		s, change, err := client.Subscribe(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(change).To(Equal(core.SubscriptionUnchanged))
+		Expect(s.Spec.Channel).To(Equal("numbers"))
+		Expect(requests).To(Equal([]string{"GET " + subscriptionPath + "/square"}))
+	})
+
+	It("should only tell what would change in dry run mode", func() {
+		existing = subscription("letters")
+		options.DryRun = true
+
+		s, change, err := client.Subscribe(options)
+		Expect(err).NotTo(HaveOccurred())
+		Expect(change).To(Equal(core.SubscriptionUpdated))
+		Expect(s.Spec.Channel).To(Equal("numbers"))
+		Expect(requests).To(Equal([]string{"GET " + subscriptionPath + "/square"}))
+	})
+})
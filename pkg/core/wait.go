@@ -0,0 +1,89 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	core_v1 "k8s.io/api/core/v1"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/wait"
+)
+
+// ConditionsGetter looks up a resource being waited for, along with its conditions.
+type ConditionsGetter func() (meta_v1.Object, []ConditionDescription, error)
+
+// ConditionsPredicate tells whether the conditions of a resource are the ones being waited for. An error ends the wait,
+// e.g. when the resource reached a state it won't recover from.
+type ConditionsPredicate func(conditions []ConditionDescription) (bool, error)
+
+// WaitForCondition looks up a resource at the given interval until the predicate holds for its conditions, fails, or
+// ctx is done, in which case ctx.Err() is returned. Errors of the getter end the wait, as does the resource being
+// deleted and created again in the meantime, which is detected by a change of its UID.
+func WaitForCondition(ctx context.Context, interval time.Duration, get ConditionsGetter, done ConditionsPredicate) error {
+	uid, first := "", true
+	return pollUntilDone(ctx, interval, func() (bool, error) {
+		object, conditions, err := get()
+		if err != nil {
+			return false, err
+		}
+		if first {
+			uid, first = string(object.GetUID()), false
+		} else if string(object.GetUID()) != uid {
+			return false, fmt.Errorf("%q was deleted and created again while waiting for it", object.GetName())
+		}
+		return done(conditions)
+	})
+}
+
+// pollUntilDone runs condition right away, then at the given interval, until it holds, fails, or ctx is done, in which
+// case ctx.Err() is returned. Unlike wait.PollImmediateUntil on its own, condition is not run again once ctx is done.
+func pollUntilDone(ctx context.Context, interval time.Duration, condition wait.ConditionFunc) error {
+	first := true
+	err := wait.PollImmediateUntil(interval, func() (bool, error) {
+		if !first && ctx.Err() != nil {
+			return false, ctx.Err()
+		}
+		first = false
+		return condition()
+	}, ctx.Done())
+	if err == wait.ErrWaitTimeout {
+		return ctx.Err()
+	}
+	return err
+}
+
+// ConditionIsTrue returns a predicate holding once the condition of the given type is True. The condition becoming
+// False fails the wait, with the reason and message of the condition as error.
+func ConditionIsTrue(conditionType string) ConditionsPredicate {
+	return func(conditions []ConditionDescription) (bool, error) {
+		for _, cond := range conditions {
+			if cond.Type != conditionType {
+				continue
+			}
+			switch core_v1.ConditionStatus(cond.Status) {
+			case core_v1.ConditionTrue:
+				return true, nil
+			case core_v1.ConditionFalse:
+				return false, fmt.Errorf("%s: %s", cond.Reason, cond.Message)
+			}
+		}
+		return false, nil
+	}
+}
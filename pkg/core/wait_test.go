@@ -0,0 +1,110 @@
+/*
+ * Copyright 2018 The original author or authors
+ *
+ * Licensed under the Apache License, Version 2.0 (the "License");
+ * you may not use this file except in compliance with the License.
+ * You may obtain a copy of the License at
+ *
+ *     http://www.apache.org/licenses/LICENSE-2.0
+ *
+ * Unless required by applicable law or agreed to in writing, software
+ * distributed under the License is distributed on an "AS IS" BASIS,
+ * WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+ * See the License for the specific language governing permissions and
+ * limitations under the License.
+ */
+
+package core_test
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	. "github.com/onsi/ginkgo"
+	. "github.com/onsi/gomega"
+	"github.com/projectriff/riff/pkg/core"
+	meta_v1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+var _ = Describe("Waiting for conditions", func() {
+
+	var (
+		lookups int
+		// statuses are the successive statuses of the Ready condition, the last one being repeated
+		statuses []string
+		uids     []types.UID
+		get      core.ConditionsGetter
+	)
+
+	BeforeEach(func() {
+		lookups = 0
+		statuses = []string{"Unknown", "Unknown", "True"}
+		uids = []types.UID{"first"}
+		get = func() (meta_v1.Object, []core.ConditionDescription, error) {
+			status, uid := statuses[len(statuses)-1], uids[len(uids)-1]
+			if lookups < len(statuses) {
+				status = statuses[lookups]
+			}
+			if lookups < len(uids) {
+				uid = uids[lookups]
+			}
+			object := &meta_v1.ObjectMeta{Name: "square", UID: uid}
+			lookups++
+			return object, []core.ConditionDescription{
+				{Type: "Scheduled", Status: "True"},
+				{Type: "Ready", Status: status, Reason: "RevisionFailed", Message: "image not found"},
+			}, nil
+		}
+	})
+
+	It("should look up the resource until the predicate holds", func() {
+		err := core.WaitForCondition(context.Background(), time.Millisecond, get, core.ConditionIsTrue("Ready"))
+		Expect(err).NotTo(HaveOccurred())
+		Expect(lookups).To(Equal(3))
+	})
+
+	It("should fail when the condition becomes False", func() {
+		statuses = []string{"Unknown", "False"}
+
+		err := core.WaitForCondition(context.Background(), time.Millisecond, get, core.ConditionIsTrue("Ready"))
+		Expect(err).To(MatchError("RevisionFailed: image not found"))
+		Expect(lookups).To(Equal(2))
+	})
+
+	It("should not wait for a condition the resource doesn't report", func() {
+		statuses = []string{"Unknown"}
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := core.WaitForCondition(ctx, time.Millisecond, get, core.ConditionIsTrue("Dispatching"))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+	})
+
+	It("should give up once the context is done", func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 20*time.Millisecond)
+		defer cancel()
+
+		err := core.WaitForCondition(ctx, time.Hour, get, core.ConditionIsTrue("Ready"))
+		Expect(err).To(Equal(context.DeadlineExceeded))
+		Expect(lookups).To(Equal(1))
+	})
+
+	It("should stop on errors of the getter", func() {
+		e := fmt.Errorf("some error")
+		get = func() (meta_v1.Object, []core.ConditionDescription, error) {
+			return nil, nil, e
+		}
+
+		err := core.WaitForCondition(context.Background(), time.Millisecond, get, core.ConditionIsTrue("Ready"))
+		Expect(err).To(MatchError(e))
+	})
+
+	It("should fail when the resource is created again", func() {
+		uids = []types.UID{"first", "second"}
+
+		err := core.WaitForCondition(context.Background(), time.Millisecond, get, core.ConditionIsTrue("Ready"))
+		Expect(err).To(MatchError(`"square" was deleted and created again while waiting for it`))
+	})
+})
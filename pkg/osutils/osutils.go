@@ -19,6 +19,7 @@ package osutils
 import (
 	"bytes"
 	"context"
+	"io"
 	"os/exec"
 	"time"
 )
@@ -54,3 +55,11 @@ func ExecStdin(cmdName string, cmdArgs []string, stdin *[]byte, timeout time.Dur
 
 	return out, err
 }
+
+// ExecStream runs a command until it exits, copying both its standard and error output to out as it runs.
+func ExecStream(cmdName string, cmdArgs []string, out io.Writer) error {
+	cmd := exec.Command(cmdName, cmdArgs...)
+	cmd.Stdout = out
+	cmd.Stderr = out
+	return cmd.Run()
+}